@@ -0,0 +1,75 @@
+package bind
+
+import (
+	"mime/multipart"
+	"reflect"
+)
+
+const (
+	formTagKey = "form"
+)
+
+var (
+	fileHeaderType      = reflect.TypeOf((*multipart.FileHeader)(nil))
+	fileHeaderSliceType = reflect.TypeOf([]*multipart.FileHeader(nil))
+)
+
+// Files injects uploaded files into the *multipart.FileHeader and []*multipart.FileHeader
+// fields of v. The form tag is used as the name of the file part and defaults to the
+// name of the field. All other fields are ignored
+func Files(v interface{}, files map[string][]*multipart.FileHeader) error {
+	return FilesValue(reflect.ValueOf(v).Elem(), files)
+}
+
+// FilesValue injects uploaded files into the *multipart.FileHeader and
+// []*multipart.FileHeader fields of obj
+func FilesValue(obj reflect.Value, files map[string][]*multipart.FileHeader) error {
+	kind := obj.Type()
+
+	for i := 0; i < obj.NumField(); i++ {
+		field := obj.Field(i)
+		if !field.CanSet() {
+			continue
+		}
+
+		tField := kind.Field(i)
+		if tField.Type != fileHeaderType && tField.Type != fileHeaderSliceType {
+			continue
+		}
+
+		formKey := tField.Name
+		if tag, ok := tField.Tag.Lookup(formTagKey); ok {
+			formKey = tag
+		}
+
+		if formKey == "-" {
+			continue
+		}
+
+		fhs := files[formKey]
+		if len(fhs) == 0 {
+			continue
+		}
+
+		if tField.Type == fileHeaderSliceType {
+			field.Set(reflect.ValueOf(fhs))
+			continue
+		}
+
+		// simple fields cannot have multiple values
+		if len(fhs) > 1 {
+			names := make([]string, len(fhs))
+			for i, fh := range fhs {
+				names[i] = fh.Filename
+			}
+			return &TypeMismatchError{
+				Cause:     errMultiValueSimpleField,
+				FieldName: tField.Name,
+				Kind:      reflect.Ptr,
+				Val:       names,
+			}
+		}
+		field.Set(reflect.ValueOf(fhs[0]))
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package bind
+
+import (
+	"mime/multipart"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilesSetsFileHeaderByTag(t *testing.T) {
+	var item struct {
+		Avatar *multipart.FileHeader `form:"avatar"`
+	}
+
+	fh := &multipart.FileHeader{Filename: "me.png"}
+	err := Files(&item, map[string][]*multipart.FileHeader{
+		"avatar": {fh},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, fh, item.Avatar)
+}
+
+func TestFilesSetsFileHeaderSliceByFieldName(t *testing.T) {
+	var item struct {
+		Photos []*multipart.FileHeader
+	}
+
+	fhs := []*multipart.FileHeader{{Filename: "1.png"}, {Filename: "2.png"}}
+	err := Files(&item, map[string][]*multipart.FileHeader{
+		"Photos": fhs,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, fhs, item.Photos)
+}
+
+func TestFilesErrorsWhenMultipleFilesForSingleField(t *testing.T) {
+	var item struct {
+		Avatar *multipart.FileHeader `form:"avatar"`
+	}
+
+	err := Files(&item, map[string][]*multipart.FileHeader{
+		"avatar": {{Filename: "1.png"}, {Filename: "2.png"}},
+	})
+	assert.IsType(t, &TypeMismatchError{}, err)
+}
+
+func TestFilesSkipsOtherFields(t *testing.T) {
+	var item struct {
+		Name   string                `form:"avatar"`
+		Avatar *multipart.FileHeader `form:"-"`
+	}
+
+	err := Files(&item, map[string][]*multipart.FileHeader{
+		"avatar": {{Filename: "1.png"}},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, item.Name)
+	assert.Nil(t, item.Avatar)
+}
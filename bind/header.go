@@ -0,0 +1,24 @@
+package bind
+
+import (
+	"net/http"
+	"reflect"
+)
+
+const (
+	headerTagKey = "header"
+)
+
+// Header parses request headers and injects them into v. The header tag is used as the
+// name of the header and defaults to the name of the field. Header names are not case
+// sensitive
+func Header(v interface{}, h http.Header) error {
+	return HeaderValue(reflect.ValueOf(v).Elem(), h)
+}
+
+// HeaderValue parses request headers and injects them into obj
+func HeaderValue(obj reflect.Value, h http.Header) error {
+	return valuesValue(obj, headerTagKey, func(key string) []string {
+		return h.Values(key)
+	})
+}
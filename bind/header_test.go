@@ -0,0 +1,53 @@
+package bind
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHeaderParsesString(t *testing.T) {
+	var item struct {
+		Token string `header:"x-auth-token"`
+	}
+
+	h := http.Header{}
+	h.Set("X-Auth-Token", "secret")
+	require.NoError(t, Header(&item, h))
+	assert.Equal(t, "secret", item.Token)
+}
+
+func TestHeaderDefaultsToFieldName(t *testing.T) {
+	var item struct {
+		Origin string
+	}
+
+	h := http.Header{}
+	h.Set("Origin", "http://example.com")
+	require.NoError(t, Header(&item, h))
+	assert.Equal(t, "http://example.com", item.Origin)
+}
+
+func TestHeaderParsesSlice(t *testing.T) {
+	var item struct {
+		Protocols []string `header:"sec-websocket-protocol"`
+	}
+
+	h := http.Header{}
+	h.Add("Sec-WebSocket-Protocol", "chat")
+	h.Add("Sec-WebSocket-Protocol", "superchat")
+	require.NoError(t, Header(&item, h))
+	assert.Equal(t, []string{"chat", "superchat"}, item.Protocols)
+}
+
+func TestHeaderErrorsForInvalidType(t *testing.T) {
+	var item struct {
+		Count int `header:"x-count"`
+	}
+
+	h := http.Header{}
+	h.Set("X-Count", "abc")
+	assert.Error(t, Header(&item, h))
+}
@@ -0,0 +1,99 @@
+package bind
+
+import (
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// The functions in this file convert strings into the values of fields. They are shared by the
+// reflective binders and the binders generated by cmd/boargen so that both return the same
+// values and errors
+
+// SingleValue returns the trimmed value of a field which is not a slice from vals. An empty
+// value means the field should be left unset. A TypeMismatchError is returned when there is
+// more than one value
+func SingleValue(vals []string, kind reflect.Kind, fieldName string) (string, error) {
+	if len(vals) == 0 {
+		return "", nil
+	}
+	if len(vals) > 1 {
+		return "", &TypeMismatchError{
+			Cause:     errMultiValueSimpleField,
+			FieldName: fieldName,
+			Kind:      kind,
+			Val:       vals,
+		}
+	}
+	return strings.TrimSpace(vals[0]), nil
+}
+
+// ParseBool parses val as a bool
+func ParseBool(val string, fieldName string) (bool, error) {
+	v, err := strconv.ParseBool(val)
+	if err != nil {
+		return false, mismatch(reflect.Bool, val, err, fieldName)
+	}
+	return v, nil
+}
+
+// ParseInt parses val as an integer which must fit in the signed integer kind
+func ParseInt(val string, kind reflect.Kind, fieldName string) (int64, error) {
+	v, err := strconv.ParseInt(val, 10, 64)
+	if err != nil {
+		return 0, mismatch(kind, val, err, fieldName)
+	}
+	if bits := bitSize(kind); bits < 64 && (v < -1<<(bits-1) || v > 1<<(bits-1)-1) {
+		return 0, mismatch(kind, val, nil, fieldName)
+	}
+	return v, nil
+}
+
+// ParseUint parses val as an integer which must fit in the unsigned integer kind
+func ParseUint(val string, kind reflect.Kind, fieldName string) (uint64, error) {
+	v, err := strconv.ParseUint(val, 10, 64)
+	if err != nil {
+		return 0, mismatch(kind, val, err, fieldName)
+	}
+	if bits := bitSize(kind); bits < 64 && v > 1<<bits-1 {
+		return 0, mismatch(kind, val, nil, fieldName)
+	}
+	return v, nil
+}
+
+// ParseFloat parses val as a number which must fit in the float kind
+func ParseFloat(val string, kind reflect.Kind, fieldName string) (float64, error) {
+	v, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		return 0, mismatch(kind, val, err, fieldName)
+	}
+	// infinities do not overflow, as with reflect.Value.OverflowFloat
+	if abs := math.Abs(v); kind == reflect.Float32 && abs > math.MaxFloat32 && abs <= math.MaxFloat64 {
+		return 0, mismatch(kind, val, nil, fieldName)
+	}
+	return v, nil
+}
+
+func mismatch(kind reflect.Kind, val string, cause error, fieldName string) error {
+	return &TypeMismatchError{
+		Kind:      kind,
+		Val:       val,
+		Cause:     cause,
+		FieldName: fieldName,
+	}
+}
+
+func bitSize(kind reflect.Kind) int {
+	switch kind {
+	case reflect.Int8, reflect.Uint8:
+		return 8
+	case reflect.Int16, reflect.Uint16:
+		return 16
+	case reflect.Int32, reflect.Uint32, reflect.Float32:
+		return 32
+	case reflect.Int, reflect.Uint:
+		return strconv.IntSize
+	}
+	return 64
+}
@@ -0,0 +1,91 @@
+package bind
+
+import (
+	"math"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseIntRejectsOverflowLikeReflect(t *testing.T) {
+	types := []reflect.Type{
+		reflect.TypeOf(int(0)),
+		reflect.TypeOf(int8(0)),
+		reflect.TypeOf(int16(0)),
+		reflect.TypeOf(int32(0)),
+		reflect.TypeOf(int64(0)),
+	}
+	values := []int64{0, math.MinInt8, math.MaxInt8, math.MaxInt8 + 1, math.MinInt16 - 1, math.MaxInt32, math.MinInt32 - 1, math.MaxInt64}
+
+	for _, typ := range types {
+		for _, v := range values {
+			_, err := ParseInt(strconv.FormatInt(v, 10), typ.Kind(), "f")
+			overflow := reflect.New(typ).Elem().OverflowInt(v)
+			assert.Equal(t, overflow, err != nil, "%d as %s", v, typ)
+		}
+	}
+}
+
+func TestParseUintRejectsOverflowLikeReflect(t *testing.T) {
+	types := []reflect.Type{
+		reflect.TypeOf(uint(0)),
+		reflect.TypeOf(uint8(0)),
+		reflect.TypeOf(uint16(0)),
+		reflect.TypeOf(uint32(0)),
+		reflect.TypeOf(uint64(0)),
+	}
+	values := []uint64{0, math.MaxUint8, math.MaxUint8 + 1, math.MaxUint16 + 1, math.MaxUint32, math.MaxUint32 + 1, math.MaxUint64}
+
+	for _, typ := range types {
+		for _, v := range values {
+			_, err := ParseUint(strconv.FormatUint(v, 10), typ.Kind(), "f")
+			overflow := reflect.New(typ).Elem().OverflowUint(v)
+			assert.Equal(t, overflow, err != nil, "%d as %s", v, typ)
+		}
+	}
+}
+
+func TestParseFloatRejectsOverflowLikeReflect(t *testing.T) {
+	values := []string{"0", "3.4e38", "3.5e38", "-3.5e38", "1e300", "Inf", "-Inf", "NaN"}
+
+	for _, typ := range []reflect.Type{reflect.TypeOf(float32(0)), reflect.TypeOf(float64(0))} {
+		for _, s := range values {
+			v, err := strconv.ParseFloat(s, 64)
+			require.NoError(t, err)
+			_, err = ParseFloat(s, typ.Kind(), "f")
+			overflow := reflect.New(typ).Elem().OverflowFloat(v)
+			assert.Equal(t, overflow, err != nil, "%s as %s", s, typ)
+		}
+	}
+}
+
+func TestParseErrorsAreTypeMismatches(t *testing.T) {
+	_, err := ParseInt("300", reflect.Int8, "Age")
+	assert.Equal(t, &TypeMismatchError{Kind: reflect.Int8, Val: "300", FieldName: "Age"}, err)
+
+	_, err = ParseBool("yes", "Active")
+	require.IsType(t, &TypeMismatchError{}, err)
+	assert.Equal(t, reflect.Bool, err.(*TypeMismatchError).Kind)
+	assert.Error(t, err.(*TypeMismatchError).Cause)
+}
+
+func TestSingleValue(t *testing.T) {
+	val, err := SingleValue([]string{" a "}, reflect.String, "Name")
+	require.NoError(t, err)
+	assert.Equal(t, "a", val)
+
+	val, err = SingleValue(nil, reflect.String, "Name")
+	require.NoError(t, err)
+	assert.Empty(t, val)
+
+	_, err = SingleValue([]string{"a", "b"}, reflect.String, "Name")
+	assert.Equal(t, &TypeMismatchError{
+		Cause:     errMultiValueSimpleField,
+		FieldName: "Name",
+		Kind:      reflect.String,
+		Val:       []string{"a", "b"},
+	}, err)
+}
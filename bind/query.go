@@ -4,7 +4,6 @@ import (
 	"errors"
 	"net/url"
 	"reflect"
-	"strings"
 )
 
 var (
@@ -23,6 +22,14 @@ func Query(v interface{}, q url.Values) error {
 
 // QueryValue parses query parameters from the http.Request and injects them into v
 func QueryValue(obj reflect.Value, q url.Values) error {
+	return valuesValue(obj, queryTagKey, func(key string) []string {
+		return q[key]
+	})
+}
+
+// valuesValue injects the values returned by lookup into the fields of obj. The key
+// of each field is the value of the tagKey tag or the name of the field
+func valuesValue(obj reflect.Value, tagKey string, lookup func(key string) []string) error {
 	kind := obj.Type()
 
 	for i := 0; i < obj.NumField(); i++ {
@@ -39,7 +46,7 @@ func QueryValue(obj reflect.Value, q url.Values) error {
 		}
 
 		queryKey := tField.Name
-		if tag, ok := tField.Tag.Lookup(queryTagKey); ok {
+		if tag, ok := tField.Tag.Lookup(tagKey); ok {
 			queryKey = tag
 		}
 
@@ -47,35 +54,29 @@ func QueryValue(obj reflect.Value, q url.Values) error {
 			continue
 		}
 
-		vals := q[queryKey]
+		vals := lookup(queryKey)
 
 		if len(vals) == 0 {
 			continue
 		}
 
+		// errors name the field by its key, as the client knows it
 		if kind == reflect.Slice {
-			if err := setFieldSlice(field, tField.Name, vals); err != nil {
+			if err := setFieldSlice(field, queryKey, vals); err != nil {
 				return err
 			}
 			continue
 		}
 
 		// simple fields cannot have multiple values
-		if len(vals) > 1 {
-			return &TypeMismatchError{
-				Cause:     errMultiValueSimpleField,
-				FieldName: tField.Name,
-				Kind:      kind,
-				Val:       vals,
-			}
+		val, err := SingleValue(vals, kind, queryKey)
+		if err != nil {
+			return err
 		}
-
-		val := strings.TrimSpace(vals[0])
 		if val == "" {
 			continue
 		}
-		err := setSimpleField(field, queryKey, kind, val)
-		if err != nil {
+		if err := setSimpleField(field, queryKey, kind, val); err != nil {
 			return err
 		}
 
@@ -5,7 +5,6 @@ package bind
 import (
 	"fmt"
 	"reflect"
-	"strconv"
 	"strings"
 )
 
@@ -31,59 +30,33 @@ func setSimpleField(f reflect.Value, fieldName string, kind reflect.Kind, val st
 	switch kind {
 	case reflect.String:
 		f.SetString(val)
-		return nil
 	case reflect.Bool:
-		v, err := strconv.ParseBool(val)
+		v, err := ParseBool(val, fieldName)
 		if err != nil {
-			return &TypeMismatchError{
-				Kind:      kind,
-				Val:       val,
-				Cause:     err,
-				FieldName: fieldName,
-			}
+			return err
 		}
 		f.SetBool(v)
-		break
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		v, err := strconv.ParseInt(val, 10, 64)
-		if err != nil || f.OverflowInt(v) {
-			return &TypeMismatchError{
-				Kind:      kind,
-				Val:       val,
-				Cause:     err,
-				FieldName: fieldName,
-			}
+		v, err := ParseInt(val, kind, fieldName)
+		if err != nil {
+			return err
 		}
 		f.SetInt(v)
-		break
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		v, err := strconv.ParseUint(val, 10, 64)
-		if err != nil || f.OverflowUint(v) {
-			return &TypeMismatchError{
-				Kind:      kind,
-				Val:       val,
-				Cause:     err,
-				FieldName: fieldName,
-			}
+		v, err := ParseUint(val, kind, fieldName)
+		if err != nil {
+			return err
 		}
 		f.SetUint(v)
-		break
 	case reflect.Float32, reflect.Float64:
-		v, err := strconv.ParseFloat(val, 64)
-		if err != nil || f.OverflowFloat(v) {
-			return &TypeMismatchError{
-				Kind:      kind,
-				Val:       val,
-				Cause:     err,
-				FieldName: fieldName,
-			}
+		v, err := ParseFloat(val, kind, fieldName)
+		if err != nil {
+			return err
 		}
 		f.SetFloat(v)
-		return nil
 	default:
 		return fmt.Errorf("%s is not a supported query parameter type", kind)
 	}
-
 	return nil
 }
 
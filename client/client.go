@@ -0,0 +1,234 @@
+// Package client is the runtime of the Go clients generated by the clientgen package. It
+// builds requests from the Query, URLParams, Headers and Body types of boar handlers and
+// decodes error responses into HTTPError and ValidationError
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/blockloop/boar/internal/pattern"
+)
+
+// Client sends requests to a boar service
+type Client struct {
+	// BaseURL is the URL that request paths are appended to, such as https://api.example.com/v1
+	BaseURL string
+
+	// HTTPClient sends requests. http.DefaultClient is used when nil
+	HTTPClient *http.Client
+
+	// Header is added to every request, such as an Authorization header
+	Header http.Header
+}
+
+// New creates a Client for the service at baseURL
+func New(baseURL string) *Client {
+	return &Client{
+		BaseURL: strings.TrimSuffix(baseURL, "/"),
+		Header:  make(http.Header),
+	}
+}
+
+// Do sends a request for path with the given query and header. body is encoded as JSON
+// when it is not nil, and the JSON of a successful response is decoded into out when it is
+// not nil. Responses with a status of 400 or above are returned as a *ValidationError when
+// they describe validation errors, and as an *HTTPError otherwise
+func (c *Client) Do(ctx context.Context, method, path string, query url.Values, header http.Header, body, out interface{}) error {
+	u := c.BaseURL + path
+	if len(query) > 0 {
+		// path may already have a query string from the params of Path
+		sep := "?"
+		if strings.Contains(path, "?") {
+			sep = "&"
+		}
+		u += sep + query.Encode()
+	}
+
+	var r io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("could not encode request body: %v", err)
+		}
+		r = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequest(method, u, r)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	for k, v := range c.Header {
+		req.Header[k] = v
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+
+	hc := c.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return decodeError(resp)
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		io.Copy(ioutil.Discard, resp.Body)
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil && err != io.EOF {
+		return fmt.Errorf("could not decode response body: %v", err)
+	}
+	return nil
+}
+
+// Path fills the :param and *catchall segments of a boar route pattern with params, which are
+// key/value pairs. Values are formatted with fmt.Sprint and escaped as they are by
+// boar.Router.URL, and pairs with keys that are not parameters of the pattern are added to
+// the query string. An error is returned when a parameter is missing or a :param value
+// contains a slash
+func Path(pat string, params ...interface{}) (string, error) {
+	values := make([]string, len(params))
+	for i, p := range params {
+		values[i] = fmt.Sprint(p)
+	}
+	return pattern.Build(pat, strconv.Quote(pat), values)
+}
+
+// Query encodes the fields of the struct v as query values named by their query tags, in
+// the same way that boar binds them. Fields with zero values are omitted
+func Query(v interface{}) url.Values {
+	q := url.Values{}
+	encodeValues(v, "query", q.Add)
+	return q
+}
+
+// Header encodes the fields of the struct v as headers named by their header tags, in the
+// same way that boar binds them. Fields with zero values are omitted
+func Header(v interface{}) http.Header {
+	h := http.Header{}
+	encodeValues(v, "header", h.Add)
+	return h
+}
+
+func encodeValues(v interface{}, tagKey string, add func(key, value string)) {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return
+	}
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		f := rt.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		key := f.Name
+		if tag, ok := f.Tag.Lookup(tagKey); ok {
+			key = tag
+		}
+		if key == "-" {
+			continue
+		}
+
+		field := rv.Field(i)
+		if isZero(field) {
+			continue
+		}
+		if field.Kind() == reflect.Slice {
+			for j := 0; j < field.Len(); j++ {
+				add(key, fmt.Sprint(field.Index(j).Interface()))
+			}
+			continue
+		}
+		add(key, fmt.Sprint(field.Interface()))
+	}
+}
+
+func isZero(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return reflect.DeepEqual(v.Interface(), reflect.Zero(v.Type()).Interface())
+}
+
+// HTTPError is returned for responses with an error status. Message is the error
+// that the service responded with
+type HTTPError struct {
+	Status  int
+	Message string
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("HTTPError: (status: %d, error: %s)", e.Status, e.Message)
+}
+
+// ValidationError is returned when the request failed validation. Errors are keyed by the
+// part of the request that was invalid, such as body, query or urlparams
+type ValidationError struct {
+	Status int
+	Errors map[string][]string
+}
+
+func (e *ValidationError) Error() string {
+	keys := make([]string, 0, len(e.Errors))
+	for k := range e.Errors {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, k := range keys {
+		parts = append(parts, e.Errors[k]...)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// decodeError reads the error of a response. Bodies which are not the JSON of a boar error
+// are used as the message of an HTTPError
+func decodeError(resp *http.Response) error {
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return &HTTPError{Status: resp.StatusCode, Message: err.Error()}
+	}
+
+	var body struct {
+		Error  *string             `json:"error"`
+		Errors map[string][]string `json:"errors"`
+	}
+	if json.Unmarshal(b, &body) == nil {
+		if body.Errors != nil {
+			return &ValidationError{Status: resp.StatusCode, Errors: body.Errors}
+		}
+		if body.Error != nil {
+			return &HTTPError{Status: resp.StatusCode, Message: *body.Error}
+		}
+	}
+
+	msg := strings.TrimSpace(string(b))
+	if msg == "" {
+		msg = http.StatusText(resp.StatusCode)
+	}
+	return &HTTPError{Status: resp.StatusCode, Message: msg}
+}
@@ -0,0 +1,154 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/blockloop/boar"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type orderHandler struct {
+	Query struct {
+		Expand []string `query:"expand"`
+		Page   int      `query:"page"`
+	}
+	URLParams struct {
+		ID int `url:"id"`
+	}
+	Headers struct {
+		RequestID string `header:"X-Request-ID"`
+	}
+	Body struct {
+		Item string `json:"item" validate:"required"`
+	}
+}
+
+func (h *orderHandler) Handle(c boar.Context) error {
+	if h.URLParams.ID == 404 {
+		return boar.ErrEntityNotFound
+	}
+	return c.WriteJSON(http.StatusOK, boar.JSON{
+		"id":        h.URLParams.ID,
+		"item":      h.Body.Item,
+		"expand":    h.Query.Expand,
+		"page":      h.Query.Page,
+		"requestID": h.Headers.RequestID,
+	})
+}
+
+func newServer(t *testing.T) *Client {
+	r := boar.NewRouter()
+	r.Put("/orders/:id", func(boar.Context) (boar.Handler, error) {
+		return &orderHandler{}, nil
+	})
+	r.MethodFunc(http.MethodGet, "/text", func(c boar.Context) error {
+		c.Response().Header().Set("content-type", "text/plain")
+		c.WriteStatus(http.StatusBadGateway)
+		_, err := c.Response().Write([]byte("upstream failed\n"))
+		return err
+	})
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+	return New(srv.URL + "/")
+}
+
+func TestDo(t *testing.T) {
+	c := newServer(t)
+	c.Header.Set("X-Request-ID", "overridden")
+
+	var req orderHandler
+	req.Query.Expand = []string{"items", "customer"}
+	req.Headers.RequestID = "abc"
+	req.Body.Item = "book"
+
+	path, err := Path("/orders/:id", "id", 42)
+	require.NoError(t, err)
+
+	var out struct {
+		ID        int      `json:"id"`
+		Item      string   `json:"item"`
+		Expand    []string `json:"expand"`
+		Page      int      `json:"page"`
+		RequestID string   `json:"requestID"`
+	}
+	err = c.Do(context.Background(), http.MethodPut, path, Query(req.Query), Header(req.Headers), req.Body, &out)
+	require.NoError(t, err)
+	assert.Equal(t, 42, out.ID)
+	assert.Equal(t, "book", out.Item)
+	assert.Equal(t, []string{"items", "customer"}, out.Expand)
+	assert.Equal(t, 0, out.Page)
+	assert.Equal(t, "abc", out.RequestID)
+}
+
+func TestDoValidationError(t *testing.T) {
+	c := newServer(t)
+
+	err := c.Do(context.Background(), http.MethodPut, "/orders/1", nil, nil, struct{}{}, nil)
+	var verr *ValidationError
+	require.True(t, errors.As(err, &verr), "%T", err)
+	assert.Equal(t, http.StatusBadRequest, verr.Status)
+	assert.Contains(t, verr.Errors, "body")
+}
+
+func TestDoHTTPError(t *testing.T) {
+	c := newServer(t)
+
+	err := c.Do(context.Background(), http.MethodPut, "/orders/404", nil, nil, boar.JSON{"item": "book"}, nil)
+	var herr *HTTPError
+	require.True(t, errors.As(err, &herr), "%T", err)
+	assert.Equal(t, http.StatusNotFound, herr.Status)
+	assert.Equal(t, "entity not found", herr.Message)
+
+	err = c.Do(context.Background(), http.MethodGet, "/text", nil, nil, nil, nil)
+	require.True(t, errors.As(err, &herr), "%T", err)
+	assert.Equal(t, http.StatusBadGateway, herr.Status)
+	assert.Equal(t, "upstream failed", herr.Message)
+}
+
+func TestPath(t *testing.T) {
+	p, err := Path("/users/:id/files/*path", "id", 7, "path", "/a b/c.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "/users/7/files/a%20b/c.txt", p)
+
+	_, err = Path("/users/:id", "id", "a/b")
+	assert.EqualError(t, err, `param "id" for "/users/:id" cannot contain a slash`)
+
+	_, err = Path("/users/:id")
+	assert.EqualError(t, err, `missing param "id" for "/users/:id"`)
+
+	p, err = Path("/users/:id", "id", 7, "page", 2)
+	require.NoError(t, err)
+	assert.Equal(t, "/users/7?page=2", p)
+}
+
+func TestQueryOmitsZeroValues(t *testing.T) {
+	var q struct {
+		Page    int      `query:"page"`
+		Search  string   `query:"q"`
+		Tags    []string `query:"tag"`
+		Active  bool
+		Skipped string `query:"-"`
+		private string
+	}
+	q.Search = "boar"
+	q.Tags = []string{"a", "b"}
+	q.Active = true
+	q.Skipped = "x"
+	q.private = "x"
+
+	assert.Equal(t, "Active=true&q=boar&tag=a&tag=b", Query(q).Encode())
+	assert.Empty(t, Query(nil))
+}
+
+func TestValidationErrorMessage(t *testing.T) {
+	err := &ValidationError{Errors: map[string][]string{
+		"query": {"page must be a number"},
+		"body":  {"item is required"},
+	}}
+	assert.Equal(t, "item is required; page must be a number", err.Error())
+}
@@ -0,0 +1,340 @@
+// Package clientgen generates typed Go clients for boar services from the route table of a
+// Router. The generator is run by a program that builds the Router of the service, usually
+// with go generate:
+//
+//	//go:generate go run ./cmd/genclient
+//
+//	func main() {
+//		f, err := os.Create("client/client_gen.go")
+//		if err != nil {
+//			log.Fatal(err)
+//		}
+//		defer f.Close()
+//		if err := clientgen.Generate(f, api.NewRouter().Routes(), clientgen.Options{Package: "client"}); err != nil {
+//			log.Fatal(err)
+//		}
+//	}
+//
+// A method is generated for each named route. The request of each method holds the Query,
+// URLParams, Headers and Body of the handler given by boar.Handles, reusing their types when
+// they are exported and declaring equivalent types when they are not. Methods of routes
+// which document their response with boar.Returns return it as a pointer to that type, and
+// the others decode the response into the value they are given. Error responses are
+// returned as a *client.HTTPError or a *client.ValidationError
+package clientgen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"path"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/blockloop/boar"
+	"github.com/blockloop/boar/internal/naming"
+	"github.com/blockloop/boar/schema"
+)
+
+const clientImport = "github.com/blockloop/boar/client"
+
+// Options configure the generated code
+type Options struct {
+	// Package is the name of the generated package. Defaults to client
+	Package string
+}
+
+// Generate writes the source of a Go client for the named routes to w. Routes without a name
+// and handlers served with Mount are skipped
+func Generate(w io.Writer, routes []boar.RouteInfo, opts Options) error {
+	if opts.Package == "" {
+		opts.Package = "client"
+	}
+	g := &generator{
+		imports: map[string]string{clientImport: "client"},
+		aliases: map[string]bool{"client": true, "context": true, opts.Package: true},
+	}
+
+	methods, err := naming.Methods(routes, func(method string) string { return method })
+	if err != nil {
+		return err
+	}
+	body := &bytes.Buffer{}
+	for i, ri := range routes {
+		if methods[i] != "" {
+			g.route(body, methods[i], ri)
+		}
+	}
+
+	src := &bytes.Buffer{}
+	fmt.Fprintf(src, "// Code generated by github.com/blockloop/boar/clientgen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(src, "package %s\n\n", opts.Package)
+	g.writeImports(src, body.Len() > 0)
+	fmt.Fprint(src, `
+// Client sends requests to the service
+type Client struct {
+	*client.Client
+}
+
+// NewClient creates a Client for the service at baseURL
+func NewClient(baseURL string) *Client {
+	return &Client{Client: client.New(baseURL)}
+}
+`)
+	src.Write(body.Bytes())
+
+	formatted, err := format.Source(src.Bytes())
+	if err != nil {
+		return fmt.Errorf("could not format generated client: %v", err)
+	}
+	_, err = w.Write(formatted)
+	return err
+}
+
+type generator struct {
+	imports map[string]string // package path to name
+	aliases map[string]bool
+	// visiting holds the unexported types being declared to break cycles
+	visiting map[reflect.Type]bool
+}
+
+// route writes the request type and the method of a route
+func (g *generator) route(w io.Writer, method string, ri boar.RouteInfo) {
+	request := method + "Request"
+	fields := []struct {
+		name string
+		typ  reflect.Type
+	}{
+		{"Query", ri.Query},
+		{"URLParams", ri.URLParams},
+		{"Headers", ri.Headers},
+		{"Body", ri.Body},
+	}
+
+	// path params which are not bound to a field of URLParams are given their own fields
+	var args []string
+	taken := map[string]bool{"Query": true, "URLParams": true, "Headers": true, "Body": true}
+	var extra []string
+	for _, p := range ri.Params() {
+		if field, ok := urlParamField(ri.URLParams, p.Name); ok && !p.CatchAll {
+			args = append(args, strconv.Quote(p.Name), "req.URLParams."+field)
+			continue
+		}
+		name := naming.Exported(p.Name)
+		for name == "" || taken[name] {
+			name += "Param"
+		}
+		taken[name] = true
+		extra = append(extra, name)
+		args = append(args, strconv.Quote(p.Name), "req."+name)
+	}
+
+	fmt.Fprintf(w, "\n// %s is the request of the %s route\n", request, ri.Name)
+	fmt.Fprintf(w, "type %s struct {\n", request)
+	for _, f := range fields {
+		if f.typ != nil {
+			fmt.Fprintf(w, "%s %s\n", f.name, g.typeExpr(f.typ))
+		}
+	}
+	for _, name := range extra {
+		fmt.Fprintf(w, "%s string\n", name)
+	}
+	fmt.Fprintf(w, "}\n")
+
+	pathArgs := ""
+	if len(args) > 0 {
+		pathArgs = ", " + strings.Join(args, ", ")
+	}
+	query, header, body := "nil", "nil", "nil"
+	if ri.Query != nil {
+		query = "client.Query(req.Query)"
+	}
+	if ri.Headers != nil {
+		header = "client.Header(req.Headers)"
+	}
+	if ri.Body != nil {
+		body = "req.Body"
+	}
+	do := fmt.Sprintf("c.Do(ctx, %s, path, %s, %s, %s, out)", strconv.Quote(ri.Method), query, header, body)
+
+	if ri.Response == nil {
+		fmt.Fprintf(w, "\n// %s sends %s %s. The JSON of a successful response is decoded into out\n", method, ri.Method, ri.Path)
+		fmt.Fprintf(w, "// when it is not nil\n")
+		fmt.Fprintf(w, "func (c *Client) %s(ctx context.Context, req %s, out interface{}) error {\n", method, request)
+		fmt.Fprintf(w, "path, err := client.Path(%s%s)\n", strconv.Quote(ri.Path), pathArgs)
+		fmt.Fprintf(w, "if err != nil {\nreturn err\n}\n")
+		fmt.Fprintf(w, "return %s\n}\n", do)
+		return
+	}
+
+	// the response is returned by pointer, so a pointer given to boar.Returns is not
+	// pointed to again
+	response := ri.Response
+	for response.Kind() == reflect.Ptr {
+		response = response.Elem()
+	}
+	typ := g.typeExpr(response)
+	fmt.Fprintf(w, "\n// %s sends %s %s and returns the JSON of a successful response\n", method, ri.Method, ri.Path)
+	fmt.Fprintf(w, "func (c *Client) %s(ctx context.Context, req %s) (*%s, error) {\n", method, request, typ)
+	fmt.Fprintf(w, "path, err := client.Path(%s%s)\n", strconv.Quote(ri.Path), pathArgs)
+	fmt.Fprintf(w, "if err != nil {\nreturn nil, err\n}\n")
+	fmt.Fprintf(w, "out := new(%s)\n", typ)
+	fmt.Fprintf(w, "if err := %s; err != nil {\nreturn nil, err\n}\n", do)
+	fmt.Fprintf(w, "return out, nil\n}\n")
+}
+
+func (g *generator) writeImports(w io.Writer, hasRoutes bool) {
+	paths := make([]string, 0, len(g.imports))
+	for p := range g.imports {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	fmt.Fprintf(w, "import (\n")
+	if hasRoutes {
+		fmt.Fprintf(w, "\"context\"\n\n")
+	}
+	for _, p := range paths {
+		if name := g.imports[p]; name != path.Base(p) {
+			fmt.Fprintf(w, "%s %q\n", name, p)
+		} else {
+			fmt.Fprintf(w, "%q\n", p)
+		}
+	}
+	fmt.Fprintf(w, ")\n")
+}
+
+// typeExpr returns the Go source of t. Exported types are referenced from their packages
+// and other types are declared in place
+func (g *generator) typeExpr(t reflect.Type) string {
+	if t.Name() != "" {
+		if t.PkgPath() == "" {
+			return t.Name()
+		}
+		if isExported(t.Name()) && t.PkgPath() != "main" && !strings.Contains(t.Name(), "[") {
+			return g.qualify(t)
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return "*" + g.typeExpr(t.Elem())
+	case reflect.Slice:
+		return "[]" + g.typeExpr(t.Elem())
+	case reflect.Array:
+		return fmt.Sprintf("[%d]%s", t.Len(), g.typeExpr(t.Elem()))
+	case reflect.Map:
+		return fmt.Sprintf("map[%s]%s", g.typeExpr(t.Key()), g.typeExpr(t.Elem()))
+	case reflect.Struct:
+		if g.visiting[t] {
+			// a recursive unexported type cannot be declared in place
+			return "interface{}"
+		}
+		if g.visiting == nil {
+			g.visiting = make(map[reflect.Type]bool)
+		}
+		g.visiting[t] = true
+		defer delete(g.visiting, t)
+
+		var b strings.Builder
+		b.WriteString("struct {\n")
+		g.structFields(&b, t)
+		b.WriteString("}")
+		return b.String()
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		// unexported named types are replaced by their underlying type
+		return t.Kind().String()
+	}
+	return "interface{}"
+}
+
+// structFields writes the exported fields of the struct type t. The fields of unexported
+// embedded structs are promoted in place of the struct, as encoding/json does
+func (g *generator) structFields(b *strings.Builder, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		ft := f.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && ft.Kind() == reflect.Struct {
+			if f.PkgPath == "" && isExported(ft.Name()) && ft.PkgPath() != "main" {
+				b.WriteString(g.typeExpr(f.Type))
+				writeTag(b, f.Tag)
+				continue
+			}
+			if f.Tag == "" {
+				g.structFields(b, ft)
+				continue
+			}
+		}
+		if f.PkgPath != "" && !(f.Anonymous && ft.Kind() == reflect.Struct) {
+			continue
+		}
+		b.WriteString(naming.Exported(f.Name) + " " + g.typeExpr(f.Type))
+		writeTag(b, f.Tag)
+	}
+}
+
+func writeTag(b *strings.Builder, tag reflect.StructTag) {
+	if tag != "" {
+		if strings.Contains(string(tag), "`") {
+			b.WriteString(" " + strconv.Quote(string(tag)))
+		} else {
+			b.WriteString(" `" + string(tag) + "`")
+		}
+	}
+	b.WriteString("\n")
+}
+
+// qualify returns the name of the named type t qualified by the name of its package, which
+// is imported
+func (g *generator) qualify(t reflect.Type) string {
+	name, ok := g.imports[t.PkgPath()]
+	if !ok {
+		base := path.Base(t.PkgPath())
+		base = strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+				return r
+			}
+			return '_'
+		}, base)
+		name = base
+		for i := 2; g.aliases[name]; i++ {
+			name = base + strconv.Itoa(i)
+		}
+		g.aliases[name] = true
+		g.imports[t.PkgPath()] = name
+	}
+	return name + "." + t.Name()
+}
+
+// urlParamField returns the name of the field of the URLParams type t that is bound to the
+// path param key
+func urlParamField(t reflect.Type, key string) (string, bool) {
+	if t == nil || t.Kind() != reflect.Struct {
+		return "", false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		name, _ := schema.FieldName(f, "url")
+		if name == key {
+			return f.Name, true
+		}
+	}
+	return "", false
+}
+
+func isExported(name string) bool {
+	return name != "" && unicode.IsUpper([]rune(name)[0])
+}
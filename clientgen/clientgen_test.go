@@ -0,0 +1,165 @@
+package clientgen
+
+import (
+	"bytes"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/blockloop/boar"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type status string
+
+type lineItem struct {
+	SKU      string `json:"sku" validate:"required"`
+	Quantity int    `json:"quantity"`
+}
+
+type audit struct {
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type createOrder struct {
+	URLParams struct {
+		CustomerID int `url:"customer"`
+	}
+	Headers struct {
+		IdempotencyKey string `header:"Idempotency-Key"`
+	}
+	Body struct {
+		audit
+		Items    []lineItem    `json:"items"`
+		Status   status        `json:"status"`
+		Timeout  time.Duration `json:"timeout"`
+		Notes    *string       `json:"notes,omitempty"`
+		internal bool
+	}
+}
+
+func (h *createOrder) Handle(boar.Context) error { return nil }
+
+type listOrders struct {
+	Query struct {
+		Page  int      `query:"page"`
+		Sort  []string `query:"sort"`
+		Since time.Time
+	}
+}
+
+func (h *listOrders) Handle(boar.Context) error { return nil }
+
+func testRouter() *boar.Router {
+	r := boar.NewRouter()
+	r.Post("/customers/:customer/orders/:shard", func(boar.Context) (boar.Handler, error) {
+		return &createOrder{}, nil
+	}, boar.Handles(&createOrder{}), boar.Name("orders.create"))
+	r.Get("/orders", func(boar.Context) (boar.Handler, error) {
+		return &listOrders{}, nil
+	}, boar.Handles(&listOrders{}), boar.Name("orders.list"), boar.Returns([]lineItem{}))
+	r.MethodFunc(http.MethodGet, "/orders/:id", func(boar.Context) error { return nil },
+		boar.Name("order"), boar.Returns(&audit{}))
+	r.MethodFunc(http.MethodGet, "/files/*filepath", func(boar.Context) error { return nil }, boar.Name("file"))
+	r.MethodFunc(http.MethodDelete, "/files/*filepath", func(boar.Context) error { return nil }, boar.Name("file"))
+	r.MethodFunc(http.MethodGet, "/health", func(boar.Context) error { return nil })
+	return r
+}
+
+func generate(t *testing.T, routes []boar.RouteInfo) (string, *types.Package) {
+	buf := &bytes.Buffer{}
+	require.NoError(t, Generate(buf, routes, Options{Package: "orders"}))
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "client_gen.go", buf.Bytes(), parser.ParseComments)
+	require.NoError(t, err, buf.String())
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	pkg, err := conf.Check("orders", fset, []*ast.File{f}, nil)
+	require.NoError(t, err, buf.String())
+	return buf.String(), pkg
+}
+
+func TestGenerateCompiles(t *testing.T) {
+	src, pkg := generate(t, testRouter().Routes())
+
+	assert.Contains(t, src, "// Code generated by github.com/blockloop/boar/clientgen. DO NOT EDIT.")
+	client := pkg.Scope().Lookup("Client")
+	require.NotNil(t, client)
+
+	methods := types.NewMethodSet(types.NewPointer(client.Type()))
+	for _, name := range []string{"OrdersCreate", "OrdersList", "GetFile", "DeleteFile"} {
+		assert.NotNil(t, methods.Lookup(pkg, name), name)
+	}
+	assert.Nil(t, pkg.Scope().Lookup("HealthRequest"), "unnamed routes should be skipped")
+}
+
+func TestGenerateRequestTypes(t *testing.T) {
+	_, pkg := generate(t, testRouter().Routes())
+
+	req := pkg.Scope().Lookup("OrdersCreateRequest").Type().Underlying().(*types.Struct)
+	fields := make(map[string]string)
+	for i := 0; i < req.NumFields(); i++ {
+		fields[req.Field(i).Name()] = req.Field(i).Type().String()
+	}
+	assert.Equal(t, `struct{CustomerID int "url:\"customer\""}`, fields["URLParams"])
+	assert.Equal(t, `struct{IdempotencyKey string "header:\"Idempotency-Key\""}`, fields["Headers"])
+	assert.Equal(t, "string", fields["Shard"], "path params without a URLParams field should have their own field")
+	assert.Equal(t, `struct{CreatedAt time.Time "json:\"createdAt\""; `+
+		`Items []struct{SKU string "json:\"sku\" validate:\"required\""; Quantity int "json:\"quantity\""} "json:\"items\""; `+
+		`Status string "json:\"status\""; `+
+		`Timeout time.Duration "json:\"timeout\""; `+
+		`Notes *string "json:\"notes,omitempty\""}`, fields["Body"])
+	assert.NotContains(t, fields, "Query")
+
+	file := pkg.Scope().Lookup("GetFileRequest").Type().Underlying().(*types.Struct)
+	require.Equal(t, 1, file.NumFields())
+	assert.Equal(t, "Filepath", file.Field(0).Name())
+}
+
+func TestGenerateMethodBodies(t *testing.T) {
+	src, _ := generate(t, testRouter().Routes())
+
+	assert.Contains(t, src, `path, err := client.Path("/customers/:customer/orders/:shard", "customer", req.URLParams.CustomerID, "shard", req.Shard)`)
+	assert.Contains(t, src, `return c.Do(ctx, "POST", path, nil, client.Header(req.Headers), req.Body, out)`)
+	assert.Contains(t, src, `if err := c.Do(ctx, "GET", path, client.Query(req.Query), nil, nil, out); err != nil {`)
+	assert.Contains(t, src, `return c.Do(ctx, "DELETE", path, nil, nil, nil, out)`)
+}
+
+func TestGenerateResponseTypes(t *testing.T) {
+	_, pkg := generate(t, testRouter().Routes())
+	client := pkg.Scope().Lookup("Client").Type()
+	methods := types.NewMethodSet(types.NewPointer(client))
+
+	signature := func(name string) string {
+		sel := methods.Lookup(pkg, name)
+		require.NotNil(t, sel, name)
+		return types.TypeString(sel.Type(), types.RelativeTo(pkg))
+	}
+	assert.Equal(t, `func(ctx context.Context, req OrdersListRequest) (*[]struct{SKU string "json:\"sku\" validate:\"required\""; Quantity int "json:\"quantity\""}, error)`,
+		signature("OrdersList"))
+	assert.Equal(t, `func(ctx context.Context, req OrderRequest) (*struct{CreatedAt time.Time "json:\"createdAt\""}, error)`,
+		signature("Order"))
+	assert.Equal(t, `func(ctx context.Context, req OrdersCreateRequest, out interface{}) error`,
+		signature("OrdersCreate"))
+}
+
+func TestGenerateWithoutRoutes(t *testing.T) {
+	src, pkg := generate(t, nil)
+	assert.NotContains(t, src, `"context"`)
+	assert.NotNil(t, pkg.Scope().Lookup("NewClient"))
+}
+
+func TestGenerateRejectsConflictingNames(t *testing.T) {
+	routes := []boar.RouteInfo{
+		{Method: http.MethodGet, Path: "/a", Name: "user.list"},
+		{Method: http.MethodGet, Path: "/b", Name: "user-list"},
+	}
+	err := Generate(&bytes.Buffer{}, routes, Options{})
+	assert.EqualError(t, err, `routes "user.list" and "user-list" both generate the method UserList`)
+}
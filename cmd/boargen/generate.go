@@ -0,0 +1,344 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	bindImport       = "github.com/blockloop/boar/bind"
+	httprouterImport = "github.com/julienschmidt/httprouter"
+)
+
+// generate returns the source of the binders of the named handler types of the package in
+// dir. The output file is ignored when the package is loaded so that binders which are out of
+// date do not prevent it from type checking
+func generate(dir string, typeNames []string, output string) ([]byte, error) {
+	pkg, err := loadPackage(dir, output)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &generator{
+		pkg:     pkg,
+		imports: make(map[string]string),
+		names:   map[string]string{"bind": bindImport, "httprouter": httprouterImport},
+	}
+	body := &bytes.Buffer{}
+	for _, name := range typeNames {
+		obj, ok := pkg.Scope().Lookup(name).(*types.TypeName)
+		if !ok {
+			return nil, fmt.Errorf("type %s is not declared in package %s", name, pkg.Name())
+		}
+		if err := g.handler(body, obj); err != nil {
+			return nil, err
+		}
+	}
+
+	src := &bytes.Buffer{}
+	fmt.Fprintf(src, "// Code generated by github.com/blockloop/boar/cmd/boargen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(src, "package %s\n\n", pkg.Name())
+	g.writeImports(src)
+	src.Write(body.Bytes())
+
+	formatted, err := format.Source(src.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("could not format generated binders: %v", err)
+	}
+	return formatted, nil
+}
+
+// loadPackage parses and type checks the Go files of the package in dir, except for the file
+// named output
+func loadPackage(dir, output string) (*types.Package, error) {
+	bp, err := build.ImportDir(dir, 0)
+	if err != nil {
+		return nil, err
+	}
+	abs, err := filepath.Abs(output)
+	if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, name := range bp.GoFiles {
+		path := filepath.Join(bp.Dir, name)
+		if path == abs {
+			continue
+		}
+		f, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	return conf.Check(bp.ImportPath, fset, files, nil)
+}
+
+type generator struct {
+	pkg *types.Package
+	// imports holds the names of the packages used by the generated code by path, and names
+	// holds their paths by name
+	imports map[string]string
+	names   map[string]string
+}
+
+// handler writes the binders of the Query and URLParams fields of the handler type obj
+func (g *generator) handler(w *bytes.Buffer, obj *types.TypeName) error {
+	if _, ok := obj.Type().Underlying().(*types.Struct); !ok {
+		return fmt.Errorf("%s is not a struct", obj.Name())
+	}
+
+	found := false
+	if query, err := g.field(obj, "Query"); err != nil {
+		return err
+	} else if query != nil {
+		found = true
+		if err := g.bindQuery(w, obj, query); err != nil {
+			return err
+		}
+	}
+	if params, err := g.field(obj, "URLParams"); err != nil {
+		return err
+	} else if params != nil {
+		found = true
+		if err := g.bindParams(w, obj, params); err != nil {
+			return err
+		}
+	}
+	if !found {
+		return fmt.Errorf("%s has no Query or URLParams field", obj.Name())
+	}
+	return nil
+}
+
+// field returns the struct type of the named field of the handler obj, or nil when there is
+// no such field
+func (g *generator) field(obj *types.TypeName, name string) (*types.Struct, error) {
+	v, _, _ := types.LookupFieldOrMethod(types.NewPointer(obj.Type()), true, g.pkg, name)
+	f, ok := v.(*types.Var)
+	if !ok || !f.IsField() {
+		return nil, nil
+	}
+	st, ok := f.Type().Underlying().(*types.Struct)
+	if !ok {
+		return nil, fmt.Errorf("%s field of %s is not a struct", name, obj.Name())
+	}
+	return st, nil
+}
+
+// bindQuery writes the BindQuery method of obj, which binds query values in the same way
+// as bind.QueryValue. Errors name fields by their query key, as bind.QueryValue does
+func (g *generator) bindQuery(w *bytes.Buffer, obj *types.TypeName, st *types.Struct) error {
+	g.use("net/url")
+	fmt.Fprintf(w, "\n// BindQuery populates the Query field of %s from q\n", obj.Name())
+	fmt.Fprintf(w, "func (h *%s) BindQuery(q url.Values) error {\n", obj.Name())
+	for i := 0; i < st.NumFields(); i++ {
+		f := st.Field(i)
+		if !f.Exported() {
+			continue
+		}
+		if _, ok := f.Type().Underlying().(*types.Array); ok {
+			return fmt.Errorf("field Query.%s of %s is an array. use a slice instead", f.Name(), obj.Name())
+		}
+		key := f.Name()
+		if tag, ok := reflect.StructTag(st.Tag(i)).Lookup("query"); ok {
+			key = tag
+		}
+		if key == "-" {
+			continue
+		}
+		target := "h.Query." + f.Name()
+
+		if s, ok := f.Type().Underlying().(*types.Slice); ok {
+			fmt.Fprintf(w, "for _, val := range q[%q] {\n", key)
+			fmt.Fprintf(w, "val = strings.TrimSpace(val)\n")
+			g.use("strings")
+			value, err := g.parse(w, s.Elem(), "val", key)
+			if err != nil {
+				return fmt.Errorf("field Query.%s of %s: %v", f.Name(), obj.Name(), err)
+			}
+			fmt.Fprintf(w, "%s = append(%s, %s)\n}\n", target, target, value)
+			continue
+		}
+
+		kind, ok := basicKind(f.Type())
+		if !ok {
+			return fmt.Errorf("field Query.%s of %s has the unsupported type %s", f.Name(), obj.Name(), f.Type())
+		}
+		g.use("reflect")
+		g.use(bindImport)
+		fmt.Fprintf(w, "if vals := q[%q]; len(vals) > 0 {\n", key)
+		fmt.Fprintf(w, "val, err := bind.SingleValue(vals, %s, %q)\n", kind, key)
+		fmt.Fprintf(w, "if err != nil {\nreturn err\n}\n")
+		fmt.Fprintf(w, "if val != \"\" {\n")
+		value, err := g.parse(w, f.Type(), "val", key)
+		if err != nil {
+			return fmt.Errorf("field Query.%s of %s: %v", f.Name(), obj.Name(), err)
+		}
+		fmt.Fprintf(w, "%s = %s\n}\n}\n", target, value)
+	}
+	fmt.Fprintf(w, "return nil\n}\n")
+	return nil
+}
+
+// bindParams writes the BindParams method of obj, which binds url parameters in the same way
+// as bind.ParamsValue
+func (g *generator) bindParams(w *bytes.Buffer, obj *types.TypeName, st *types.Struct) error {
+	g.use(httprouterImport)
+	fmt.Fprintf(w, "\n// BindParams populates the URLParams field of %s from p\n", obj.Name())
+	fmt.Fprintf(w, "func (h *%s) BindParams(p httprouter.Params) error {\n", obj.Name())
+	for i := 0; i < st.NumFields(); i++ {
+		f := st.Field(i)
+		if !f.Exported() {
+			continue
+		}
+		if _, ok := basicKind(f.Type()); !ok {
+			return fmt.Errorf("field URLParams.%s of %s has the unsupported type %s", f.Name(), obj.Name(), f.Type())
+		}
+		key := f.Name()
+		if tag, ok := reflect.StructTag(st.Tag(i)).Lookup("url"); ok {
+			key = tag
+		}
+		if key == "-" {
+			continue
+		}
+
+		fmt.Fprintf(w, "if val := p.ByName(%q); len(val) > 0 {\n", key)
+		value, err := g.parse(w, f.Type(), "val", f.Name())
+		if err != nil {
+			return fmt.Errorf("field URLParams.%s of %s: %v", f.Name(), obj.Name(), err)
+		}
+		fmt.Fprintf(w, "h.URLParams.%s = %s\n}\n", f.Name(), value)
+	}
+	fmt.Fprintf(w, "return nil\n}\n")
+	return nil
+}
+
+// parse writes the statements which parse the string variable val into a value of type t
+// and returns the expression of the value
+func (g *generator) parse(w *bytes.Buffer, t types.Type, val, fieldName string) (string, error) {
+	kind, ok := basicKind(t)
+	if !ok {
+		return "", fmt.Errorf("%s is not a supported type", t)
+	}
+	b := t.Underlying().(*types.Basic)
+
+	var call, result string
+	switch {
+	case b.Kind() == types.String:
+		return g.convert(t, "string", val), nil
+	case b.Kind() == types.Bool:
+		call, result = fmt.Sprintf("bind.ParseBool(%s, %q)", val, fieldName), "bool"
+	case b.Info()&types.IsFloat != 0:
+		call, result = fmt.Sprintf("bind.ParseFloat(%s, %s, %q)", val, kind, fieldName), "float64"
+	case b.Info()&types.IsUnsigned != 0:
+		call, result = fmt.Sprintf("bind.ParseUint(%s, %s, %q)", val, kind, fieldName), "uint64"
+	default:
+		call, result = fmt.Sprintf("bind.ParseInt(%s, %s, %q)", val, kind, fieldName), "int64"
+	}
+	g.use(bindImport)
+	if b.Kind() != types.Bool {
+		g.use("reflect")
+	}
+	fmt.Fprintf(w, "v, err := %s\n", call)
+	fmt.Fprintf(w, "if err != nil {\nreturn err\n}\n")
+	return g.convert(t, result, "v"), nil
+}
+
+// convert returns the expression which converts the variable v of the basic type named from
+// into t
+func (g *generator) convert(t types.Type, from, v string) string {
+	to := types.TypeString(t, g.qualify)
+	if to == from {
+		return v
+	}
+	return to + "(" + v + ")"
+}
+
+// qualify returns the name that the package p is referred to by in the generated code
+func (g *generator) qualify(p *types.Package) string {
+	if p == g.pkg {
+		return ""
+	}
+	return g.use(p.Path())
+}
+
+// use imports the package path and returns its name
+func (g *generator) use(path string) string {
+	if name, ok := g.imports[path]; ok {
+		return name
+	}
+	base := filepath.Base(path)
+	name := base
+	for i := 2; g.names[name] != "" && g.names[name] != path; i++ {
+		name = base + strconv.Itoa(i)
+	}
+	g.names[name] = path
+	g.imports[path] = name
+	return name
+}
+
+func (g *generator) writeImports(w *bytes.Buffer) {
+	paths := make([]string, 0, len(g.imports))
+	for p := range g.imports {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	// the standard library is imported before other packages
+	sort.SliceStable(paths, func(i, j int) bool {
+		return isStd(paths[i]) && !isStd(paths[j])
+	})
+
+	fmt.Fprintf(w, "import (\n")
+	for i, p := range paths {
+		if i > 0 && isStd(paths[i-1]) && !isStd(p) {
+			fmt.Fprintf(w, "\n")
+		}
+		if name := g.imports[p]; name != filepath.Base(p) {
+			fmt.Fprintf(w, "%s %q\n", name, p)
+		} else {
+			fmt.Fprintf(w, "%q\n", p)
+		}
+	}
+	fmt.Fprintf(w, ")\n")
+}
+
+func isStd(path string) bool {
+	return !strings.Contains(strings.Split(path, "/")[0], ".")
+}
+
+// basicKind returns the reflect.Kind constant of a type which can be bound from a string
+func basicKind(t types.Type) (string, bool) {
+	b, ok := t.Underlying().(*types.Basic)
+	if !ok {
+		return "", false
+	}
+	kinds := map[types.BasicKind]string{
+		types.Bool: "Bool", types.String: "String",
+		types.Int: "Int", types.Int8: "Int8", types.Int16: "Int16", types.Int32: "Int32", types.Int64: "Int64",
+		types.Uint: "Uint", types.Uint8: "Uint8", types.Uint16: "Uint16", types.Uint32: "Uint32", types.Uint64: "Uint64",
+		types.Float32: "Float32", types.Float64: "Float64",
+	}
+	kind, ok := kinds[b.Kind()]
+	if !ok {
+		return "", false
+	}
+	return "reflect." + kind, true
+}
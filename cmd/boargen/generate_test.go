@@ -0,0 +1,104 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateMatchesFixture(t *testing.T) {
+	dir := filepath.Join("internal", "fixture")
+	output := filepath.Join(dir, "listorders_bind.go")
+	expected, err := ioutil.ReadFile(output)
+	require.NoError(t, err)
+
+	src, err := generate(dir, []string{"listOrders", "getOrder"}, output)
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), string(src), "run go generate in %s", dir)
+}
+
+func writePackage(t *testing.T, src string) string {
+	dir, err := ioutil.TempDir("", "boargen")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "handlers.go"), []byte(src), 0644))
+	return dir
+}
+
+func TestGenerateIgnoresOutputFile(t *testing.T) {
+	dir := writePackage(t, `package handlers
+
+type search struct {
+	Query struct {
+		Term string `+"`query:\"q\"`"+`
+	}
+}
+`)
+	// binders of fields which have since been removed do not compile
+	stale := filepath.Join(dir, "search_bind.go")
+	require.NoError(t, ioutil.WriteFile(stale, []byte("package handlers\n\nfunc (h *search) BindQuery() { h.Query.Removed = 1 }\n"), 0644))
+
+	src, err := generate(dir, []string{"search"}, stale)
+	require.NoError(t, err)
+	assert.Contains(t, string(src), "func (h *search) BindQuery(q url.Values) error {")
+	assert.Contains(t, string(src), `if vals := q["q"]; len(vals) > 0 {`)
+	assert.NotContains(t, string(src), "httprouter")
+}
+
+func TestGenerateErrors(t *testing.T) {
+	dir := writePackage(t, `package handlers
+
+type arrayQuery struct {
+	Query struct {
+		IDs [2]int
+	}
+}
+
+type mapQuery struct {
+	Query struct {
+		Filters map[string]string
+	}
+}
+
+type sliceParams struct {
+	URLParams struct {
+		IDs []int
+	}
+}
+
+type pointerParams struct {
+	URLParams struct {
+		ID *int
+	}
+}
+
+type badQuery struct {
+	Query int
+}
+
+type noFields struct {
+	Body struct{}
+}
+
+type notStruct int
+`)
+
+	tests := map[string]string{
+		"arrayQuery":    "field Query.IDs of arrayQuery is an array. use a slice instead",
+		"mapQuery":      "field Query.Filters of mapQuery has the unsupported type map[string]string",
+		"sliceParams":   "field URLParams.IDs of sliceParams has the unsupported type []int",
+		"pointerParams": "field URLParams.ID of pointerParams has the unsupported type *int",
+		"badQuery":      "Query field of badQuery is not a struct",
+		"noFields":      "noFields has no Query or URLParams field",
+		"notStruct":     "notStruct is not a struct",
+		"missing":       "type missing is not declared in package handlers",
+	}
+	for typ, expected := range tests {
+		_, err := generate(dir, []string{typ}, filepath.Join(dir, "out.go"))
+		assert.EqualError(t, err, expected, typ)
+	}
+}
@@ -0,0 +1,60 @@
+// Package fixture holds handlers whose binders are generated by boargen, to test them against
+// the reflective binders of the bind package
+package fixture
+
+//go:generate go run github.com/blockloop/boar/cmd/boargen -type=listOrders,getOrder
+
+import (
+	"net/http"
+
+	"github.com/blockloop/boar"
+)
+
+type status string
+
+type listOrders struct {
+	Query struct {
+		Search   string  `query:"q"`
+		Page     int     `query:"page"`
+		PerPage  uint8   `query:"per_page"`
+		Offset   int64   `query:"offset"`
+		Small    int8    `query:"small"`
+		Medium   int16   `query:"medium"`
+		Large    int32   `query:"large"`
+		Big      uint64  `query:"big"`
+		Unsigned uint    `query:"unsigned"`
+		Word     uint16  `query:"word"`
+		Long     uint32  `query:"long"`
+		Ratio    float32 `query:"ratio"`
+		Score    float64 `query:"score"`
+		Active   bool    `query:"active"`
+		Status   status  `query:"status"`
+		Method   http.ConnState
+		IDs      []int     `query:"id"`
+		Tags     []string  `query:"tag"`
+		Statuses []status  `query:"status_in"`
+		Flags    []bool    `query:"flag"`
+		Weights  []float64 `query:"weight"`
+		Skipped  string    `query:"-"`
+		NoTag    string
+		internal string
+	}
+}
+
+func (h *listOrders) Handle(boar.Context) error { return nil }
+
+type getOrder struct {
+	URLParams struct {
+		ID       int64   `url:"id"`
+		Customer string  `url:"customer"`
+		Version  uint16  `url:"version"`
+		Price    float64 `url:"price"`
+		Draft    bool    `url:"draft"`
+		Status   status  `url:"status"`
+		Skipped  string  `url:"-"`
+		Shard    int8
+		internal string
+	}
+}
+
+func (h *getOrder) Handle(boar.Context) error { return nil }
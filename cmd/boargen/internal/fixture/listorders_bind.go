@@ -0,0 +1,305 @@
+// Code generated by github.com/blockloop/boar/cmd/boargen. DO NOT EDIT.
+
+package fixture
+
+import (
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/blockloop/boar/bind"
+	"github.com/julienschmidt/httprouter"
+)
+
+// BindQuery populates the Query field of listOrders from q
+func (h *listOrders) BindQuery(q url.Values) error {
+	if vals := q["q"]; len(vals) > 0 {
+		val, err := bind.SingleValue(vals, reflect.String, "q")
+		if err != nil {
+			return err
+		}
+		if val != "" {
+			h.Query.Search = val
+		}
+	}
+	if vals := q["page"]; len(vals) > 0 {
+		val, err := bind.SingleValue(vals, reflect.Int, "page")
+		if err != nil {
+			return err
+		}
+		if val != "" {
+			v, err := bind.ParseInt(val, reflect.Int, "page")
+			if err != nil {
+				return err
+			}
+			h.Query.Page = int(v)
+		}
+	}
+	if vals := q["per_page"]; len(vals) > 0 {
+		val, err := bind.SingleValue(vals, reflect.Uint8, "per_page")
+		if err != nil {
+			return err
+		}
+		if val != "" {
+			v, err := bind.ParseUint(val, reflect.Uint8, "per_page")
+			if err != nil {
+				return err
+			}
+			h.Query.PerPage = uint8(v)
+		}
+	}
+	if vals := q["offset"]; len(vals) > 0 {
+		val, err := bind.SingleValue(vals, reflect.Int64, "offset")
+		if err != nil {
+			return err
+		}
+		if val != "" {
+			v, err := bind.ParseInt(val, reflect.Int64, "offset")
+			if err != nil {
+				return err
+			}
+			h.Query.Offset = v
+		}
+	}
+	if vals := q["small"]; len(vals) > 0 {
+		val, err := bind.SingleValue(vals, reflect.Int8, "small")
+		if err != nil {
+			return err
+		}
+		if val != "" {
+			v, err := bind.ParseInt(val, reflect.Int8, "small")
+			if err != nil {
+				return err
+			}
+			h.Query.Small = int8(v)
+		}
+	}
+	if vals := q["medium"]; len(vals) > 0 {
+		val, err := bind.SingleValue(vals, reflect.Int16, "medium")
+		if err != nil {
+			return err
+		}
+		if val != "" {
+			v, err := bind.ParseInt(val, reflect.Int16, "medium")
+			if err != nil {
+				return err
+			}
+			h.Query.Medium = int16(v)
+		}
+	}
+	if vals := q["large"]; len(vals) > 0 {
+		val, err := bind.SingleValue(vals, reflect.Int32, "large")
+		if err != nil {
+			return err
+		}
+		if val != "" {
+			v, err := bind.ParseInt(val, reflect.Int32, "large")
+			if err != nil {
+				return err
+			}
+			h.Query.Large = int32(v)
+		}
+	}
+	if vals := q["big"]; len(vals) > 0 {
+		val, err := bind.SingleValue(vals, reflect.Uint64, "big")
+		if err != nil {
+			return err
+		}
+		if val != "" {
+			v, err := bind.ParseUint(val, reflect.Uint64, "big")
+			if err != nil {
+				return err
+			}
+			h.Query.Big = v
+		}
+	}
+	if vals := q["unsigned"]; len(vals) > 0 {
+		val, err := bind.SingleValue(vals, reflect.Uint, "unsigned")
+		if err != nil {
+			return err
+		}
+		if val != "" {
+			v, err := bind.ParseUint(val, reflect.Uint, "unsigned")
+			if err != nil {
+				return err
+			}
+			h.Query.Unsigned = uint(v)
+		}
+	}
+	if vals := q["word"]; len(vals) > 0 {
+		val, err := bind.SingleValue(vals, reflect.Uint16, "word")
+		if err != nil {
+			return err
+		}
+		if val != "" {
+			v, err := bind.ParseUint(val, reflect.Uint16, "word")
+			if err != nil {
+				return err
+			}
+			h.Query.Word = uint16(v)
+		}
+	}
+	if vals := q["long"]; len(vals) > 0 {
+		val, err := bind.SingleValue(vals, reflect.Uint32, "long")
+		if err != nil {
+			return err
+		}
+		if val != "" {
+			v, err := bind.ParseUint(val, reflect.Uint32, "long")
+			if err != nil {
+				return err
+			}
+			h.Query.Long = uint32(v)
+		}
+	}
+	if vals := q["ratio"]; len(vals) > 0 {
+		val, err := bind.SingleValue(vals, reflect.Float32, "ratio")
+		if err != nil {
+			return err
+		}
+		if val != "" {
+			v, err := bind.ParseFloat(val, reflect.Float32, "ratio")
+			if err != nil {
+				return err
+			}
+			h.Query.Ratio = float32(v)
+		}
+	}
+	if vals := q["score"]; len(vals) > 0 {
+		val, err := bind.SingleValue(vals, reflect.Float64, "score")
+		if err != nil {
+			return err
+		}
+		if val != "" {
+			v, err := bind.ParseFloat(val, reflect.Float64, "score")
+			if err != nil {
+				return err
+			}
+			h.Query.Score = v
+		}
+	}
+	if vals := q["active"]; len(vals) > 0 {
+		val, err := bind.SingleValue(vals, reflect.Bool, "active")
+		if err != nil {
+			return err
+		}
+		if val != "" {
+			v, err := bind.ParseBool(val, "active")
+			if err != nil {
+				return err
+			}
+			h.Query.Active = v
+		}
+	}
+	if vals := q["status"]; len(vals) > 0 {
+		val, err := bind.SingleValue(vals, reflect.String, "status")
+		if err != nil {
+			return err
+		}
+		if val != "" {
+			h.Query.Status = status(val)
+		}
+	}
+	if vals := q["Method"]; len(vals) > 0 {
+		val, err := bind.SingleValue(vals, reflect.Int, "Method")
+		if err != nil {
+			return err
+		}
+		if val != "" {
+			v, err := bind.ParseInt(val, reflect.Int, "Method")
+			if err != nil {
+				return err
+			}
+			h.Query.Method = http.ConnState(v)
+		}
+	}
+	for _, val := range q["id"] {
+		val = strings.TrimSpace(val)
+		v, err := bind.ParseInt(val, reflect.Int, "id")
+		if err != nil {
+			return err
+		}
+		h.Query.IDs = append(h.Query.IDs, int(v))
+	}
+	for _, val := range q["tag"] {
+		val = strings.TrimSpace(val)
+		h.Query.Tags = append(h.Query.Tags, val)
+	}
+	for _, val := range q["status_in"] {
+		val = strings.TrimSpace(val)
+		h.Query.Statuses = append(h.Query.Statuses, status(val))
+	}
+	for _, val := range q["flag"] {
+		val = strings.TrimSpace(val)
+		v, err := bind.ParseBool(val, "flag")
+		if err != nil {
+			return err
+		}
+		h.Query.Flags = append(h.Query.Flags, v)
+	}
+	for _, val := range q["weight"] {
+		val = strings.TrimSpace(val)
+		v, err := bind.ParseFloat(val, reflect.Float64, "weight")
+		if err != nil {
+			return err
+		}
+		h.Query.Weights = append(h.Query.Weights, v)
+	}
+	if vals := q["NoTag"]; len(vals) > 0 {
+		val, err := bind.SingleValue(vals, reflect.String, "NoTag")
+		if err != nil {
+			return err
+		}
+		if val != "" {
+			h.Query.NoTag = val
+		}
+	}
+	return nil
+}
+
+// BindParams populates the URLParams field of getOrder from p
+func (h *getOrder) BindParams(p httprouter.Params) error {
+	if val := p.ByName("id"); len(val) > 0 {
+		v, err := bind.ParseInt(val, reflect.Int64, "ID")
+		if err != nil {
+			return err
+		}
+		h.URLParams.ID = v
+	}
+	if val := p.ByName("customer"); len(val) > 0 {
+		h.URLParams.Customer = val
+	}
+	if val := p.ByName("version"); len(val) > 0 {
+		v, err := bind.ParseUint(val, reflect.Uint16, "Version")
+		if err != nil {
+			return err
+		}
+		h.URLParams.Version = uint16(v)
+	}
+	if val := p.ByName("price"); len(val) > 0 {
+		v, err := bind.ParseFloat(val, reflect.Float64, "Price")
+		if err != nil {
+			return err
+		}
+		h.URLParams.Price = v
+	}
+	if val := p.ByName("draft"); len(val) > 0 {
+		v, err := bind.ParseBool(val, "Draft")
+		if err != nil {
+			return err
+		}
+		h.URLParams.Draft = v
+	}
+	if val := p.ByName("status"); len(val) > 0 {
+		h.URLParams.Status = status(val)
+	}
+	if val := p.ByName("Shard"); len(val) > 0 {
+		v, err := bind.ParseInt(val, reflect.Int8, "Shard")
+		if err != nil {
+			return err
+		}
+		h.URLParams.Shard = int8(v)
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package fixture
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/blockloop/boar"
+	"github.com/blockloop/boar/bind"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	_ boar.QueryBinder  = (*listOrders)(nil)
+	_ boar.ParamsBinder = (*getOrder)(nil)
+)
+
+var queries = []string{
+	"",
+	"q=boar&page=2&per_page=50&offset=-9000000000&active=true&status=open",
+	"small=-128&medium=32767&large=-2147483648&big=18446744073709551615&unsigned=7&word=65535&long=4294967295",
+	"ratio=1.5&score=-0.25&Method=2",
+	"id=1&id=%202%20&tag=a&tag=&tag=%20b&status_in=open&status_in=closed&flag=1&flag=false&weight=0.5",
+	"q=%20%20padded%20%20&page=%20%20",
+	"q=&page=&active=",
+	"NoTag=yes&Skipped=no&internal=no&-=no",
+	"page=1&page=2",
+	"q=a&q=b",
+	"page=abc",
+	"page=1.5",
+	"per_page=256",
+	"per_page=-1",
+	"small=128",
+	"medium=-32769",
+	"large=2147483648",
+	"big=18446744073709551616",
+	"word=65536",
+	"long=4294967296",
+	"ratio=3.5e38",
+	"ratio=Inf",
+	"score=1e400",
+	"score=NaN",
+	"active=yes",
+	"id=1&id=x",
+	"flag=true&flag=maybe",
+	"weight=",
+	"Method=-1",
+}
+
+func TestBindQueryParity(t *testing.T) {
+	failures := 0
+	for _, raw := range queries {
+		q, err := url.ParseQuery(raw)
+		if !assert.NoError(t, err, raw) {
+			continue
+		}
+
+		var reflective, generated listOrders
+		expectedErr := bind.QueryValue(reflect.ValueOf(&reflective.Query).Elem(), q)
+		actualErr := generated.BindQuery(q)
+
+		assert.Equal(t, expectedErr, actualErr, raw)
+		if expectedErr != nil {
+			assert.EqualError(t, actualErr, expectedErr.Error(), raw)
+			failures++
+			continue
+		}
+		assertSameFields(t, reflective.Query, generated.Query, raw)
+	}
+	assert.True(t, failures > 0 && failures < len(queries), "both valid and invalid queries should be tested")
+}
+
+var params = []httprouter.Params{
+	nil,
+	{{Key: "id", Value: "42"}, {Key: "customer", Value: "acme"}, {Key: "version", Value: "3"}},
+	{{Key: "price", Value: "9.99"}, {Key: "draft", Value: "true"}, {Key: "status", Value: "open"}, {Key: "Shard", Value: "-7"}},
+	{{Key: "id", Value: ""}, {Key: "customer", Value: " spaced "}, {Key: "-", Value: "x"}, {Key: "Skipped", Value: "x"}},
+	{{Key: "id", Value: "abc"}},
+	{{Key: "id", Value: " 1"}},
+	{{Key: "version", Value: "65536"}},
+	{{Key: "version", Value: "-1"}},
+	{{Key: "price", Value: "free"}},
+	{{Key: "draft", Value: "maybe"}},
+	{{Key: "Shard", Value: "128"}},
+}
+
+func TestBindParamsParity(t *testing.T) {
+	for _, p := range params {
+		var reflective, generated getOrder
+		expectedErr := bind.ParamsValue(reflect.ValueOf(&reflective.URLParams).Elem(), p)
+		actualErr := generated.BindParams(p)
+
+		assert.Equal(t, expectedErr, actualErr, "%v", p)
+		if expectedErr != nil {
+			assert.EqualError(t, actualErr, expectedErr.Error(), "%v", p)
+		}
+		if expectedErr == nil {
+			assertSameFields(t, reflective.URLParams, generated.URLParams, p)
+		}
+	}
+}
+
+// assertSameFields compares expected and actual field by field, so that NaN values which are
+// set by both are considered equal
+func assertSameFields(t *testing.T, expected, actual interface{}, input interface{}) {
+	e, a := reflect.ValueOf(expected), reflect.ValueOf(actual)
+	for i := 0; i < e.NumField(); i++ {
+		if !e.Field(i).CanInterface() {
+			continue
+		}
+		ef, af := e.Field(i).Interface(), a.Field(i).Interface()
+		if f, ok := ef.(float64); ok && f != f {
+			af := af.(float64)
+			assert.True(t, af != af, "%s of %v should be NaN", e.Type().Field(i).Name, input)
+			continue
+		}
+		assert.Equal(t, ef, af, "%s of %v", e.Type().Field(i).Name, input)
+	}
+}
+
+func BenchmarkBindQueryReflect(b *testing.B) {
+	q, _ := url.ParseQuery(queries[1] + "&" + queries[4])
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var h listOrders
+		if err := bind.QueryValue(reflect.ValueOf(&h.Query).Elem(), q); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkBindQueryGenerated(b *testing.B) {
+	q, _ := url.ParseQuery(queries[1] + "&" + queries[4])
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var h listOrders
+		if err := h.BindQuery(q); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
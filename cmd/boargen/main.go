@@ -0,0 +1,62 @@
+// Command boargen generates BindQuery and BindParams methods for boar handlers so that their
+// Query and URLParams fields are populated without reflection. The Router calls the methods in
+// place of binding the fields with reflection, and the fields are validated as before.
+//
+// boargen is run in the directory of the package that declares the handlers, usually with
+// go generate:
+//
+//	//go:generate go run github.com/blockloop/boar/cmd/boargen -type=listOrders,createOrder
+//
+// The methods are written to <type>_bind.go, where <type> is the first type in lower case,
+// unless another file is given with -output. The fields of Query and URLParams may be strings,
+// bools, integers and floats, or slices of them for Query, as they are when they are bound
+// with reflection. The methods must be generated again when the fields change
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("boargen: ")
+
+	typeNames := flag.String("type", "", "comma-separated list of handler type names; required")
+	output := flag.String("output", "", "output file name; default <type>_bind.go")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: boargen -type=T[,T...] [-output=file] [directory]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if *typeNames == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+	types := strings.Split(*typeNames, ",")
+	if *output == "" {
+		*output = strings.ToLower(types[0]) + "_bind.go"
+	}
+	out := *output
+	if !filepath.IsAbs(out) {
+		out = filepath.Join(dir, out)
+	}
+
+	src, err := generate(dir, types, out)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := ioutil.WriteFile(out, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -2,10 +2,17 @@
 package boar
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"encoding/xml"
 	"fmt"
+	"io"
+	"mime"
 	"net/http"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/blockloop/boar/bind"
 	"github.com/gorilla/schema"
@@ -38,9 +45,58 @@ type Context interface {
 	// ReadForm reads the contents of the request form and populates the values of v.
 	ReadForm(v interface{}) error
 
+	// ReadXML decodes the XML request body into v. Documents may declare the UTF-8,
+	// ISO-8859-1 and UTF-16 encodings
+	ReadXML(v interface{}) error
+
+	// MultipartReader returns a reader that iterates over the parts of a multipart
+	// request body without buffering them. An HTTPError with a status of 415 is returned
+	// when the request is not multipart
+	MultipartReader() (*MultipartReader, error)
+
+	// Bind decodes the request body into v using the decoder registered for the
+	// content-type of the request. An HTTPError with a status of 415 is returned when
+	// there is no decoder for the content-type
+	Bind(v interface{}) error
+
 	// WriteJSON writes the status code and then sends a json response message
 	WriteJSON(status int, v interface{}) error
 
+	// WriteXML writes the status code and then sends an xml response message
+	WriteXML(status int, v interface{}) error
+
+	// Render writes the status code and then sends v encoded with the encoder that best
+	// matches the Accept header of the request. The content-type of the response is set to
+	// the media type of the encoder. ErrNotAcceptable is returned and nothing is written
+	// when none of the registered encoders are acceptable
+	Render(status int, v interface{}) error
+
+	// SSE begins a Server-Sent Events stream. The response headers are sent immediately
+	// and the response is no longer buffered. Subsequent calls return the same stream,
+	// which is closed by the Router once the handler returns
+	SSE() (*EventStream, error)
+
+	// StreamJSON begins streaming JSON values to the client with the given status. Values
+	// are written as application/x-ndjson when the client prefers it and as the elements of
+	// a JSON array otherwise. Subsequent calls return the same stream, which is closed by the
+	// Router once the handler returns
+	StreamJSON(status int) (*JSONStream, error)
+
+	// ServeContent replies to the request with the contents of content. Range, If-Range,
+	// If-Modified-Since and the other conditional request headers are honored as they are by
+	// http.ServeContent, including multipart/byteranges responses for multiple ranges. The
+	// content-type is detected from the extension of name, or from the content, unless it
+	// has already been set
+	ServeContent(name string, modtime time.Time, content io.ReadSeeker) error
+
+	// File replies to the request with the contents of the named file in the same way as
+	// ServeContent. ErrNotFound is returned when the file does not exist or is a directory
+	File(path string) error
+
+	// Attachment replies with the named file like File, but with a Content-Disposition header
+	// that tells browsers to download it as filename
+	Attachment(path, filename string) error
+
 	// WriteStatus is an alias to c.Response().WriteHeader(status)
 	WriteStatus(status int) error
 
@@ -50,6 +106,10 @@ type Context interface {
 	// ReadURLParams maps all URL parameters to struct fields of v and returns
 	// a validation error if there are any type mismatches
 	ReadURLParams(v interface{}) error
+
+	// URLFor builds the URL of the route registered with the given Name in the same way as
+	// Router.URL
+	URLFor(name string, params ...string) (string, error)
 }
 
 // NewContext creates a new Context based on the rquest and response writer given
@@ -57,12 +117,21 @@ func NewContext(r *http.Request, w http.ResponseWriter, ps httprouter.Params) Co
 	return newContext(r, w, ps)
 }
 
+// newContext creates a Context which is not served by a Router, so it has its own copy of
+// the default decoders and encoders
 func newContext(r *http.Request, w http.ResponseWriter, ps httprouter.Params) *requestContext {
+	return newRequestContext(r, w, ps, defaultDecoders(), defaultEncoders())
+}
+
+func newRequestContext(r *http.Request, w http.ResponseWriter, ps httprouter.Params, decoders map[string]DecoderFunc, encoders []encoder) *requestContext {
 	return &requestContext{
 		response:   NewBufferedResponseWriter(w),
 		request:    r,
 		urlParams:  ps,
 		formParser: schema.NewDecoder(),
+		decoders:   decoders,
+		encoders:   encoders,
+		route:      &route{},
 	}
 }
 
@@ -71,6 +140,14 @@ type requestContext struct {
 	request    *http.Request
 	urlParams  httprouter.Params
 	formParser *schema.Decoder
+	decoders   map[string]DecoderFunc
+	encoders   []encoder
+	route      *route
+	router     *Router
+	sse        *EventStream
+	jsonStream *JSONStream
+
+	decodeOptions DecodeOptions
 }
 
 func (r *requestContext) Context() context.Context {
@@ -99,14 +176,51 @@ func (r *requestContext) Response() ResponseWriter {
 }
 
 func (r *requestContext) ReadJSON(v interface{}) error {
-	if err := json.NewDecoder(r.Request().Body).Decode(v); err != nil {
-		return NewValidationError(bodyField, fmt.Errorf("failed to parse JSON body: %v", err))
+	if err := decodeJSONBody(r.Request().Body, v, r.decodeOptions); err != nil {
+		if _, ok := err.(HTTPError); ok {
+			return err
+		}
+		return NewValidationError(bodyField, err)
 	}
 	return nil
 }
 
+func (r *requestContext) ReadXML(v interface{}) error {
+	dec := xml.NewDecoder(r.Request().Body)
+	_, transcoded := r.Request().Body.(transcodedBody)
+	dec.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		// a body transcoded from the charset of the content-type is already UTF-8, whatever
+		// the encoding declared by the document
+		if transcoded {
+			return input, nil
+		}
+		return charsetReader(strings.ToLower(charset), input)
+	}
+	if err := dec.Decode(v); err != nil {
+		if tooLarge := requestTooLarge(err); tooLarge != nil {
+			return tooLarge
+		}
+		return NewValidationError(bodyField, fmt.Errorf("failed to parse XML body: %v", err))
+	}
+	return nil
+}
+
+func (r *requestContext) MultipartReader() (*MultipartReader, error) {
+	mr, err := r.Request().MultipartReader()
+	if err != nil {
+		if err == http.ErrNotMultipart {
+			return nil, unsupportedMediaType(r.Request().Header.Get("content-type"))
+		}
+		return nil, NewHTTPError(http.StatusBadRequest, err)
+	}
+	return &MultipartReader{r: mr}, nil
+}
+
 func (r *requestContext) ReadForm(v interface{}) error {
 	if err := r.Request().ParseForm(); err != nil {
+		if tooLarge := requestTooLarge(err); tooLarge != nil {
+			return tooLarge
+		}
 		return NewValidationError(bodyField, err)
 	}
 
@@ -116,6 +230,21 @@ func (r *requestContext) ReadForm(v interface{}) error {
 	return nil
 }
 
+func (r *requestContext) Bind(v interface{}) error {
+	decode, err := findDecoder(r.Request(), r.decoders, r.route.consumes)
+	if err != nil {
+		return err
+	}
+
+	if err := decode(r, v); err != nil {
+		if _, ok := err.(HTTPError); ok {
+			return err
+		}
+		return NewValidationError(bodyField, err)
+	}
+	return nil
+}
+
 func (r *requestContext) WriteJSON(status int, v interface{}) error {
 	r.response.Header().Set("content-type", "application/json")
 	r.response.WriteHeader(status)
@@ -125,6 +254,99 @@ func (r *requestContext) WriteJSON(status int, v interface{}) error {
 	return nil
 }
 
+func (r *requestContext) WriteXML(status int, v interface{}) error {
+	r.response.Header().Set("content-type", contentTypeXML)
+	r.response.WriteHeader(status)
+	if err := encodeXML(r.Response(), v); err != nil {
+		return fmt.Errorf("could not encode XML response: %+v", err)
+	}
+	return nil
+}
+
+func (r *requestContext) Render(status int, v interface{}) error {
+	addVary(r.response.Header(), "Accept")
+
+	enc, ok := negotiate(r.Request().Header.Get("accept"), r.encoders)
+	if !ok {
+		return ErrNotAcceptable
+	}
+
+	// encode into a buffer first so that nothing is written when encoding fails
+	buf := &bytes.Buffer{}
+	if err := enc.encode(buf, v); err != nil {
+		return fmt.Errorf("could not encode %s response: %+v", enc.mediaType, err)
+	}
+
+	r.response.Header().Set("content-type", enc.contentType())
+	r.response.WriteHeader(status)
+	_, err := buf.WriteTo(r.response)
+	return err
+}
+
+func (r *requestContext) SSE() (*EventStream, error) {
+	if r.sse != nil {
+		return r.sse, nil
+	}
+	s, err := newEventStream(r)
+	if err != nil {
+		return nil, err
+	}
+	r.sse = s
+	return s, nil
+}
+
+func (r *requestContext) StreamJSON(status int) (*JSONStream, error) {
+	if r.jsonStream != nil {
+		return r.jsonStream, nil
+	}
+	s, err := newJSONStream(r, status)
+	if err != nil {
+		return nil, err
+	}
+	r.jsonStream = s
+	return s, nil
+}
+
+func (r *requestContext) ServeContent(name string, modtime time.Time, content io.ReadSeeker) error {
+	http.ServeContent(r.response, r.Request(), name, modtime, content)
+	return nil
+}
+
+func (r *requestContext) File(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fileError(err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return fileError(err)
+	}
+	if info.IsDir() {
+		return ErrNotFound
+	}
+	return r.ServeContent(info.Name(), info.ModTime(), f)
+}
+
+func (r *requestContext) Attachment(path, filename string) error {
+	r.response.Header().Set("content-disposition", mime.FormatMediaType("attachment", map[string]string{
+		"filename": filename,
+	}))
+	return r.File(path)
+}
+
+// fileError converts an error opening a file into an HTTPError
+func fileError(err error) error {
+	switch {
+	case os.IsNotExist(err):
+		return ErrNotFound
+	case os.IsPermission(err):
+		return ErrForbidden
+	}
+	return err
+}
+
 func (r *requestContext) ReadQuery(v interface{}) error {
 	if err := bind.Query(v, r.Request().URL.Query()); err != nil {
 		return NewValidationError(queryField, err)
@@ -0,0 +1,173 @@
+package boar
+
+import (
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const fileContent = "0123456789abcdefghij"
+
+var fileModTime = time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func writeTestFile(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "report.txt")
+	require.NoError(t, ioutil.WriteFile(path, []byte(fileContent), 0644))
+	require.NoError(t, os.Chtimes(path, fileModTime, fileModTime))
+	return path
+}
+
+// serveFile serves the request with h in both buffered and streaming response modes
+func serveFile(t *testing.T, req *http.Request, h HandlerFunc, test func(*testing.T, *httptest.ResponseRecorder)) {
+	modes := map[string][]RouteOption{
+		"buffered":  nil,
+		"streaming": {StreamResponse()},
+	}
+	for name, opts := range modes {
+		t.Run(name, func(t *testing.T) {
+			r := NewRouter()
+			r.MethodFunc(http.MethodGet, "/", h, opts...)
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+			test(t, rec)
+		})
+	}
+}
+
+func serveContentHandler(c Context) error {
+	return c.ServeContent("report.txt", fileModTime, strings.NewReader(fileContent))
+}
+
+func TestServeContent(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	serveFile(t, req, serveContentHandler, func(t *testing.T, rec *httptest.ResponseRecorder) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, fileContent, rec.Body.String())
+		assert.Equal(t, "text/plain; charset=utf-8", rec.Header().Get("content-type"))
+		assert.Equal(t, "bytes", rec.Header().Get("accept-ranges"))
+		assert.Equal(t, fileModTime.Format(http.TimeFormat), rec.Header().Get("last-modified"))
+	})
+}
+
+func TestServeContentRange(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Range", "bytes=10-14")
+	serveFile(t, req, serveContentHandler, func(t *testing.T, rec *httptest.ResponseRecorder) {
+		assert.Equal(t, http.StatusPartialContent, rec.Code)
+		assert.Equal(t, "abcde", rec.Body.String())
+		assert.Equal(t, "bytes 10-14/20", rec.Header().Get("content-range"))
+	})
+}
+
+func TestServeContentUnsatisfiableRange(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Range", "bytes=50-60")
+	serveFile(t, req, serveContentHandler, func(t *testing.T, rec *httptest.ResponseRecorder) {
+		assert.Equal(t, http.StatusRequestedRangeNotSatisfiable, rec.Code)
+		assert.Equal(t, "bytes */20", rec.Header().Get("content-range"))
+	})
+}
+
+func TestServeContentMultipleRanges(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Range", "bytes=0-1,18-19")
+	serveFile(t, req, serveContentHandler, func(t *testing.T, rec *httptest.ResponseRecorder) {
+		require.Equal(t, http.StatusPartialContent, rec.Code)
+		mt, params, err := mime.ParseMediaType(rec.Header().Get("content-type"))
+		require.NoError(t, err)
+		assert.Equal(t, "multipart/byteranges", mt)
+
+		mr := multipart.NewReader(rec.Body, params["boundary"])
+		var parts []string
+		for {
+			p, err := mr.NextPart()
+			if err != nil {
+				break
+			}
+			b, err := ioutil.ReadAll(p)
+			require.NoError(t, err)
+			parts = append(parts, p.Header.Get("content-range")+"="+string(b))
+		}
+		assert.Equal(t, []string{"bytes 0-1/20=01", "bytes 18-19/20=ij"}, parts)
+	})
+}
+
+func TestServeContentIfRangeMismatchSendsEverything(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Range", "bytes=10-14")
+	req.Header.Set("If-Range", fileModTime.Add(-time.Hour).Format(http.TimeFormat))
+	serveFile(t, req, serveContentHandler, func(t *testing.T, rec *httptest.ResponseRecorder) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, fileContent, rec.Body.String())
+	})
+}
+
+func TestServeContentIfRangeMatch(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Range", "bytes=10-14")
+	req.Header.Set("If-Range", fileModTime.Format(http.TimeFormat))
+	serveFile(t, req, serveContentHandler, func(t *testing.T, rec *httptest.ResponseRecorder) {
+		assert.Equal(t, http.StatusPartialContent, rec.Code)
+		assert.Equal(t, "abcde", rec.Body.String())
+	})
+}
+
+func TestServeContentIfModifiedSince(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("If-Modified-Since", fileModTime.Format(http.TimeFormat))
+	serveFile(t, req, serveContentHandler, func(t *testing.T, rec *httptest.ResponseRecorder) {
+		assert.Equal(t, http.StatusNotModified, rec.Code)
+		assert.Empty(t, rec.Body.String())
+	})
+}
+
+func TestFile(t *testing.T) {
+	path := writeTestFile(t)
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Range", "bytes=-5")
+	serveFile(t, req, func(c Context) error {
+		return c.File(path)
+	}, func(t *testing.T, rec *httptest.ResponseRecorder) {
+		assert.Equal(t, http.StatusPartialContent, rec.Code)
+		assert.Equal(t, "fghij", rec.Body.String())
+		assert.Equal(t, fileModTime.Format(http.TimeFormat), rec.Header().Get("last-modified"))
+	})
+}
+
+func TestFileNotFound(t *testing.T) {
+	dir := t.TempDir()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, path := range []string{filepath.Join(dir, "missing.txt"), dir} {
+		serveFile(t, req, func(c Context) error {
+			return c.File(path)
+		}, func(t *testing.T, rec *httptest.ResponseRecorder) {
+			assert.Equal(t, http.StatusNotFound, rec.Code)
+		})
+	}
+}
+
+func TestAttachment(t *testing.T) {
+	path := writeTestFile(t)
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	serveFile(t, req, func(c Context) error {
+		return c.Attachment(path, "résumé 2020.txt")
+	}, func(t *testing.T, rec *httptest.ResponseRecorder) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, fileContent, rec.Body.String())
+
+		disposition, params, err := mime.ParseMediaType(rec.Header().Get("content-disposition"))
+		require.NoError(t, err)
+		assert.Equal(t, "attachment", disposition)
+		assert.Equal(t, "résumé 2020.txt", params["filename"])
+	})
+}
@@ -55,3 +55,28 @@ func TestReadJSONSetsBadRequestStatusIfJSONIsInvalid(t *testing.T) {
 	err := c.ReadJSON(&req).(HTTPError)
 	assert.Equal(t, http.StatusBadRequest, err.Status())
 }
+
+func TestReadXMLParsesXMLBody(t *testing.T) {
+	body := bytes.NewBufferString(`<user><name>brett</name><age>100</age></user>`)
+	r := httptest.NewRequest(http.MethodPost, "/", body)
+
+	c := NewContext(r, nil, nil)
+
+	var req struct {
+		Name string `xml:"name"`
+		Age  int    `xml:"age"`
+	}
+	require.NoError(t, c.ReadXML(&req), "read XML")
+	assert.Equal(t, "brett", req.Name)
+	assert.Equal(t, 100, req.Age)
+}
+
+func TestReadXMLReturnsValidationErrorIfXMLIsInvalid(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(`<user>`))
+
+	c := NewContext(r, nil, nil)
+
+	var req struct{}
+	err := c.ReadXML(&req)
+	assert.IsType(t, &ValidationError{}, err)
+}
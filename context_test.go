@@ -2,6 +2,7 @@ package boar
 
 import (
 	"bytes"
+	"encoding/xml"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -183,3 +184,138 @@ func TestReadQueryShouldReturnValidationErrorIfQueryTypesMismatch(t *testing.T)
 	err = c.ReadQuery(&fields)
 	require.IsType(t, &ValidationError{}, err)
 }
+
+func TestRenderUsesNegotiatedEncoder(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("accept", "text/plain")
+	c := newContext(r, w, nil)
+
+	require.NoError(t, c.Render(http.StatusTeapot, "hello"))
+	require.NoError(t, c.Response().Flush())
+
+	assert.Equal(t, http.StatusTeapot, w.Code)
+	assert.Equal(t, "text/plain; charset=utf-8", w.Header().Get("content-type"))
+	assert.Equal(t, "Accept", w.Header().Get("vary"))
+	assert.Equal(t, "hello\n", w.Body.String())
+}
+
+func TestRenderReturnsNotAcceptable(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("accept", "image/png")
+	c := newContext(r, w, nil)
+
+	err := c.Render(http.StatusOK, JSON{})
+	assert.Equal(t, ErrNotAcceptable, err)
+	assert.Equal(t, 0, c.Response().Len())
+}
+
+func TestRenderDoesNotWriteWhenEncodeFails(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("accept", "text/csv")
+	c := newContext(r, w, nil)
+
+	err := c.Render(http.StatusOK, JSON{})
+	require.Error(t, err)
+	assert.Equal(t, 0, c.Response().Status())
+	assert.Equal(t, 0, c.Response().Len())
+}
+
+func TestRenderUsesRegisteredEncoders(t *testing.T) {
+	rtr := NewRouter()
+	rtr.RegisterEncoder("application/vnd.boar", func(w io.Writer, v interface{}) error {
+		_, err := io.WriteString(w, "boar")
+		return err
+	})
+	rtr.MethodFunc(http.MethodGet, "/", func(c Context) error {
+		return c.Render(http.StatusOK, JSON{})
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("accept", "application/vnd.boar")
+	rtr.ServeHTTP(w, r)
+
+	assert.Equal(t, "application/vnd.boar", w.Header().Get("content-type"))
+	assert.Equal(t, "boar", w.Body.String())
+}
+
+func TestErrorHandlerNegotiatesErrorFormat(t *testing.T) {
+	rtr := NewRouter()
+	rtr.MethodFunc(http.MethodGet, "/", func(c Context) error {
+		return ErrForbidden
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("accept", "text/plain")
+	rtr.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, "text/plain; charset=utf-8", w.Header().Get("content-type"))
+	assert.Equal(t, "Forbidden\n", w.Body.String())
+}
+
+func TestErrorHandlerFallsBackToJSONWhenNotAcceptable(t *testing.T) {
+	rtr := NewRouter()
+	rtr.MethodFunc(http.MethodGet, "/", func(c Context) error {
+		return ErrForbidden
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("accept", "image/png")
+	rtr.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, contentTypeJSON, w.Header().Get("content-type"))
+}
+
+func TestErrorHandlerFallsBackToJSONWhenEncoderFails(t *testing.T) {
+	rtr := NewRouter()
+	rtr.MethodFunc(http.MethodGet, "/", func(c Context) error {
+		return ErrForbidden
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("accept", "text/csv")
+	rtr.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, contentTypeJSON, w.Header().Get("content-type"))
+	assert.Contains(t, w.Body.String(), "Forbidden")
+}
+
+func TestWriteXMLSetsContentTypeAndStatus(t *testing.T) {
+	w := httptest.NewRecorder()
+	c := newContext(nil, w, nil)
+
+	type user struct {
+		Name string `xml:"name"`
+	}
+	require.NoError(t, c.WriteXML(http.StatusCreated, user{Name: "brett"}))
+	require.NoError(t, c.Response().Flush())
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, contentTypeXML, w.Header().Get("content-type"))
+	assert.Equal(t, xml.Header+"<user><name>brett</name></user>", w.Body.String())
+}
+
+func TestErrorHandlerWritesXMLWhenAccepted(t *testing.T) {
+	rtr := NewRouter()
+	rtr.MethodFunc(http.MethodGet, "/", func(c Context) error {
+		return ErrForbidden
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("accept", "application/xml")
+	rtr.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, contentTypeXML, w.Header().Get("content-type"))
+	assert.Equal(t, xml.Header+"<error>Forbidden</error>", w.Body.String())
+}
@@ -0,0 +1,227 @@
+package boar
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/blockloop/boar/bind"
+)
+
+// DecodeOptions configure how request bodies are read. They can be set for every route
+// with Router.DecodeOptions or for a single route with the WithDecodeOptions RouteOption
+type DecodeOptions struct {
+	// DisallowUnknownFields causes JSON bodies with fields that do not exist in the
+	// destination struct to fail validation
+	DisallowUnknownFields bool
+
+	// UseNumber decodes JSON numbers into interface{} values as json.Number instead
+	// of float64
+	UseNumber bool
+
+	// DisallowTrailingData causes JSON bodies with anything other than whitespace
+	// after the first JSON value to fail validation
+	DisallowTrailingData bool
+
+	// MaxBytes is the maximum size of the request body. Requests with larger bodies
+	// are rejected with ErrRequestEntityTooLarge. Zero means there is no limit
+	MaxBytes int64
+}
+
+// JSONError describes where a JSON request body failed to decode
+type JSONError struct {
+	// Path is the dotted path to the field that failed to decode (e.g. user.age). It is
+	// empty when the error is not related to a specific field, such as a syntax error
+	Path string
+	// Offset is the byte offset in the body at which the error occurred
+	Offset int64
+	// Err is the underlying error returned by encoding/json
+	Err error
+}
+
+func (e *JSONError) Error() string {
+	if e.Path != "" {
+		return fmt.Sprintf("failed to parse JSON body at offset %d (%s): %v", e.Offset, e.Path, e.Err)
+	}
+	return fmt.Sprintf("failed to parse JSON body at offset %d: %v", e.Offset, e.Err)
+}
+
+var errTrailingData = errors.New("unexpected data after JSON value")
+
+// decodeJSONBody decodes a single JSON value from body into v according to opts
+func decodeJSONBody(body io.Reader, v interface{}, opts DecodeOptions) error {
+	dec := json.NewDecoder(body)
+	if opts.DisallowUnknownFields {
+		dec.DisallowUnknownFields()
+	}
+	if opts.UseNumber {
+		dec.UseNumber()
+	}
+
+	if err := dec.Decode(v); err != nil {
+		return jsonError(dec, err)
+	}
+
+	if opts.DisallowTrailingData {
+		offset := dec.InputOffset()
+		if _, err := dec.Token(); err != io.EOF {
+			if tooLarge := requestTooLarge(err); tooLarge != nil {
+				return tooLarge
+			}
+			return &JSONError{Offset: offset, Err: errTrailingData}
+		}
+	}
+	return nil
+}
+
+func jsonError(dec *json.Decoder, err error) error {
+	if tooLarge := requestTooLarge(err); tooLarge != nil {
+		return tooLarge
+	}
+
+	switch e := err.(type) {
+	case *json.SyntaxError:
+		return &JSONError{Offset: e.Offset, Err: err}
+	case *json.UnmarshalTypeError:
+		return &JSONError{Path: e.Field, Offset: e.Offset, Err: err}
+	}
+
+	const unknownField = "json: unknown field "
+	if msg := err.Error(); strings.HasPrefix(msg, unknownField) {
+		field, _ := strconv.Unquote(strings.TrimPrefix(msg, unknownField))
+		return &JSONError{Path: field, Offset: dec.InputOffset(), Err: err}
+	}
+	return &JSONError{Offset: dec.InputOffset(), Err: err}
+}
+
+// requestTooLarge returns ErrRequestEntityTooLarge if err was caused by reading more
+// than DecodeOptions.MaxBytes from the request body
+func requestTooLarge(err error) error {
+	var mbe *http.MaxBytesError
+	if errors.As(err, &mbe) {
+		return ErrRequestEntityTooLarge
+	}
+	return nil
+}
+
+// DecoderFunc decodes the body of the request into v. Decoders are registered with
+// Router.RegisterDecoder and selected by the content-type of the request
+type DecoderFunc func(c Context, v interface{}) error
+
+func defaultDecoders() map[string]DecoderFunc {
+	return map[string]DecoderFunc{
+		contentTypeJSON:          decodeJSON,
+		contentTypeFormEncoded:   decodeForm,
+		contentTypeMultipartForm: decodeMultipartForm,
+		contentTypeXML:           decodeXML,
+		contentTypeTextXML:       decodeXML,
+		contentTypeMergePatch:    decodeMergePatch,
+		contentTypeJSONPatch:     decodeJSONPatch,
+	}
+}
+
+func decodeXML(c Context, v interface{}) error {
+	return c.ReadXML(v)
+}
+
+func decodeJSON(c Context, v interface{}) error {
+	return c.ReadJSON(v)
+}
+
+func decodeForm(c Context, v interface{}) error {
+	if err := c.Request().ParseForm(); err != nil {
+		if tooLarge := requestTooLarge(err); tooLarge != nil {
+			return tooLarge
+		}
+		return NewHTTPError(http.StatusBadRequest, err)
+	}
+	return c.ReadForm(v)
+}
+
+func decodeMultipartForm(c Context, v interface{}) error {
+	if err := c.Request().ParseMultipartForm(MultiPartFormMaxMemory); err != nil {
+		if tooLarge := requestTooLarge(err); tooLarge != nil {
+			return tooLarge
+		}
+		return NewHTTPError(http.StatusBadRequest, err)
+	}
+	if err := c.ReadForm(v); err != nil {
+		return err
+	}
+	return bind.Files(v, c.Request().MultipartForm.File)
+}
+
+// findDecoder returns the decoder registered for the content-type of r. The exact
+// media type is preferred over the generic type of a structured syntax suffix so that
+// a decoder for application/merge-patch+json takes precedence over application/json.
+// If consumes is not empty then the media type must be one of the listed types
+func findDecoder(r *http.Request, decoders map[string]DecoderFunc, consumes []string) (DecoderFunc, error) {
+	ct := r.Header.Get("content-type")
+	if ct == "" {
+		return nil, NewHTTPError(http.StatusBadRequest, errNoContentType)
+	}
+
+	mt, err := parseMediaType(ct)
+	if err != nil {
+		return nil, NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	if len(consumes) > 0 && !mt.matches(consumes) {
+		return nil, unsupportedMediaType(ct)
+	}
+
+	decode, ok := decoders[mt.Type]
+	if !ok {
+		decode, ok = decoders[mt.Base]
+	}
+	if !ok {
+		return nil, unsupportedMediaType(ct)
+	}
+
+	if err := transcodeBody(r, mt); err != nil {
+		return nil, err
+	}
+	return decode, nil
+}
+
+// matches reports whether the media type, or its generic type, is one of mediaTypes
+func (m *mediaType) matches(mediaTypes []string) bool {
+	for _, typ := range mediaTypes {
+		typ = strings.ToLower(typ)
+		if typ == m.Type || typ == m.Base {
+			return true
+		}
+	}
+	return false
+}
+
+// transcodeBody replaces the request body with a reader that converts the charset
+// declared in the content-type into UTF-8
+func transcodeBody(r *http.Request, mt *mediaType) error {
+	if r.Body == nil {
+		return nil
+	}
+	body, err := charsetReader(mt.Charset(), r.Body)
+	if err != nil {
+		return NewHTTPError(http.StatusUnsupportedMediaType,
+			fmt.Errorf("unsupported charset: %q", mt.Charset()))
+	}
+	if body != r.Body {
+		r.Body = transcodedBody{Reader: body, Closer: r.Body}
+	}
+	return nil
+}
+
+func unsupportedMediaType(ct string) error {
+	return NewHTTPError(http.StatusUnsupportedMediaType, fmt.Errorf("unsupported content type: %q", ct))
+}
+
+// transcodedBody is a request body which transcodeBody converted into UTF-8
+type transcodedBody struct {
+	io.Reader
+	io.Closer
+}
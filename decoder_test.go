@@ -0,0 +1,306 @@
+package boar
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type csvBodyHandler struct {
+	handle HandlerFunc
+	Body   struct {
+		Names []string
+	}
+}
+
+func (h *csvBodyHandler) Handle(c Context) error { return h.handle(c) }
+
+func decodeCSVNames(c Context, v interface{}) error {
+	records, err := csv.NewReader(c.Request().Body).ReadAll()
+	if err != nil {
+		return err
+	}
+	body := v.(*struct{ Names []string })
+	for _, rec := range records {
+		body.Names = append(body.Names, rec[0])
+	}
+	return nil
+}
+
+func TestRegisterDecoderIsUsedForBody(t *testing.T) {
+	r := NewRouter()
+	r.RegisterDecoder("text/csv", decodeCSVNames)
+
+	h := &csvBodyHandler{}
+	h.handle = func(c Context) error {
+		return c.WriteJSON(http.StatusOK, h.Body)
+	}
+	r.Post("/", func(Context) (Handler, error) {
+		return h, nil
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString("brett\nkristy\n"))
+	req.Header.Set("content-type", "text/csv; charset=utf-8")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, []string{"brett", "kristy"}, h.Body.Names)
+}
+
+func TestRegisterDecoderPanicsWhenNil(t *testing.T) {
+	r := NewRouter()
+	log.SetOutput(ioutil.Discard)
+	defer log.SetOutput(os.Stderr)
+	assert.Panics(t, func() {
+		r.RegisterDecoder("text/csv", nil)
+	})
+}
+
+func TestRegisterDecoderPanicsForInvalidMediaTypes(t *testing.T) {
+	r := NewRouter()
+	log.SetOutput(ioutil.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	for _, mt := range []string{"", "JSON", "text/", "/csv", "application/*", "*/*", "application/json; charset=utf-8"} {
+		assert.Panics(t, func() {
+			r.RegisterDecoder(mt, decodeCSVNames)
+		}, mt)
+	}
+}
+
+func TestRegisterDecoderNormalizesMediaType(t *testing.T) {
+	r := NewRouter()
+
+	var called bool
+	r.RegisterDecoder("Text/CSV", func(c Context, v interface{}) error {
+		called = true
+		return nil
+	})
+	r.MethodFunc(http.MethodPost, "/", func(c Context) error {
+		var v JSON
+		return c.Bind(&v)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString("a,b"))
+	req.Header.Set("content-type", "text/csv; charset=utf-8")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	assert.True(t, called)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestRegisterDecoderPrefersExactMediaTypeOverSuffix(t *testing.T) {
+	r := NewRouter()
+
+	var called bool
+	r.RegisterDecoder("application/vnd.api+json", func(c Context, v interface{}) error {
+		called = true
+		return nil
+	})
+	r.MethodFunc(http.MethodPost, "/", func(c Context) error {
+		var v JSON
+		return c.Bind(&v)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString("{}"))
+	req.Header.Set("content-type", "application/vnd.api+json")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.True(t, called)
+}
+
+func TestBindDecodesJSON(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(`{"name": "brett"}`))
+	req.Header.Set("content-type", contentTypeJSON)
+	c := NewContext(req, nil, nil)
+
+	var v struct {
+		Name string `json:"name"`
+	}
+	require.NoError(t, c.Bind(&v))
+	assert.Equal(t, "brett", v.Name)
+}
+
+func TestBindWrapsDecoderErrorsInValidationError(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString("a,b\nc"))
+	req.Header.Set("content-type", "text/csv")
+	c := newContext(req, nil, nil)
+	c.decoders["text/csv"] = decodeCSVNames
+
+	var v struct{ Names []string }
+	err := c.Bind(&v)
+	assert.IsType(t, &ValidationError{}, err)
+}
+
+func TestConsumesRejectsOtherContentTypes(t *testing.T) {
+	r := NewRouter()
+	r.MethodFunc(http.MethodPost, "/", func(c Context) error {
+		var v JSON
+		return c.Bind(&v)
+	}, Consumes(contentTypeJSON))
+
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString("name=brett"))
+	req.Header.Set("content-type", contentTypeFormEncoded)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	rec.Flush()
+
+	body, err := ioutil.ReadAll(rec.Result().Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+	assert.Contains(t, string(body), contentTypeFormEncoded)
+}
+
+func TestConsumesAcceptsSuffixOfListedType(t *testing.T) {
+	r := NewRouter()
+	r.MethodFunc(http.MethodPost, "/", func(c Context) error {
+		var v JSON
+		if err := c.Bind(&v); err != nil {
+			return err
+		}
+		return c.WriteStatus(http.StatusNoContent)
+	}, Consumes(contentTypeJSON))
+
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString("{}"))
+	req.Header.Set("content-type", "application/merge-patch+json")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+}
+
+func TestReadJSONDisallowUnknownFieldsReportsField(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(`{"name": "brett", "age": 1}`))
+	c := newContext(req, nil, nil)
+	c.decodeOptions = DecodeOptions{DisallowUnknownFields: true}
+
+	var v struct {
+		Name string `json:"name"`
+	}
+	err := c.ReadJSON(&v)
+	require.IsType(t, &ValidationError{}, err)
+
+	jerr, ok := err.(*ValidationError).Errors[0].(*JSONError)
+	require.True(t, ok)
+	assert.Equal(t, "age", jerr.Path)
+}
+
+func TestReadJSONUseNumber(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(`{"id": 12345678901234567890}`))
+	c := newContext(req, nil, nil)
+	c.decodeOptions = DecodeOptions{UseNumber: true}
+
+	var v JSON
+	require.NoError(t, c.ReadJSON(&v))
+	assert.Equal(t, json.Number("12345678901234567890"), v["id"])
+}
+
+func TestReadJSONDisallowTrailingData(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(`{"name": "brett"} {"name": "kristy"}`))
+	c := newContext(req, nil, nil)
+	c.decodeOptions = DecodeOptions{DisallowTrailingData: true}
+
+	var v JSON
+	err := c.ReadJSON(&v)
+	require.IsType(t, &ValidationError{}, err)
+
+	jerr := err.(*ValidationError).Errors[0].(*JSONError)
+	assert.Equal(t, errTrailingData, jerr.Err)
+	assert.EqualValues(t, 17, jerr.Offset)
+}
+
+func TestReadJSONAllowsTrailingWhitespace(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString("{}\n\n"))
+	c := newContext(req, nil, nil)
+	c.decodeOptions = DecodeOptions{DisallowTrailingData: true}
+
+	var v JSON
+	assert.NoError(t, c.ReadJSON(&v))
+}
+
+func TestReadJSONReportsPathAndOffsetOfTypeErrors(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(`{"user": {"age": "old"}}`))
+	c := newContext(req, nil, nil)
+
+	var v struct {
+		User struct {
+			Age int `json:"age"`
+		} `json:"user"`
+	}
+	err := c.ReadJSON(&v)
+	require.IsType(t, &ValidationError{}, err)
+
+	jerr := err.(*ValidationError).Errors[0].(*JSONError)
+	assert.Equal(t, "user.age", jerr.Path)
+	assert.EqualValues(t, 22, jerr.Offset)
+	assert.Contains(t, err.Error(), "user.age")
+}
+
+func TestMaxBytesReturnsRequestEntityTooLarge(t *testing.T) {
+	r := NewRouter()
+	r.DecodeOptions = DecodeOptions{MaxBytes: 8}
+
+	h := &bodyHandler{handle: func(Context) error {
+		t.Fatal("handle called unexpectedly")
+		return nil
+	}}
+	r.Post("/", func(Context) (Handler, error) {
+		return h, nil
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(`{"Age": 1000000}`))
+	req.Header.Set("content-type", contentTypeJSON)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+}
+
+func TestMaxBytesAppliesToForms(t *testing.T) {
+	r := NewRouter()
+	r.DecodeOptions = DecodeOptions{MaxBytes: 4}
+	r.MethodFunc(http.MethodPost, "/", func(c Context) error {
+		var v struct{ Name string }
+		return c.Bind(&v)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(`Name=brett`))
+	req.Header.Set("content-type", contentTypeFormEncoded)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+}
+
+func TestWithDecodeOptionsOverridesRouterOptions(t *testing.T) {
+	r := NewRouter()
+	r.DecodeOptions = DecodeOptions{MaxBytes: 4}
+
+	h := &bodyHandler{}
+	h.handle = func(c Context) error {
+		return c.WriteStatus(http.StatusNoContent)
+	}
+	r.Post("/", func(Context) (Handler, error) {
+		return h, nil
+	}, WithDecodeOptions(DecodeOptions{DisallowUnknownFields: true}))
+
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(`{"Age": 1000000}`))
+	req.Header.Set("content-type", contentTypeJSON)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, 1000000, h.Body.Age)
+}
@@ -0,0 +1,240 @@
+package boar
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	contentTypeXML       = "application/xml"
+	contentTypeTextXML   = "text/xml"
+	contentTypeTextPlain = "text/plain"
+	contentTypeCSV       = "text/csv"
+)
+
+// EncoderFunc encodes v into w. Encoders are registered with Router.RegisterEncoder and
+// selected for a response by negotiating with the Accept header of the request
+type EncoderFunc func(w io.Writer, v interface{}) error
+
+type encoder struct {
+	mediaType string
+	encode    EncoderFunc
+}
+
+// contentType is the value of the content-type header for responses written with
+// this encoder
+func (e encoder) contentType() string {
+	if strings.HasPrefix(e.mediaType, "text/") {
+		return e.mediaType + "; charset=utf-8"
+	}
+	return e.mediaType
+}
+
+func defaultEncoders() []encoder {
+	return []encoder{
+		{mediaType: contentTypeJSON, encode: encodeJSON},
+		{mediaType: contentTypeXML, encode: encodeXML},
+		{mediaType: contentTypeTextXML, encode: encodeXML},
+		{mediaType: contentTypeTextPlain, encode: encodeText},
+		{mediaType: contentTypeCSV, encode: encodeCSV},
+	}
+}
+
+func encodeJSON(w io.Writer, v interface{}) error {
+	return json.NewEncoder(w).Encode(v)
+}
+
+func encodeXML(w io.Writer, v interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	return xml.NewEncoder(w).Encode(v)
+}
+
+func encodeText(w io.Writer, v interface{}) error {
+	if httperr, ok := v.(HTTPError); ok {
+		v = httperr.Cause()
+	}
+	_, err := fmt.Fprintln(w, v)
+	return err
+}
+
+// addVary adds value to the Vary header unless it is already listed
+func addVary(h http.Header, value string) {
+	for _, v := range h["Vary"] {
+		for _, field := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(field), value) {
+				return
+			}
+		}
+	}
+	h.Add("Vary", value)
+}
+
+// encodeCSV writes v as CSV. v must be a [][]string, a []string for a single record,
+// or a slice of structs. Slices of structs are written with a header record of the field
+// names, or the value of the csv tag if one exists
+func encodeCSV(w io.Writer, v interface{}) error {
+	cw := csv.NewWriter(w)
+	switch records := v.(type) {
+	case [][]string:
+		return cw.WriteAll(records)
+	case []string:
+		if err := cw.Write(records); err != nil {
+			return err
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+
+	val := reflect.Indirect(reflect.ValueOf(v))
+	if val.Kind() != reflect.Slice {
+		return fmt.Errorf("cannot encode %T as CSV", v)
+	}
+	typ := val.Type().Elem()
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return fmt.Errorf("cannot encode %T as CSV", v)
+	}
+
+	fields := make([]int, 0, typ.NumField())
+	header := make([]string, 0, typ.NumField())
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		name := f.Name
+		if tag, ok := f.Tag.Lookup("csv"); ok {
+			name = tag
+		}
+		if f.PkgPath != "" || name == "-" {
+			continue
+		}
+		fields = append(fields, i)
+		header = append(header, name)
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	record := make([]string, len(fields))
+	for i := 0; i < val.Len(); i++ {
+		item := reflect.Indirect(val.Index(i))
+		for j, f := range fields {
+			record[j] = ""
+			if item.IsValid() {
+				record[j] = fmt.Sprint(item.Field(f).Interface())
+			}
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// mediaRange is a single media range of an Accept header
+type mediaRange struct {
+	typ     string
+	subtype string
+	q       float64
+}
+
+// parseAccept parses the value of an Accept header into media ranges. Malformed
+// ranges are ignored
+func parseAccept(accept string) []mediaRange {
+	ranges := make([]mediaRange, 0, 4)
+	for _, part := range strings.Split(accept, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		mt, params, err := mime.ParseMediaType(part)
+		if err != nil {
+			continue
+		}
+		slash := strings.IndexByte(mt, '/')
+		if slash < 0 {
+			continue
+		}
+
+		q := 1.0
+		if qs, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(qs, 64); err != nil {
+				continue
+			}
+		}
+		ranges = append(ranges, mediaRange{
+			typ:     mt[:slash],
+			subtype: mt[slash+1:],
+			q:       q,
+		})
+	}
+	return ranges
+}
+
+// quality returns the q-value of the most specific media range in ranges that matches
+// mediaType, or -1 if no range matches
+func quality(ranges []mediaRange, mediaType string) float64 {
+	slash := strings.IndexByte(mediaType, '/')
+	typ, subtype := mediaType[:slash], mediaType[slash+1:]
+
+	q, specificity := -1.0, -1
+	for _, r := range ranges {
+		var s int
+		switch {
+		case r.typ == typ && r.subtype == subtype:
+			s = 2
+		case r.typ == typ && r.subtype == "*":
+			s = 1
+		case r.typ == "*" && r.subtype == "*":
+			s = 0
+		default:
+			continue
+		}
+		if s > specificity {
+			q, specificity = r.q, s
+		}
+	}
+	return q
+}
+
+// negotiate returns the encoder that best satisfies the Accept header. When multiple
+// encoders are equally acceptable the one registered first is preferred. false is
+// returned when none of the encoders are acceptable
+func negotiate(accept string, encoders []encoder) (encoder, bool) {
+	if strings.TrimSpace(accept) == "" {
+		if len(encoders) == 0 {
+			return encoder{}, false
+		}
+		return encoders[0], true
+	}
+
+	ranges := parseAccept(accept)
+	candidates := make([]encoder, 0, len(encoders))
+	qualities := make(map[string]float64, len(encoders))
+	for _, enc := range encoders {
+		if q := quality(ranges, enc.mediaType); q > 0 {
+			candidates = append(candidates, enc)
+			qualities[enc.mediaType] = q
+		}
+	}
+	if len(candidates) == 0 {
+		return encoder{}, false
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return qualities[candidates[i].mediaType] > qualities[candidates[j].mediaType]
+	})
+	return candidates[0], true
+}
@@ -0,0 +1,105 @@
+package boar
+
+import (
+	"bytes"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiateUsesFirstEncoderWhenNoAccept(t *testing.T) {
+	enc, ok := negotiate("", defaultEncoders())
+	require.True(t, ok)
+	assert.Equal(t, contentTypeJSON, enc.mediaType)
+}
+
+func TestNegotiateUsesFirstEncoderWhenAcceptAll(t *testing.T) {
+	enc, ok := negotiate("*/*", defaultEncoders())
+	require.True(t, ok)
+	assert.Equal(t, contentTypeJSON, enc.mediaType)
+}
+
+func TestNegotiatePrefersHighestQuality(t *testing.T) {
+	enc, ok := negotiate("application/json;q=0.5, text/csv;q=0.9, */*;q=0.1", defaultEncoders())
+	require.True(t, ok)
+	assert.Equal(t, contentTypeCSV, enc.mediaType)
+}
+
+func TestNegotiatePrefersMostSpecificRange(t *testing.T) {
+	enc, ok := negotiate("text/*;q=0.8, text/plain;q=0, text/xml;q=0.1, application/xml;q=0.5", defaultEncoders())
+	require.True(t, ok)
+	assert.Equal(t, contentTypeCSV, enc.mediaType)
+}
+
+func TestNegotiateReturnsFalseWhenNothingMatches(t *testing.T) {
+	_, ok := negotiate("image/png", defaultEncoders())
+	assert.False(t, ok)
+}
+
+func TestNegotiateIgnoresMalformedRanges(t *testing.T) {
+	enc, ok := negotiate("garbage, text/plain;q=abc, application/xml", defaultEncoders())
+	require.True(t, ok)
+	assert.Equal(t, contentTypeXML, enc.mediaType)
+}
+
+func TestEncodeCSVWritesRecords(t *testing.T) {
+	buf := &bytes.Buffer{}
+	require.NoError(t, encodeCSV(buf, [][]string{{"a", "b"}, {"c", "d"}}))
+	assert.Equal(t, "a,b\nc,d\n", buf.String())
+}
+
+func TestEncodeCSVWritesStructsWithHeader(t *testing.T) {
+	type user struct {
+		Name   string `csv:"name"`
+		Age    int
+		Secret string `csv:"-"`
+	}
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, encodeCSV(buf, []*user{{Name: "brett", Age: 30}, nil}))
+	assert.Equal(t, "name,Age\nbrett,30\n,\n", buf.String())
+}
+
+func TestEncodeCSVErrorsForUnsupportedTypes(t *testing.T) {
+	err := encodeCSV(&bytes.Buffer{}, JSON{})
+	assert.Error(t, err)
+}
+
+func TestEncodeTextWritesHTTPErrorCause(t *testing.T) {
+	buf := &bytes.Buffer{}
+	require.NoError(t, encodeText(buf, ErrNotFound))
+	assert.Equal(t, "Not Found\n", buf.String())
+}
+
+func TestAddVaryDoesNotDuplicate(t *testing.T) {
+	h := http.Header{}
+	h.Set("Vary", "Origin, accept")
+	addVary(h, "Accept")
+	assert.Equal(t, []string{"Origin, accept"}, h["Vary"])
+}
+
+func TestRegisterEncoderPanicsForInvalidMediaTypes(t *testing.T) {
+	r := NewRouter()
+	log.SetOutput(ioutil.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	for _, mt := range []string{"", "csv", "text/", "/csv", "text/*", "*/*", "text/csv; charset=utf-8"} {
+		assert.Panics(t, func() {
+			r.RegisterEncoder(mt, encodeCSV)
+		}, mt)
+	}
+}
+
+func TestRegisterEncoderNormalizesMediaType(t *testing.T) {
+	r := NewRouter()
+	r.RegisterEncoder("Application/Vnd.Boar", encodeJSON)
+
+	enc, ok := negotiate("application/vnd.boar", r.encoders)
+	require.True(t, ok)
+	assert.Equal(t, "application/vnd.boar", enc.mediaType)
+}
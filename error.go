@@ -2,6 +2,7 @@ package boar
 
 import (
 	"encoding/json"
+	"encoding/xml"
 	"errors"
 	"fmt"
 	"net/http"
@@ -18,9 +19,19 @@ var (
 	// ErrNotFound is an HTTPError for StatusNotFound
 	ErrNotFound = NewHTTPErrorStatus(http.StatusNotFound)
 
+	// ErrMethodNotAllowed is an HTTPError for StatusMethodNotAllowed
+	ErrMethodNotAllowed = NewHTTPErrorStatus(http.StatusMethodNotAllowed)
+
 	// ErrNotAcceptable is an HTTPError for StatusNotAcceptable
 	ErrNotAcceptable = NewHTTPErrorStatus(http.StatusNotAcceptable)
 
+	// ErrRequestEntityTooLarge is an HTTPError for StatusRequestEntityTooLarge
+	ErrRequestEntityTooLarge = NewHTTPErrorStatus(http.StatusRequestEntityTooLarge)
+
+	// ErrResponseTooLarge is an HTTPError for StatusInsufficientStorage. It is returned
+	// when writing a buffered response larger than BufferOptions.MaxSize
+	ErrResponseTooLarge = NewHTTPErrorStatus(http.StatusInsufficientStorage)
+
 	// ErrUnsupportedMediaType is an HTTPError for StatusUnsupportedMediaType
 	ErrUnsupportedMediaType = NewHTTPErrorStatus(http.StatusUnsupportedMediaType)
 
@@ -56,7 +67,7 @@ type httpError struct {
 // uses the default status text for that status code. These are useful for concise
 // errors such as "Forbidden" or "Unauthorized"
 func NewHTTPErrorStatus(status int) error {
-	return NewHTTPError(status, fmt.Errorf(http.StatusText(status)))
+	return NewHTTPError(status, errors.New(http.StatusText(status)))
 }
 
 // NewHTTPError creates a new HTTPError that will be marshaled to the requestor
@@ -87,6 +98,11 @@ func (h *httpError) MarshalJSON() ([]byte, error) {
 	})
 }
 
+// MarshalXML marshals this error to XML as <error>cause</error>
+func (h *httpError) MarshalXML(e *xml.Encoder, _ xml.StartElement) error {
+	return marshalXMLError(e, h.cause)
+}
+
 // ValidationError is an HTTPError that was caused by validation. Validation
 // errors are typically caused by valid tags or improper type mapping between
 // input types and struct fields. These should always be considered 400 errors.
@@ -166,6 +182,32 @@ func (e *ValidationError) MarshalJSON() ([]byte, error) {
 	})
 }
 
+// MarshalXML marshals the validation errors to XML. Each error is an element named
+// after the field which failed validation, or an error element when there is no field
+//
+// Example:
+//    <errors>
+//        <body>Name is required</body>
+//        <body>Age must be a number</body>
+//    </errors>
+func (e *ValidationError) MarshalXML(enc *xml.Encoder, _ xml.StartElement) error {
+	start := xml.StartElement{Name: xml.Name{Local: "errors"}}
+	name := strings.ToLower(e.fieldName)
+	if name == "" {
+		name = "error"
+	}
+	field := xml.StartElement{Name: xml.Name{Local: name}}
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+	for _, err := range e.Errors {
+		if err := enc.EncodeElement(err.Error(), field); err != nil {
+			return err
+		}
+	}
+	return enc.EncodeToken(start.End())
+}
+
 var _ HTTPError = (*PanicError)(nil)
 
 // PanicError is an error caused by panic that was recovered
@@ -204,3 +246,12 @@ func (p *PanicError) MarshalJSON() ([]byte, error) {
 		"error": p.Cause().Error(),
 	})
 }
+
+// MarshalXML marshals this error to XML as <error>cause</error>
+func (p *PanicError) MarshalXML(e *xml.Encoder, _ xml.StartElement) error {
+	return marshalXMLError(e, p.Cause())
+}
+
+func marshalXMLError(e *xml.Encoder, cause error) error {
+	return e.EncodeElement(cause.Error(), xml.StartElement{Name: xml.Name{Local: "error"}})
+}
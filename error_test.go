@@ -2,6 +2,7 @@ package boar
 
 import (
 	"encoding/json"
+	"encoding/xml"
 	"io"
 	"os"
 	"testing"
@@ -80,3 +81,50 @@ func TestValidationErrorCauseShowsAllCauses(t *testing.T) {
 		assert.Contains(t, err, er.Error())
 	}
 }
+
+func TestHTTPErrorMarshalXMLCreatesErrorElementWithCause(t *testing.T) {
+	e := &httpError{
+		status: 500,
+		cause:  io.ErrClosedPipe,
+	}
+
+	byts, err := xml.Marshal(e)
+	require.NoError(t, err)
+	assert.Equal(t, "<error>"+io.ErrClosedPipe.Error()+"</error>", string(byts))
+}
+
+func TestValidationErrorMarshalXMLCreatesElementPerCause(t *testing.T) {
+	e := &ValidationError{
+		status:    400,
+		fieldName: "Body",
+		Errors:    []error{io.ErrClosedPipe, os.ErrInvalid},
+	}
+
+	byts, err := xml.Marshal(e)
+	require.NoError(t, err)
+
+	var ermsg struct {
+		XMLName xml.Name `xml:"errors"`
+		Body    []string `xml:"body"`
+	}
+	require.NoError(t, xml.Unmarshal(byts, &ermsg))
+
+	assert.Equal(t, []string{io.ErrClosedPipe.Error(), os.ErrInvalid.Error()}, ermsg.Body)
+}
+
+func TestValidationErrorMarshalXMLWithoutFieldName(t *testing.T) {
+	e := NewValidationError("", io.ErrClosedPipe)
+
+	byts, err := xml.Marshal(e)
+	require.NoError(t, err)
+	assert.Equal(t, "<errors><error>"+io.ErrClosedPipe.Error()+"</error></errors>", string(byts))
+	require.NoError(t, xml.Unmarshal(byts, new(struct{})))
+}
+
+func TestPanicErrorMarshalXMLDoesNotIncludeStack(t *testing.T) {
+	e := NewPanicError("something broke", []byte("goroutine 1"))
+
+	byts, err := xml.Marshal(e)
+	require.NoError(t, err)
+	assert.Equal(t, "<error>something broke</error>", string(byts))
+}
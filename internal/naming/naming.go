@@ -0,0 +1,74 @@
+// Package naming converts route names into the names of generated Go and TypeScript methods
+// so that clientgen and tsgen name the methods of a route alike
+package naming
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/blockloop/boar"
+)
+
+// initialisms are written in upper case in exported names, as golint recommends
+var initialisms = map[string]bool{
+	"API": true, "HTML": true, "HTTP": true, "ID": true, "JSON": true, "URL": true, "UUID": true, "XML": true,
+}
+
+// Exported converts a route name such as user.orders or get-user_id into a name such as
+// UserOrders or GetUserID
+func Exported(s string) string {
+	parts := strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var b strings.Builder
+	for _, p := range parts {
+		if upper := strings.ToUpper(p); initialisms[upper] {
+			b.WriteString(upper)
+			continue
+		}
+		b.WriteString(strings.ToUpper(p[:1]) + p[1:])
+	}
+	name := b.String()
+	if name != "" && unicode.IsDigit(rune(name[0])) {
+		name = "R" + name
+	}
+	return name
+}
+
+// Methods returns the exported method name of each of routes, or an empty string for routes
+// without a name and handlers served with Mount. Routes which share a name are distinguished
+// by their method, such as GetUser and DeleteUser. generated converts an exported name into
+// the name of the generated method, which must be unique
+func Methods(routes []boar.RouteInfo, generated func(string) string) ([]string, error) {
+	named := make(map[string]int)
+	for _, ri := range routes {
+		if ri.Name != "" && !ri.Mount {
+			named[ri.Name]++
+		}
+	}
+
+	methods := make([]string, len(routes))
+	seen := make(map[string]string)
+	for i, ri := range routes {
+		if ri.Name == "" || ri.Mount {
+			continue
+		}
+		method := Exported(ri.Name)
+		// a name is shared by the routes of a resource, such as GET and DELETE /users/:id,
+		// which are generated as separate methods
+		if named[ri.Name] > 1 {
+			method = Exported(strings.ToLower(ri.Method)) + method
+		}
+		if method == "" {
+			return nil, fmt.Errorf("route name %q is not a valid method name", ri.Name)
+		}
+		name := generated(method)
+		if other, ok := seen[name]; ok {
+			return nil, fmt.Errorf("routes %q and %q both generate the method %s", other, ri.Name, name)
+		}
+		seen[name] = ri.Name
+		methods[i] = method
+	}
+	return methods, nil
+}
@@ -0,0 +1,49 @@
+package naming
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/blockloop/boar"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExported(t *testing.T) {
+	tests := map[string]string{
+		"user.orders":  "UserOrders",
+		"get-user_id":  "GetUserID",
+		"api.v2.users": "APIV2Users",
+		"2fa":          "R2fa",
+		"...":          "",
+	}
+	for in, expected := range tests {
+		assert.Equal(t, expected, Exported(in), in)
+	}
+}
+
+func TestMethods(t *testing.T) {
+	routes := []boar.RouteInfo{
+		{Method: http.MethodGet, Path: "/users/:id", Name: "user"},
+		{Method: http.MethodGet, Path: "/health"},
+		{Method: http.MethodDelete, Path: "/users/:id", Name: "user"},
+	}
+	methods, err := Methods(routes, func(s string) string { return s })
+	require.NoError(t, err)
+	assert.Equal(t, []string{"GetUser", "", "DeleteUser"}, methods)
+}
+
+func TestMethodsSkipsMounts(t *testing.T) {
+	routes := []boar.RouteInfo{
+		{Method: "*", Path: "/legacy/*mountpath", Name: "user", Mount: true},
+		{Method: http.MethodGet, Path: "/users/:id", Name: "user"},
+	}
+	methods, err := Methods(routes, func(s string) string { return s })
+	require.NoError(t, err)
+	assert.Equal(t, []string{"", "User"}, methods)
+}
+
+func TestMethodsRejectsInvalidNames(t *testing.T) {
+	_, err := Methods([]boar.RouteInfo{{Method: http.MethodGet, Path: "/", Name: "..."}}, func(s string) string { return s })
+	assert.EqualError(t, err, `route name "..." is not a valid method name`)
+}
@@ -0,0 +1,102 @@
+// Package pattern parses and fills the paths of boar routes so that Router.URL and the
+// generated clients build the same URLs
+package pattern
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Param is a :param or *catchall segment of a route path
+type Param struct {
+	// Name is the key of the parameter in the URLParams of the request
+	Name string
+	// CatchAll is set for *catchall parameters, which match the rest of the path
+	CatchAll bool
+}
+
+// String returns the segment as it is written in the path, such as :id or *filepath
+func (p Param) String() string {
+	if p.CatchAll {
+		return "*" + p.Name
+	}
+	return ":" + p.Name
+}
+
+// Params returns the parameters of the route path in order
+func Params(path string) []Param {
+	var params []Param
+	for {
+		i := strings.IndexAny(path, ":*")
+		if i < 0 {
+			return params
+		}
+		catchAll := path[i] == '*'
+		path = path[i+1:]
+		end := strings.IndexByte(path, '/')
+		if end < 0 {
+			end = len(path)
+		}
+		params = append(params, Param{Name: path[:end], CatchAll: catchAll})
+		path = path[end:]
+	}
+}
+
+// Build fills the parameters of the route path with params, which are key/value pairs.
+// Values are escaped, apart from the slashes of *catchall values which separate segments,
+// and pairs with keys that are not parameters of the path are added to the query string.
+// subject describes the route in errors, such as route "user"
+func Build(path, subject string, params []string) (string, error) {
+	if len(params)%2 != 0 {
+		return "", fmt.Errorf("odd number of params for %s", subject)
+	}
+	values := make(map[string]string, len(params)/2)
+	for i := 0; i < len(params); i += 2 {
+		values[params[i]] = params[i+1]
+	}
+
+	var b strings.Builder
+	used := make(map[string]bool)
+	rest := path
+	for _, param := range Params(path) {
+		i := strings.Index(rest, param.String())
+		b.WriteString(rest[:i])
+		rest = rest[i+len(param.String()):]
+
+		key := param.Name
+		v, ok := values[key]
+		if !ok {
+			return "", fmt.Errorf("missing param %q for %s", key, subject)
+		}
+		used[key] = true
+		if !param.CatchAll {
+			// routes are matched against the unescaped path, so the value would be split
+			if strings.Contains(v, "/") {
+				return "", fmt.Errorf("param %q for %s cannot contain a slash", key, subject)
+			}
+			b.WriteString(url.PathEscape(v))
+			continue
+		}
+		// catch-all values begin with a slash when they are read from httprouter.Params,
+		// but the slash is already part of the route path
+		segments := strings.Split(strings.TrimPrefix(v, "/"), "/")
+		for j, s := range segments {
+			segments[j] = url.PathEscape(s)
+		}
+		b.WriteString(strings.Join(segments, "/"))
+	}
+	b.WriteString(rest)
+
+	query := url.Values{}
+	for i := 0; i < len(params); i += 2 {
+		if !used[params[i]] {
+			query.Add(params[i], params[i+1])
+		}
+	}
+	if len(query) > 0 {
+		b.WriteString("?")
+		b.WriteString(query.Encode())
+	}
+	return b.String(), nil
+}
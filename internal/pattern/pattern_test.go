@@ -0,0 +1,30 @@
+package pattern
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParams(t *testing.T) {
+	assert.Equal(t, []Param{{Name: "id"}, {Name: "filepath", CatchAll: true}}, Params("/users/:id/files/*filepath"))
+	assert.Nil(t, Params("/health"))
+}
+
+func TestBuild(t *testing.T) {
+	p, err := Build("/users/:id/files/*path", "test", []string{"id", "a b", "path", "/x y/z.txt", "page", "2"})
+	require.NoError(t, err)
+	assert.Equal(t, "/users/a%20b/files/x%20y/z.txt?page=2", p)
+}
+
+func TestBuildErrors(t *testing.T) {
+	_, err := Build("/users/:id", `route "user"`, []string{"id"})
+	assert.EqualError(t, err, `odd number of params for route "user"`)
+
+	_, err = Build("/users/:id", `route "user"`, nil)
+	assert.EqualError(t, err, `missing param "id" for route "user"`)
+
+	_, err = Build("/users/:id", `route "user"`, []string{"id", "a/b"})
+	assert.EqualError(t, err, `param "id" for route "user" cannot contain a slash`)
+}
@@ -0,0 +1,194 @@
+package boar
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var (
+	contentTypeNDJSON = "application/x-ndjson"
+
+	errJSONStreamClosed = errors.New("json stream is closed")
+)
+
+// DefaultStreamFlushInterval is how often a JSONStream sends encoded values to the client
+const DefaultStreamFlushInterval = time.Second
+
+// streamErrorTrailer is the trailer set when a JSONStream is aborted by an error
+const streamErrorTrailer = "Stream-Error"
+
+// JSONStream encodes values to the client one at a time as they are produced. Values are
+// written as newline delimited JSON (application/x-ndjson) when the client prefers it over
+// application/json, and as the elements of a JSON array otherwise.
+//
+// When the handler returns an error after the stream has begun writing, the status can no
+// longer be changed. Instead the stream is aborted: the Stream-Error trailer is set to the error
+// message, NDJSON streams end with a final line containing the error as it would have been
+// serialized by the default ErrorHandler, and JSON arrays are left unterminated so that
+// clients fail to parse a partial result rather than mistaking it for a complete one
+type JSONStream struct {
+	// FlushInterval is the longest duration encoded values are held before being sent to
+	// the client. DefaultStreamFlushInterval is used when it is zero
+	FlushInterval time.Duration
+
+	m         sync.Mutex
+	w         ResponseWriter
+	ctx       context.Context
+	ndjson    bool
+	count     int
+	lastFlush time.Time
+	closed    bool
+	// written is the length of the response after the last write of the stream, so that
+	// writes made by others, such as the ErrorHandler, can be detected
+	written int
+}
+
+// newJSONStream negotiates the format of the stream, sends the headers and begins
+// streaming the response
+func newJSONStream(c Context, status int) (*JSONStream, error) {
+	h := c.Response().Header()
+	addVary(h, "Accept")
+
+	ranges := parseAccept(c.Request().Header.Get("accept"))
+	s := &JSONStream{
+		w:      c.Response(),
+		ctx:    c.Context(),
+		ndjson: quality(ranges, contentTypeNDJSON) > quality(ranges, contentTypeJSON),
+	}
+	if s.ndjson {
+		h.Set("content-type", contentTypeNDJSON)
+	} else {
+		h.Set("content-type", contentTypeJSON)
+	}
+	h.Add("Trailer", streamErrorTrailer)
+
+	c.WriteStatus(status)
+	if err := c.Response().Stream(); err != nil {
+		// the error is written in place of the stream, which has no trailer
+		h.Del("Trailer")
+		return nil, err
+	}
+	s.lastFlush = time.Now()
+
+	if !s.ndjson {
+		if _, err := s.w.Write([]byte("[")); err != nil {
+			return nil, err
+		}
+	}
+	s.written = s.w.Len()
+	return s, nil
+}
+
+// NDJSON returns true if values are written as newline delimited JSON rather than as
+// the elements of a JSON array
+func (s *JSONStream) NDJSON() bool {
+	return s.ndjson
+}
+
+// Encode writes v to the stream. Nothing is written when v cannot be encoded, so the
+// stream remains valid if the handler chooses to continue. The error of the request
+// context is returned once the client disconnects
+func (s *JSONStream) Encode(v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("could not encode JSON stream value: %+v", err)
+	}
+
+	s.m.Lock()
+	defer s.m.Unlock()
+	if s.closed {
+		return errJSONStreamClosed
+	}
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+
+	switch {
+	case s.ndjson:
+		b = append(b, '\n')
+	case s.count > 0:
+		b = append([]byte(","), b...)
+	}
+	if _, err := s.w.Write(b); err != nil {
+		return err
+	}
+	s.written = s.w.Len()
+	s.count++
+
+	interval := s.FlushInterval
+	if interval <= 0 {
+		interval = DefaultStreamFlushInterval
+	}
+	if time.Since(s.lastFlush) >= interval {
+		return s.flush()
+	}
+	return nil
+}
+
+// Flush sends all encoded values to the client
+func (s *JSONStream) Flush() error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return s.flush()
+}
+
+func (s *JSONStream) flush() error {
+	s.lastFlush = time.Now()
+	return s.w.Flush()
+}
+
+// Close terminates the stream and sends it to the client. It is called by the Router once
+// the handler returns
+func (s *JSONStream) Close() error {
+	return s.finish(nil)
+}
+
+// finish closes the stream, aborting it when err is not nil
+func (s *JSONStream) finish(err error) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+
+	if err != nil {
+		return s.abort(err)
+	}
+	if !s.ndjson {
+		if _, err := s.w.Write([]byte("]")); err != nil {
+			return err
+		}
+	}
+	return s.flush()
+}
+
+func (s *JSONStream) abort(err error) error {
+	httperr, ok := err.(HTTPError)
+	if !ok {
+		httperr = NewHTTPError(http.StatusInternalServerError, err)
+	}
+	// the cause is used rather than Error, which includes the stack of a PanicError
+	msg := http.StatusText(httperr.Status())
+	if cause := httperr.Cause(); cause != nil {
+		msg = cause.Error()
+	}
+	s.w.Header().Set(streamErrorTrailer, msg)
+
+	// the ErrorHandler writes the error itself when nothing has been written yet
+	if s.ndjson && s.w.Len() == s.written {
+		b, merr := json.Marshal(httperr)
+		if merr != nil {
+			return merr
+		}
+		if _, err := s.w.Write(append(b, '\n')); err != nil {
+			return err
+		}
+	}
+	return s.flush()
+}
@@ -0,0 +1,226 @@
+package boar
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type streamRecord struct {
+	ID int `json:"id"`
+}
+
+func serveJSONStream(t *testing.T, accept string, h HandlerFunc) *http.Response {
+	r := NewRouter()
+	r.MethodFunc(http.MethodGet, "/", h)
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+	require.NoError(t, err)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func encodeRecords(n int) HandlerFunc {
+	return func(c Context) error {
+		s, err := c.StreamJSON(http.StatusOK)
+		if err != nil {
+			return err
+		}
+		for i := 1; i <= n; i++ {
+			if err := s.Encode(streamRecord{ID: i}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func TestStreamJSONArray(t *testing.T) {
+	resp := serveJSONStream(t, "", encodeRecords(3))
+
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, contentTypeJSON, resp.Header.Get("content-type"))
+	assert.Equal(t, `[{"id":1},{"id":2},{"id":3}]`, string(body))
+	assert.Empty(t, resp.Trailer.Get(streamErrorTrailer))
+}
+
+func TestStreamJSONEmptyArray(t *testing.T) {
+	resp := serveJSONStream(t, "", encodeRecords(0))
+
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, `[]`, string(body))
+}
+
+func TestStreamJSONNDJSON(t *testing.T) {
+	resp := serveJSONStream(t, "application/x-ndjson", encodeRecords(2))
+
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, contentTypeNDJSON, resp.Header.Get("content-type"))
+	assert.Equal(t, "{\"id\":1}\n{\"id\":2}\n", string(body))
+}
+
+func TestStreamJSONPrefersArrayWhenEquallyAcceptable(t *testing.T) {
+	resp := serveJSONStream(t, "*/*", encodeRecords(1))
+	assert.Equal(t, contentTypeJSON, resp.Header.Get("content-type"))
+}
+
+func TestStreamJSONSendsValuesBeforeHandlerReturns(t *testing.T) {
+	release := make(chan struct{})
+	resp := serveJSONStream(t, "application/x-ndjson", func(c Context) error {
+		s, err := c.StreamJSON(http.StatusOK)
+		if err != nil {
+			return err
+		}
+		s.FlushInterval = time.Nanosecond
+		if err := s.Encode(streamRecord{ID: 1}); err != nil {
+			return err
+		}
+		<-release
+		return nil
+	})
+	defer close(release)
+
+	line, err := bufio.NewReader(resp.Body).ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "{\"id\":1}\n", line)
+}
+
+func TestStreamJSONErrorAbortsArray(t *testing.T) {
+	resp := serveJSONStream(t, "", func(c Context) error {
+		s, err := c.StreamJSON(http.StatusOK)
+		if err != nil {
+			return err
+		}
+		s.Encode(streamRecord{ID: 1})
+		return errors.New("database went away")
+	})
+
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, `[{"id":1}`, string(body), "array should be left unterminated")
+	assert.Equal(t, "database went away", resp.Trailer.Get(streamErrorTrailer))
+}
+
+func TestStreamJSONErrorEndsNDJSONWithErrorLine(t *testing.T) {
+	resp := serveJSONStream(t, "application/x-ndjson", func(c Context) error {
+		s, err := c.StreamJSON(http.StatusOK)
+		if err != nil {
+			return err
+		}
+		s.Encode(streamRecord{ID: 1})
+		return ErrForbidden
+	})
+
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "{\"id\":1}\n{\"error\":\"Forbidden\"}\n", string(body))
+	assert.NotEmpty(t, resp.Trailer.Get(streamErrorTrailer))
+}
+
+func TestStreamJSONErrorBeforeFirstValueWritesNDJSONErrorOnce(t *testing.T) {
+	resp := serveJSONStream(t, "application/x-ndjson", func(c Context) error {
+		if _, err := c.StreamJSON(http.StatusOK); err != nil {
+			return err
+		}
+		return ErrForbidden
+	})
+
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	assert.Equal(t, "{\"error\":\"Forbidden\"}\n", string(body))
+	assert.Equal(t, "Forbidden", resp.Trailer.Get(streamErrorTrailer))
+}
+
+func TestStreamJSONErrorTrailerOmitsPanicStack(t *testing.T) {
+	r := NewRouter()
+	r.Use(PanicMiddleware)
+	r.MethodFunc(http.MethodGet, "/", func(c Context) error {
+		s, err := c.StreamJSON(http.StatusOK)
+		if err != nil {
+			return err
+		}
+		s.Encode(streamRecord{ID: 1})
+		panic("database went away")
+	})
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	_, err = ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "database went away", resp.Trailer.Get(streamErrorTrailer))
+}
+
+func TestStreamJSONUnencodableValueWritesNothing(t *testing.T) {
+	rec := httptest.NewRecorder()
+	c := newContext(httptest.NewRequest(http.MethodGet, "/", nil), rec, nil)
+	s, err := c.StreamJSON(http.StatusOK)
+	require.NoError(t, err)
+
+	require.NoError(t, s.Encode(streamRecord{ID: 1}))
+	assert.Error(t, s.Encode(make(chan int)))
+	require.NoError(t, s.Encode(streamRecord{ID: 2}))
+	require.NoError(t, s.Close())
+
+	assert.Equal(t, `[{"id":1},{"id":2}]`, rec.Body.String())
+}
+
+func TestStreamJSONStopsOnContextCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	rec := httptest.NewRecorder()
+	c := newContext(httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx), rec, nil)
+	s, err := c.StreamJSON(http.StatusOK)
+	require.NoError(t, err)
+
+	cancel()
+	assert.Equal(t, context.Canceled, s.Encode(streamRecord{ID: 1}))
+	assert.Equal(t, "[", rec.Body.String())
+}
+
+func TestStreamJSONFlushesPeriodically(t *testing.T) {
+	rec := httptest.NewRecorder()
+	c := newContext(httptest.NewRequest(http.MethodGet, "/", nil), rec, nil)
+	s, err := c.StreamJSON(http.StatusCreated)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	s.FlushInterval = time.Hour
+	rec.Flushed = false
+	require.NoError(t, s.Encode(streamRecord{ID: 1}))
+	assert.False(t, rec.Flushed)
+
+	s.FlushInterval = time.Nanosecond
+	require.NoError(t, s.Encode(streamRecord{ID: 2}))
+	assert.True(t, rec.Flushed)
+}
+
+func TestStreamJSONEncodeAfterClose(t *testing.T) {
+	c := newContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder(), nil)
+	s, err := c.StreamJSON(http.StatusOK)
+	require.NoError(t, err)
+
+	require.NoError(t, s.Close())
+	assert.Equal(t, errJSONStreamClosed, s.Encode(streamRecord{ID: 1}))
+}
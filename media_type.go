@@ -0,0 +1,156 @@
+package boar
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+var errUnknownCharset = errors.New("unknown charset")
+
+// mediaType is a parsed content-type header
+type mediaType struct {
+	// Type is the full media type without parameters (e.g. application/vnd.api+json)
+	Type string
+	// Base is the media type with any structured syntax suffix resolved to its
+	// generic type (e.g. application/vnd.api+json becomes application/json)
+	Base string
+	// Params are the media type parameters such as charset or boundary
+	Params map[string]string
+}
+
+// parseMediaType parses a content-type header value according to RFC 7231
+// and resolves +json and +xml structured syntax suffixes (RFC 6839)
+func parseMediaType(v string) (*mediaType, error) {
+	typ, params, err := mime.ParseMediaType(v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid content type %q: %v", v, err)
+	}
+
+	mt := &mediaType{
+		Type:   typ,
+		Base:   typ,
+		Params: params,
+	}
+
+	slash := strings.IndexByte(typ, '/')
+	if plus := strings.LastIndexByte(typ, '+'); plus > slash {
+		switch suffix := typ[plus+1:]; suffix {
+		case "json", "xml":
+			mt.Base = "application/" + suffix
+		}
+	}
+	return mt, nil
+}
+
+// Charset returns the lowercased charset parameter or an empty string if it was
+// not provided
+func (m *mediaType) Charset() string {
+	return strings.ToLower(strings.TrimSpace(m.Params["charset"]))
+}
+
+// charsetReader wraps r so that reading from it yields UTF-8 regardless of the charset
+// the body was encoded with. Only the charsets supported by the standard library are
+// able to be transcoded. errUnknownCharset is returned for everything else
+func charsetReader(charset string, r io.Reader) (io.Reader, error) {
+	switch charset {
+	case "", "utf-8", "utf8", "us-ascii", "ascii":
+		return r, nil
+	case "iso-8859-1", "latin1", "latin-1":
+		return &latin1Reader{r: bufio.NewReader(r)}, nil
+	case "utf-16be":
+		return &utf16Reader{r: bufio.NewReader(r), bigEndian: true}, nil
+	case "utf-16le":
+		return &utf16Reader{r: bufio.NewReader(r)}, nil
+	case "utf-16":
+		return &utf16Reader{r: bufio.NewReader(r), bigEndian: true, detectBOM: true}, nil
+	default:
+		return nil, errUnknownCharset
+	}
+}
+
+// latin1Reader transcodes ISO-8859-1 into UTF-8. Every byte in ISO-8859-1 maps
+// directly to the unicode code point of the same value
+type latin1Reader struct {
+	r   *bufio.Reader
+	buf []byte
+}
+
+func (l *latin1Reader) Read(p []byte) (int, error) {
+	for len(l.buf) < len(p) {
+		b, err := l.r.ReadByte()
+		if err != nil {
+			if len(l.buf) > 0 {
+				break
+			}
+			return 0, err
+		}
+		l.buf = utf8.AppendRune(l.buf, rune(b))
+	}
+	n := copy(p, l.buf)
+	l.buf = l.buf[n:]
+	return n, nil
+}
+
+// utf16Reader transcodes UTF-16 into UTF-8. When detectBOM is true the byte order
+// mark, if present, determines the byte order and is stripped from the output
+type utf16Reader struct {
+	r         *bufio.Reader
+	buf       []byte
+	bigEndian bool
+	detectBOM bool
+}
+
+func (u *utf16Reader) Read(p []byte) (int, error) {
+	if u.detectBOM {
+		u.detectBOM = false
+		if bom, err := u.r.Peek(2); err == nil {
+			switch {
+			case bom[0] == 0xFE && bom[1] == 0xFF:
+				u.bigEndian = true
+				u.r.Discard(2)
+			case bom[0] == 0xFF && bom[1] == 0xFE:
+				u.bigEndian = false
+				u.r.Discard(2)
+			}
+		}
+	}
+
+	for len(u.buf) < len(p) {
+		r1, err := u.readUnit()
+		if err != nil {
+			if len(u.buf) > 0 {
+				break
+			}
+			return 0, err
+		}
+		r := rune(r1)
+		if utf16.IsSurrogate(r) {
+			r2, err := u.readUnit()
+			if err != nil {
+				return 0, io.ErrUnexpectedEOF
+			}
+			r = utf16.DecodeRune(r, rune(r2))
+		}
+		u.buf = utf8.AppendRune(u.buf, r)
+	}
+	n := copy(p, u.buf)
+	u.buf = u.buf[n:]
+	return n, nil
+}
+
+func (u *utf16Reader) readUnit() (uint16, error) {
+	var b [2]byte
+	if _, err := io.ReadFull(u.r, b[:]); err != nil {
+		return 0, err
+	}
+	if u.bigEndian {
+		return uint16(b[0])<<8 | uint16(b[1]), nil
+	}
+	return uint16(b[1])<<8 | uint16(b[0]), nil
+}
@@ -0,0 +1,83 @@
+package boar
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMediaTypeStripsParameters(t *testing.T) {
+	mt, err := parseMediaType("application/json; charset=UTF-8")
+	require.NoError(t, err)
+	assert.Equal(t, contentTypeJSON, mt.Type)
+	assert.Equal(t, contentTypeJSON, mt.Base)
+	assert.Equal(t, "utf-8", mt.Charset())
+}
+
+func TestParseMediaTypeResolvesJSONSuffix(t *testing.T) {
+	for _, ct := range []string{"application/merge-patch+json", "application/vnd.api+json"} {
+		mt, err := parseMediaType(ct)
+		require.NoError(t, err)
+		assert.Equal(t, ct, mt.Type)
+		assert.Equal(t, contentTypeJSON, mt.Base)
+	}
+}
+
+func TestParseMediaTypeResolvesXMLSuffix(t *testing.T) {
+	mt, err := parseMediaType("application/atom+xml")
+	require.NoError(t, err)
+	assert.Equal(t, "application/xml", mt.Base)
+}
+
+func TestParseMediaTypeIgnoresUnknownSuffix(t *testing.T) {
+	mt, err := parseMediaType("application/vnd.custom+zip")
+	require.NoError(t, err)
+	assert.Equal(t, "application/vnd.custom+zip", mt.Base)
+}
+
+func TestParseMediaTypeErrorsWhenInvalid(t *testing.T) {
+	_, err := parseMediaType("application/json; charset")
+	require.Error(t, err)
+}
+
+func TestCharsetReaderPassesThroughUTF8(t *testing.T) {
+	body := bytes.NewBufferString("héllo")
+	r, err := charsetReader("utf-8", body)
+	require.NoError(t, err)
+	assert.Equal(t, body, r)
+}
+
+func TestCharsetReaderTranscodesLatin1(t *testing.T) {
+	r, err := charsetReader("iso-8859-1", bytes.NewBuffer([]byte{'h', 0xE9, 'l', 'l', 'o'}))
+	require.NoError(t, err)
+
+	b, err := ioutil.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "héllo", string(b))
+}
+
+func TestCharsetReaderTranscodesUTF16WithBOM(t *testing.T) {
+	r, err := charsetReader("utf-16", bytes.NewBuffer([]byte{0xFF, 0xFE, 'h', 0, 0xE9, 0, 0x3D, 0xD8, 0x00, 0xDE}))
+	require.NoError(t, err)
+
+	b, err := ioutil.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "hé😀", string(b))
+}
+
+func TestCharsetReaderTranscodesUTF16BE(t *testing.T) {
+	r, err := charsetReader("utf-16be", bytes.NewBuffer([]byte{0, 'h', 0, 'i'}))
+	require.NoError(t, err)
+
+	b, err := ioutil.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "hi", string(b))
+}
+
+func TestCharsetReaderErrorsWhenUnknownCharset(t *testing.T) {
+	_, err := charsetReader("shift_jis", bytes.NewBufferString(""))
+	assert.Equal(t, errUnknownCharset, err)
+}
@@ -8,8 +8,10 @@ import (
 	
 	httprouter "github.com/julienschmidt/httprouter"
 	gomock "github.com/golang/mock/gomock"
+	io "io"
 	http "net/http"
 	reflect "reflect"
+	time "time"
 )
 
 // MockHTTPError is a mock of HTTPError interface
@@ -67,8 +69,8 @@ func (m *MockHTTPError) MarshalJSON() ([]byte, error) {
 	return ret0, ret1
 }
 
-// MarshalJSON indicates an expected call of MarshalJSON
-func (mr *MockHTTPErrorMockRecorder) MarshalJSON() *gomock.Call {
+// MarshalJSONCall indicates an expected call of MarshalJSON
+func (mr *MockHTTPErrorMockRecorder) MarshalJSONCall() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarshalJSON", reflect.TypeOf((*MockHTTPError)(nil).MarshalJSON))
 }
 
@@ -107,6 +109,18 @@ func (m *MockContext) EXPECT() *MockContextMockRecorder {
 	return m.recorder
 }
 
+// Bind mocks base method
+func (m *MockContext) Bind(arg0 interface{}) error {
+	ret := m.ctrl.Call(m, "Bind", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Bind indicates an expected call of Bind
+func (mr *MockContextMockRecorder) Bind(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Bind", reflect.TypeOf((*MockContext)(nil).Bind), arg0)
+}
+
 // Context mocks base method
 func (m *MockContext) Context() context.Context {
 	ret := m.ctrl.Call(m, "Context")
@@ -119,6 +133,81 @@ func (mr *MockContextMockRecorder) Context() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockContext)(nil).Context))
 }
 
+// MultipartReader mocks base method
+func (m *MockContext) MultipartReader() (*MultipartReader, error) {
+	ret := m.ctrl.Call(m, "MultipartReader")
+	ret0, _ := ret[0].(*MultipartReader)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MultipartReader indicates an expected call of MultipartReader
+func (mr *MockContextMockRecorder) MultipartReader() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MultipartReader", reflect.TypeOf((*MockContext)(nil).MultipartReader))
+}
+
+// ServeContent mocks base method
+func (m *MockContext) ServeContent(arg0 string, arg1 time.Time, arg2 io.ReadSeeker) error {
+	ret := m.ctrl.Call(m, "ServeContent", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ServeContent indicates an expected call of ServeContent
+func (mr *MockContextMockRecorder) ServeContent(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ServeContent", reflect.TypeOf((*MockContext)(nil).ServeContent), arg0, arg1, arg2)
+}
+
+// File mocks base method
+func (m *MockContext) File(arg0 string) error {
+	ret := m.ctrl.Call(m, "File", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// File indicates an expected call of File
+func (mr *MockContextMockRecorder) File(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "File", reflect.TypeOf((*MockContext)(nil).File), arg0)
+}
+
+// Attachment mocks base method
+func (m *MockContext) Attachment(arg0, arg1 string) error {
+	ret := m.ctrl.Call(m, "Attachment", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Attachment indicates an expected call of Attachment
+func (mr *MockContextMockRecorder) Attachment(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Attachment", reflect.TypeOf((*MockContext)(nil).Attachment), arg0, arg1)
+}
+
+// StreamJSON mocks base method
+func (m *MockContext) StreamJSON(arg0 int) (*JSONStream, error) {
+	ret := m.ctrl.Call(m, "StreamJSON", arg0)
+	ret0, _ := ret[0].(*JSONStream)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StreamJSON indicates an expected call of StreamJSON
+func (mr *MockContextMockRecorder) StreamJSON(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamJSON", reflect.TypeOf((*MockContext)(nil).StreamJSON), arg0)
+}
+
+// SSE mocks base method
+func (m *MockContext) SSE() (*EventStream, error) {
+	ret := m.ctrl.Call(m, "SSE")
+	ret0, _ := ret[0].(*EventStream)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SSE indicates an expected call of SSE
+func (mr *MockContextMockRecorder) SSE() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SSE", reflect.TypeOf((*MockContext)(nil).SSE))
+}
+
 // ReadForm mocks base method
 func (m *MockContext) ReadForm(arg0 interface{}) error {
 	ret := m.ctrl.Call(m, "ReadForm", arg0)
@@ -167,6 +256,30 @@ func (mr *MockContextMockRecorder) ReadURLParams(arg0 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadURLParams", reflect.TypeOf((*MockContext)(nil).ReadURLParams), arg0)
 }
 
+// ReadXML mocks base method
+func (m *MockContext) ReadXML(arg0 interface{}) error {
+	ret := m.ctrl.Call(m, "ReadXML", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReadXML indicates an expected call of ReadXML
+func (mr *MockContextMockRecorder) ReadXML(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadXML", reflect.TypeOf((*MockContext)(nil).ReadXML), arg0)
+}
+
+// Render mocks base method
+func (m *MockContext) Render(arg0 int, arg1 interface{}) error {
+	ret := m.ctrl.Call(m, "Render", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Render indicates an expected call of Render
+func (mr *MockContextMockRecorder) Render(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Render", reflect.TypeOf((*MockContext)(nil).Render), arg0, arg1)
+}
+
 // Request mocks base method
 func (m *MockContext) Request() *http.Request {
 	ret := m.ctrl.Call(m, "Request")
@@ -227,6 +340,18 @@ func (mr *MockContextMockRecorder) WriteStatus(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteStatus", reflect.TypeOf((*MockContext)(nil).WriteStatus), arg0)
 }
 
+// WriteXML mocks base method
+func (m *MockContext) WriteXML(arg0 int, arg1 interface{}) error {
+	ret := m.ctrl.Call(m, "WriteXML", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WriteXML indicates an expected call of WriteXML
+func (mr *MockContextMockRecorder) WriteXML(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteXML", reflect.TypeOf((*MockContext)(nil).WriteXML), arg0, arg1)
+}
+
 // MockResponseWriter is a mock of ResponseWriter interface
 type MockResponseWriter struct {
 	ctrl     *gomock.Controller
@@ -310,6 +435,18 @@ func (mr *MockResponseWriterMockRecorder) Status() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Status", reflect.TypeOf((*MockResponseWriter)(nil).Status))
 }
 
+// Stream mocks base method
+func (m *MockResponseWriter) Stream() error {
+	ret := m.ctrl.Call(m, "Stream")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Stream indicates an expected call of Stream
+func (mr *MockResponseWriterMockRecorder) Stream() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stream", reflect.TypeOf((*MockResponseWriter)(nil).Stream))
+}
+
 // Write mocks base method
 func (m *MockResponseWriter) Write(arg0 []byte) (int, error) {
 	ret := m.ctrl.Call(m, "Write", arg0)
@@ -367,3 +504,21 @@ func (m *MockHandler) Handle(arg0 Context) error {
 func (mr *MockHandlerMockRecorder) Handle(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Handle", reflect.TypeOf((*MockHandler)(nil).Handle), arg0)
 }
+
+// URLFor mocks base method
+func (m *MockContext) URLFor(arg0 string, arg1 ...string) (string, error) {
+	varargs := []interface{}{arg0}
+	for _, a := range arg1 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "URLFor", varargs...)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// URLFor indicates an expected call of URLFor
+func (mr *MockContextMockRecorder) URLFor(arg0 interface{}, arg1 ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{arg0}, arg1...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "URLFor", reflect.TypeOf((*MockContext)(nil).URLFor), varargs...)
+}
@@ -0,0 +1,110 @@
+package boar
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// mountParam is the name of the catch-all parameter of mounted handlers
+const mountParam = "mountpath"
+
+// Mount serves h for requests of every method to prefix and every path beneath it. The
+// prefix is stripped from the request URL before it is passed to h, so h sees a request
+// for / when prefix itself is requested. Requests pass through the global middlewares
+// and errors returned by them are handled by the ErrorHandler, but h writes its own
+// responses. The response is buffered like any other route, unless the StreamResponse
+// RouteOption is given, and the http.ResponseWriter passed to h implements http.Flusher,
+// http.Hijacker, http.Pusher and io.ReaderFrom when the server supports them.
+//
+// Mount is useful for hosting existing handlers such as net/http/pprof:
+//
+//	rtr.Mount("/debug/pprof", http.HandlerFunc(pprof.Index))
+func (rtr *Router) Mount(prefix string, h http.Handler, opts ...RouteOption) {
+	prefix = strings.TrimSuffix(prefix, "/")
+	opts = append([]RouteOption{StreamBody(), handlerName(fmt.Sprintf("%T", h)), mounted()}, opts...)
+
+	serve := func(c Context) error {
+		r := stripPrefix(c.Request(), c.URLParams().ByName(mountParam))
+		var w http.ResponseWriter = c.Response()
+		if bw, ok := w.(*BufferedResponseWriter); ok {
+			w = bw.HTTPResponseWriter()
+		}
+		h.ServeHTTP(w, r)
+		return nil
+	}
+
+	// the catch-all route of the first method describes the mount in the route table and
+	// holds its Name, so that URLs beneath prefix can be built
+	rest := append(append([]RouteOption{}, opts...), Name(""), hidden())
+	for i, method := range allMethods {
+		if prefix != "" {
+			rtr.MethodFunc(method, prefix, serve, rest...)
+		}
+		if i == 0 {
+			rtr.MethodFunc(method, prefix+"/*"+mountParam, serve, opts...)
+			continue
+		}
+		rtr.MethodFunc(method, prefix+"/*"+mountParam, serve, rest...)
+	}
+}
+
+// mounted marks the routes registered by Mount
+func mounted() RouteOption {
+	return func(rt *route) {
+		rt.mounted = true
+	}
+}
+
+// hidden leaves a route out of the route table
+func hidden() RouteOption {
+	return func(rt *route) {
+		rt.hidden = true
+	}
+}
+
+// MountRouter serves the routes of sub beneath prefix. Requests pass through the global
+// middlewares of rtr and then through the middlewares of sub, and errors returned by the
+// handlers of sub are handled by the ErrorHandler of sub. Requests that do not match a
+// route of sub are handled by its NotFound and MethodNotAllowed handlers. Routes of sub
+// are registered without the prefix:
+//
+//	users := boar.NewRouter()
+//	users.Get("/:id", getUser)
+//	rtr.MountRouter("/users", users) // serves GET /users/:id
+func (rtr *Router) MountRouter(prefix string, sub *Router, opts ...RouteOption) {
+	rtr.Mount(prefix, sub, opts...)
+}
+
+// stripPrefix returns a shallow copy of r with prefix removed from the URL. rest is the
+// remainder of the path after prefix
+func stripPrefix(r *http.Request, rest string) *http.Request {
+	if rest == "" {
+		rest = "/"
+	}
+
+	r2 := new(http.Request)
+	*r2 = *r
+	r2.URL = new(url.URL)
+	*r2.URL = *r.URL
+	r2.URL.Path = rest
+	r2.URL.RawPath = rawSuffix(r.URL.RawPath, rest)
+	return r2
+}
+
+// rawSuffix returns the segments at the end of rawPath which are the escaped form of rest.
+// The prefix may contain escaped characters or parameters, so rawPath is matched by the
+// path that it decodes to. An empty string is returned when no suffix decodes to rest, so
+// that the escaped path is derived from rest instead
+func rawSuffix(rawPath, rest string) string {
+	for i := len(rawPath) - 1; i >= 0; i-- {
+		if rawPath[i] != '/' {
+			continue
+		}
+		if p, err := url.PathUnescape(rawPath[i:]); err == nil && p == rest {
+			return rawPath[i:]
+		}
+	}
+	return ""
+}
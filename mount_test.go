@@ -0,0 +1,196 @@
+package boar
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func serveMount(r *Router, method, target string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+	return rec
+}
+
+func TestMountStripsPrefix(t *testing.T) {
+	r := NewRouter()
+	r.Mount("/legacy", http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		fmt.Fprintf(w, "%s %s?%s", req.Method, req.URL.Path, req.URL.RawQuery)
+	}))
+
+	rec := serveMount(r, http.MethodPost, "/legacy/users/1?active=true")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "POST /users/1?active=true", rec.Body.String())
+
+	rec = serveMount(r, http.MethodGet, "/legacy")
+	assert.Equal(t, "GET /?", rec.Body.String())
+
+	rec = serveMount(r, http.MethodGet, "/legacy/")
+	assert.Equal(t, "GET /?", rec.Body.String())
+}
+
+func TestMountStripsRawPath(t *testing.T) {
+	r := NewRouter()
+	r.Mount("/legacy", http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		fmt.Fprint(w, req.URL.EscapedPath())
+	}))
+
+	rec := serveMount(r, http.MethodGet, "/legacy/a%2Fb")
+	assert.Equal(t, "/a%2Fb", rec.Body.String())
+}
+
+func TestMountStripsRawPathOfParameterizedPrefix(t *testing.T) {
+	r := NewRouter()
+	r.Mount("/t/:tenant", http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		fmt.Fprintf(w, "%s %s", req.URL.Path, req.URL.EscapedPath())
+	}))
+
+	rec := serveMount(r, http.MethodGet, "/t/a%20b/files/c%2Fd")
+	assert.Equal(t, "/files/c/d /files/c%2Fd", rec.Body.String())
+
+	rec = serveMount(r, http.MethodGet, "/t/a%20b/files/c%20d")
+	assert.Equal(t, "/files/c d /files/c%20d", rec.Body.String())
+
+	// the escaped slash of the tenant splits the path, so no raw suffix decodes to the rest
+	rec = serveMount(r, http.MethodGet, "/t/a%2Fb/c")
+	assert.Equal(t, "/b/c /b/c", rec.Body.String())
+}
+
+func TestMountDoesNotModifyOriginalRequest(t *testing.T) {
+	r := NewRouter()
+	var path string
+	r.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c Context) error {
+			err := next(c)
+			path = c.Request().URL.Path
+			return err
+		}
+	})
+	r.Mount("/legacy", http.NotFoundHandler())
+
+	serveMount(r, http.MethodGet, "/legacy/users")
+	assert.Equal(t, "/legacy/users", path)
+}
+
+func TestMountRunsGlobalMiddleware(t *testing.T) {
+	r := NewRouter()
+	var status int
+	r.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c Context) error {
+			if c.Request().Header.Get("authorization") == "" {
+				return ErrUnauthorized
+			}
+			err := next(c)
+			status = c.Response().Status()
+			c.Response().Header().Set("x-after", "set after the handler")
+			return err
+		}
+	})
+	r.Mount("/legacy", http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	rec := serveMount(r, http.MethodGet, "/legacy")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	req := httptest.NewRequest(http.MethodGet, "/legacy", nil)
+	req.Header.Set("authorization", "token")
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusTeapot, rec.Code)
+	assert.Equal(t, http.StatusTeapot, status)
+	assert.Equal(t, "set after the handler", rec.Header().Get("x-after"), "mounted responses should be buffered")
+}
+
+func TestMountedHandlerCanFlush(t *testing.T) {
+	r := NewRouter()
+	rec := httptest.NewRecorder()
+	r.Mount("/stream", http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		fmt.Fprint(w, "first")
+		f, ok := w.(http.Flusher)
+		require.True(t, ok, "mounted handlers should receive an http.Flusher")
+		f.Flush()
+		assert.Equal(t, "first", rec.Body.String())
+		assert.True(t, rec.Flushed)
+	}))
+
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/stream", nil))
+	assert.Equal(t, "first", rec.Body.String())
+}
+
+func TestMountedHandlerReadsBody(t *testing.T) {
+	r := NewRouter()
+	r.Mount("/legacy", http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		io.Copy(w, req.Body)
+	}))
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/legacy/echo", nil)
+	req.Body = io.NopCloser(strings.NewReader("hello"))
+	req.Header.Set("content-type", contentTypeJSON)
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, "hello", rec.Body.String())
+}
+
+func TestMountRouter(t *testing.T) {
+	sub := NewRouter()
+	sub.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c Context) error {
+			c.Response().Header().Set("x-sub", "yes")
+			return next(c)
+		}
+	})
+	sub.ErrorHandler = func(c Context, err error) {
+		if c.Response().Len() > 0 {
+			return
+		}
+		msg := err.Error()
+		if httpErr, ok := err.(HTTPError); ok {
+			msg = http.StatusText(httpErr.Status())
+		}
+		c.Response().Header().Set("content-type", "text/plain")
+		c.WriteStatus(http.StatusServiceUnavailable)
+		fmt.Fprint(c.Response(), "sub: "+msg)
+	}
+	sub.MethodFunc(http.MethodGet, "/:id", func(c Context) error {
+		return c.WriteJSON(http.StatusOK, JSON{"id": c.URLParams().ByName("id")})
+	})
+	sub.MethodFunc(http.MethodGet, "/:id/fail", func(c Context) error {
+		return errors.New("failed")
+	})
+
+	r := NewRouter()
+	var order []string
+	r.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c Context) error {
+			order = append(order, "global")
+			return next(c)
+		}
+	})
+	r.MountRouter("/users", sub)
+
+	rec := serveMount(r, http.MethodGet, "/users/42")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"id":"42"}`, rec.Body.String())
+	assert.Equal(t, "yes", rec.Header().Get("x-sub"))
+	assert.Equal(t, []string{"global"}, order)
+
+	rec = serveMount(r, http.MethodGet, "/users/42/fail")
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "sub: failed", rec.Body.String())
+
+	rec = serveMount(r, http.MethodGet, "/users/42/missing/route")
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code, "sub router should handle its own not found")
+	assert.Equal(t, "sub: Not Found", rec.Body.String())
+
+	rec = serveMount(r, http.MethodDelete, "/users/42")
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "GET", rec.Header().Get("Allow"))
+}
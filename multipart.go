@@ -0,0 +1,114 @@
+package boar
+
+import (
+	"bufio"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+)
+
+// MultipartReader iterates over the parts of a multipart request body as they are
+// received. Unlike Body binding, nothing is buffered in memory or spooled to disk, which
+// allows very large uploads to be streamed directly to storage. Use it on routes that
+// are registered with StreamBody
+type MultipartReader struct {
+	// MaxPartSize is the maximum number of bytes that can be read from a single part.
+	// Reading more returns ErrRequestEntityTooLarge. Zero means there is no limit
+	MaxPartSize int64
+
+	// MaxParts is the maximum number of parts in the request. NextPart returns
+	// ErrRequestEntityTooLarge when there are more. Zero means there is no limit
+	MaxParts int
+
+	r     *multipart.Reader
+	count int
+	last  *Part
+}
+
+// NextPart returns the next part of the body or io.EOF when there are no more parts.
+// Any unread data in the previous part is discarded. Handlers can inspect the name,
+// filename, headers and sniffed content type of the part before reading it to reject
+// the request early
+func (m *MultipartReader) NextPart() (*Part, error) {
+	if m.last != nil {
+		m.last.Close()
+		m.last = nil
+	}
+
+	p, err := m.r.NextPart()
+	if err != nil {
+		if err == io.EOF {
+			return nil, err
+		}
+		if tooLarge := requestTooLarge(err); tooLarge != nil {
+			return nil, tooLarge
+		}
+		return nil, NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	m.count++
+	if m.MaxParts > 0 && m.count > m.MaxParts {
+		p.Close()
+		return nil, ErrRequestEntityTooLarge
+	}
+
+	m.last = &Part{
+		Part:   p,
+		reader: bufio.NewReaderSize(&limitedReader{r: p, max: m.MaxPartSize}, sniffLen),
+	}
+	return m.last, nil
+}
+
+// Part is a single part of a multipart body
+type Part struct {
+	*multipart.Part
+	reader *bufio.Reader
+}
+
+// Read reads the body of the part. ErrRequestEntityTooLarge is returned when the part
+// is larger than MultipartReader.MaxPartSize
+func (p *Part) Read(b []byte) (int, error) {
+	return p.reader.Read(b)
+}
+
+// ContentType detects the content type of the part from the first 512 bytes of its
+// contents without consuming them. The content-type header sent by the client is not
+// trusted
+func (p *Part) ContentType() (string, error) {
+	buf, err := p.reader.Peek(sniffLen)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return "", err
+	}
+	typ := http.DetectContentType(buf)
+	if i := strings.IndexByte(typ, ';'); i >= 0 {
+		typ = typ[:i]
+	}
+	return typ, nil
+}
+
+// limitedReader returns ErrRequestEntityTooLarge once more than max bytes are read
+type limitedReader struct {
+	r    io.Reader
+	max  int64
+	read int64
+}
+
+func (l *limitedReader) Read(b []byte) (int, error) {
+	if l.max <= 0 {
+		return l.r.Read(b)
+	}
+	if l.read > l.max {
+		return 0, ErrRequestEntityTooLarge
+	}
+	// read one byte more than allowed to detect parts that are too large
+	if remaining := l.max - l.read + 1; int64(len(b)) > remaining {
+		b = b[:remaining]
+	}
+	n, err := l.r.Read(b)
+	l.read += int64(n)
+	if l.read > l.max {
+		return n - int(l.read-l.max), ErrRequestEntityTooLarge
+	}
+	return n, err
+}
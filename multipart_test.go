@@ -0,0 +1,187 @@
+package boar
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultipartReaderIteratesParts(t *testing.T) {
+	req := newUploadRequest(t, map[string]string{"name": "brett"}, map[string][][]byte{
+		"avatar": {pngHeader},
+	})
+	c := NewContext(req, nil, nil)
+
+	mr, err := c.MultipartReader()
+	require.NoError(t, err)
+
+	p, err := mr.NextPart()
+	require.NoError(t, err)
+	assert.Equal(t, "name", p.FormName())
+	b, err := ioutil.ReadAll(p)
+	require.NoError(t, err)
+	assert.Equal(t, "brett", string(b))
+
+	p, err = mr.NextPart()
+	require.NoError(t, err)
+	assert.Equal(t, "avatar", p.FormName())
+	typ, err := p.ContentType()
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", typ)
+	b, err = ioutil.ReadAll(p)
+	require.NoError(t, err)
+	assert.Equal(t, pngHeader, b, "sniffing should not consume the part")
+
+	_, err = mr.NextPart()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestMultipartReaderEnforcesMaxPartSize(t *testing.T) {
+	req := newUploadRequest(t, nil, map[string][][]byte{
+		"big": {bytes.Repeat([]byte("a"), 2048)},
+	})
+	c := NewContext(req, nil, nil)
+
+	mr, err := c.MultipartReader()
+	require.NoError(t, err)
+	mr.MaxPartSize = 1024
+
+	p, err := mr.NextPart()
+	require.NoError(t, err)
+
+	n, err := io.Copy(ioutil.Discard, p)
+	assert.Equal(t, ErrRequestEntityTooLarge, err)
+	assert.EqualValues(t, 1024, n)
+}
+
+func TestMultipartReaderAllowsPartsOfMaxPartSize(t *testing.T) {
+	req := newUploadRequest(t, nil, map[string][][]byte{
+		"exact": {bytes.Repeat([]byte("a"), 1024)},
+	})
+	c := NewContext(req, nil, nil)
+
+	mr, err := c.MultipartReader()
+	require.NoError(t, err)
+	mr.MaxPartSize = 1024
+
+	p, err := mr.NextPart()
+	require.NoError(t, err)
+
+	n, err := io.Copy(ioutil.Discard, p)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1024, n)
+}
+
+func TestMultipartReaderEnforcesMaxParts(t *testing.T) {
+	req := newUploadRequest(t, nil, map[string][][]byte{
+		"docs": {[]byte("1"), []byte("2")},
+	})
+	c := NewContext(req, nil, nil)
+
+	mr, err := c.MultipartReader()
+	require.NoError(t, err)
+	mr.MaxParts = 1
+
+	_, err = mr.NextPart()
+	require.NoError(t, err)
+	_, err = mr.NextPart()
+	assert.Equal(t, ErrRequestEntityTooLarge, err)
+}
+
+func TestMultipartReaderReturnsUnsupportedMediaTypeWhenNotMultipart(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("{}"))
+	req.Header.Set("content-type", contentTypeJSON)
+	c := NewContext(req, nil, nil)
+
+	_, err := c.MultipartReader()
+	require.Implements(t, (*HTTPError)(nil), err)
+	assert.Equal(t, http.StatusUnsupportedMediaType, err.(HTTPError).Status())
+}
+
+type streamHandler struct {
+	handle HandlerFunc
+	Body   struct {
+		Name string `validate:"required"`
+	}
+}
+
+func (h *streamHandler) Handle(c Context) error { return h.handle(c) }
+
+func TestStreamBodyDoesNotParseBody(t *testing.T) {
+	r := NewRouter()
+
+	var names []string
+	h := &streamHandler{}
+	h.handle = func(c Context) error {
+		mr, err := c.MultipartReader()
+		if err != nil {
+			return err
+		}
+		for {
+			p, err := mr.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			names = append(names, p.FormName())
+		}
+		return c.WriteStatus(http.StatusNoContent)
+	}
+	r.Post("/", func(Context) (Handler, error) {
+		return h, nil
+	}, StreamBody())
+
+	req := newUploadRequest(t, nil, map[string][][]byte{
+		"upload": {[]byte("hello")},
+	})
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, []string{"upload"}, names)
+	assert.Empty(t, h.Body.Name)
+}
+
+func TestStreamBodyRejectsEarly(t *testing.T) {
+	r := NewRouter()
+	r.MethodFunc(http.MethodPost, "/", func(c Context) error {
+		mr, err := c.MultipartReader()
+		if err != nil {
+			return err
+		}
+		p, err := mr.NextPart()
+		if err != nil {
+			return err
+		}
+		if typ, err := p.ContentType(); err != nil || typ != "image/png" {
+			return ErrUnsupportedMediaType
+		}
+		return nil
+	}, StreamBody())
+
+	buf := &bytes.Buffer{}
+	mw := multipart.NewWriter(buf)
+	fw, err := mw.CreateFormFile("upload", "big.bin")
+	require.NoError(t, err)
+	_, err = fw.Write(bytes.Repeat([]byte("text"), 1<<16))
+	require.NoError(t, err)
+	require.NoError(t, mw.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/", buf)
+	req.Header.Set("content-type", mw.FormDataContentType())
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+	assert.True(t, buf.Len() > 0, "body should not have been read entirely")
+}
@@ -0,0 +1,302 @@
+package boar
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/blockloop/boar/internal/pattern"
+	"github.com/blockloop/boar/schema"
+)
+
+// OpenAPIVersion is the version of the OpenAPI Specification of generated documents
+const OpenAPIVersion = "3.1.0"
+
+// OpenAPIDocument is an OpenAPI document describing the routes of a Router. Only the parts
+// of the specification that can be derived from handlers are included, but the document
+// can be amended before it is served
+type OpenAPIDocument struct {
+	OpenAPI    string                     `json:"openapi"`
+	Info       OpenAPIInfo                `json:"info"`
+	Paths      map[string]OpenAPIPathItem `json:"paths"`
+	Components OpenAPIComponents          `json:"components"`
+}
+
+// OpenAPIInfo is the metadata of the API
+type OpenAPIInfo struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// OpenAPIPathItem holds the operations of a path keyed by lower case method
+type OpenAPIPathItem map[string]*OpenAPIOperation
+
+// OpenAPIOperation describes a single route
+type OpenAPIOperation struct {
+	OperationID string                     `json:"operationId,omitempty"`
+	Parameters  []OpenAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *OpenAPIRequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]OpenAPIResponse `json:"responses"`
+}
+
+// OpenAPIParameter describes a query, path or header parameter of an operation
+type OpenAPIParameter struct {
+	Name     string      `json:"name"`
+	In       string      `json:"in"`
+	Required bool        `json:"required,omitempty"`
+	Schema   interface{} `json:"schema"`
+}
+
+// OpenAPIRequestBody describes the request body of an operation
+type OpenAPIRequestBody struct {
+	Required bool                        `json:"required,omitempty"`
+	Content  map[string]OpenAPIMediaType `json:"content"`
+}
+
+// OpenAPIMediaType holds the schema of a request or response body
+type OpenAPIMediaType struct {
+	Schema interface{} `json:"schema"`
+}
+
+// OpenAPIResponse describes a response of an operation
+type OpenAPIResponse struct {
+	Description string                      `json:"description"`
+	Content     map[string]OpenAPIMediaType `json:"content,omitempty"`
+}
+
+// OpenAPIComponents holds the schemas referenced by the document
+type OpenAPIComponents struct {
+	Schemas map[string]interface{} `json:"schemas"`
+}
+
+const (
+	openAPISchemasPrefix         = "#/components/schemas/"
+	openAPIErrorSchema           = "Error"
+	openAPIValidationErrorSchema = "ValidationError"
+)
+
+// OpenAPI generates an OpenAPI document from the route table of the Router. Parameters are
+// described by the Query, URLParams and Headers fields of handlers and the request body by
+// the Body field, using the same tags that are used to bind them. The validate tags of fields
+// are mapped to JSON Schema constraints where there is an equivalent, such as required,
+// min, max, len, oneof and email. Successful responses are described by the type given by
+// Returns, and errors by the errors that the Router returns. The status of a successful
+// response is 201 for POST, 204 for DELETE without Returns and 200 otherwise. Routes
+// registered without Handles only have parameters for the segments of their path, and
+// handlers served with Mount are left out.
+//
+// The operationId of a route is its Name. Routes of different methods which share a name
+// are distinguished by their method, such as get.user and delete.user
+func (rtr *Router) OpenAPI(info OpenAPIInfo) *OpenAPIDocument {
+	doc := &OpenAPIDocument{
+		OpenAPI: OpenAPIVersion,
+		Info:    info,
+		Paths:   make(map[string]OpenAPIPathItem),
+	}
+	r := schema.NewReflector(openAPISchemasPrefix)
+	routes := rtr.Routes()
+	ids := operationIDs(routes)
+	for i, ri := range routes {
+		if ri.Mount {
+			continue
+		}
+		p := openAPIPath(ri.Path)
+		if doc.Paths[p] == nil {
+			doc.Paths[p] = make(OpenAPIPathItem)
+		}
+		op := openAPIOperation(r, ri)
+		op.OperationID = ids[i]
+		doc.Paths[p][strings.ToLower(ri.Method)] = op
+	}
+
+	doc.Components.Schemas = map[string]interface{}{
+		openAPIErrorSchema: &schema.Schema{
+			Type:     "object",
+			Required: []string{"error"},
+			Properties: map[string]*schema.Schema{
+				"error": {Type: "string"},
+			},
+		},
+		openAPIValidationErrorSchema: &schema.Schema{
+			Type:     "object",
+			Required: []string{"errors"},
+			Properties: map[string]*schema.Schema{
+				"errors": {
+					Type: "object",
+					AdditionalProperties: &schema.Schema{
+						Type:  "array",
+						Items: &schema.Schema{Type: "string"},
+					},
+				},
+			},
+		},
+	}
+	for name, s := range r.Defs() {
+		doc.Components.Schemas[name] = s
+	}
+	return doc
+}
+
+// OpenAPIHandler returns a handler which serves the OpenAPI document of rtr as JSON. The
+// document is generated for each request so that it includes every route of rtr
+//
+//	rtr.MethodFunc(http.MethodGet, "/openapi.json", rtr.OpenAPIHandler(boar.OpenAPIInfo{
+//		Title:   "Orders",
+//		Version: "1.0.0",
+//	}))
+func (rtr *Router) OpenAPIHandler(info OpenAPIInfo) HandlerFunc {
+	return func(c Context) error {
+		return c.WriteJSON(http.StatusOK, rtr.OpenAPI(info))
+	}
+}
+
+// openAPIPath converts the :param and *catchall segments of a route path to {param}
+func openAPIPath(p string) string {
+	var b strings.Builder
+	for _, param := range pattern.Params(p) {
+		i := strings.Index(p, param.String())
+		b.WriteString(p[:i])
+		b.WriteString("{" + param.Name + "}")
+		p = p[i+len(param.String()):]
+	}
+	b.WriteString(p)
+	return b.String()
+}
+
+// operationIDs returns the unique operationId of each of routes, or an empty string for
+// routes without a name
+func operationIDs(routes []RouteInfo) []string {
+	named := make(map[string]int)
+	for _, ri := range routes {
+		if ri.Name != "" && !ri.Mount {
+			named[ri.Name]++
+		}
+	}
+
+	ids := make([]string, len(routes))
+	seen := make(map[string]bool)
+	for i, ri := range routes {
+		if ri.Name == "" || ri.Mount {
+			continue
+		}
+		id := ri.Name
+		if named[ri.Name] > 1 {
+			id = strings.ToLower(ri.Method) + "." + ri.Name
+		}
+		// a prefixed name may still be taken by a route named after it
+		for n := 2; seen[id]; n++ {
+			id = fmt.Sprintf("%s.%s.%d", strings.ToLower(ri.Method), ri.Name, n)
+		}
+		seen[id] = true
+		ids[i] = id
+	}
+	return ids
+}
+
+// successStatus is the status of a successful response to a route
+func successStatus(ri RouteInfo) int {
+	switch {
+	case ri.Method == http.MethodPost:
+		return http.StatusCreated
+	case ri.Method == http.MethodDelete && ri.Response == nil:
+		return http.StatusNoContent
+	}
+	return http.StatusOK
+}
+
+func openAPIOperation(r *schema.Reflector, ri RouteInfo) *OpenAPIOperation {
+	status := successStatus(ri)
+	success := OpenAPIResponse{Description: http.StatusText(status)}
+	if ri.Response != nil {
+		success.Content = map[string]OpenAPIMediaType{
+			contentTypeJSON: {Schema: r.Reflect(ri.Response)},
+		}
+	}
+	op := &OpenAPIOperation{
+		Responses: map[string]OpenAPIResponse{
+			strconv.Itoa(status): success,
+			"default":            errorResponse("Error", openAPIErrorSchema),
+		},
+	}
+
+	urlParams := make(map[string]reflect.StructField)
+	for _, f := range parameterFields(ri.URLParams, "url") {
+		name, _ := schema.FieldName(f, "url")
+		urlParams[name] = f
+	}
+	for _, p := range ri.Params() {
+		param := OpenAPIParameter{Name: p.Name, In: "path", Required: true, Schema: &schema.Schema{Type: "string"}}
+		if f, ok := urlParams[p.Name]; ok && !p.CatchAll {
+			param.Schema = r.ReflectField(f)
+		}
+		op.Parameters = append(op.Parameters, param)
+	}
+	if len(op.Parameters) > 0 {
+		op.Responses["404"] = errorResponse(http.StatusText(http.StatusNotFound), openAPIErrorSchema)
+	}
+
+	op.Parameters = append(op.Parameters, openAPIParameters(r, ri.Query, "query", "query")...)
+	op.Parameters = append(op.Parameters, openAPIParameters(r, ri.Headers, "header", "header")...)
+	if ri.Query != nil || ri.Headers != nil || ri.Body != nil {
+		op.Responses["400"] = errorResponse(http.StatusText(http.StatusBadRequest), openAPIValidationErrorSchema)
+	}
+
+	if ri.Body != nil {
+		consumes := ri.Consumes
+		if len(consumes) == 0 {
+			consumes = []string{contentTypeJSON}
+		}
+		body := &OpenAPIRequestBody{Required: true, Content: make(map[string]OpenAPIMediaType)}
+		s := r.Reflect(ri.Body)
+		for _, ct := range consumes {
+			body.Content[ct] = OpenAPIMediaType{Schema: s}
+		}
+		op.RequestBody = body
+		op.Responses["415"] = errorResponse(http.StatusText(http.StatusUnsupportedMediaType), openAPIErrorSchema)
+	}
+	return op
+}
+
+func errorResponse(description, name string) OpenAPIResponse {
+	return OpenAPIResponse{
+		Description: description,
+		Content: map[string]OpenAPIMediaType{
+			contentTypeJSON: {Schema: &schema.Schema{Ref: openAPISchemasPrefix + name}},
+		},
+	}
+}
+
+// openAPIParameters describes the fields of the struct type t as parameters located in "in"
+func openAPIParameters(r *schema.Reflector, t reflect.Type, tagKey, in string) []OpenAPIParameter {
+	var params []OpenAPIParameter
+	for _, f := range parameterFields(t, tagKey) {
+		name, _ := schema.FieldName(f, tagKey)
+		params = append(params, OpenAPIParameter{
+			Name:     name,
+			In:       in,
+			Required: schema.HasRule(f.Tag.Get("validate"), "required"),
+			Schema:   r.ReflectField(f),
+		})
+	}
+	return params
+}
+
+// parameterFields returns the exported fields of the struct type t that are bound from the
+// values named by the tagKey tag in the same way as the bind package
+func parameterFields(t reflect.Type, tagKey string) []reflect.StructField {
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	var fields []reflect.StructField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if name, _ := schema.FieldName(f, tagKey); f.PkgPath != "" || name == "-" {
+			continue
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
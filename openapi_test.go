@@ -0,0 +1,263 @@
+package boar
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type openAPIAddress struct {
+	City string `json:"city" validate:"required"`
+}
+
+type openAPIUser struct {
+	Name      string            `json:"name" validate:"required,min=2,max=50"`
+	Email     string            `json:"email" validate:"omitempty,email"`
+	Age       int               `json:"age,omitempty" validate:"gte=18,lte=130"`
+	Role      string            `json:"role" validate:"oneof=admin member"`
+	Tags      []string          `json:"tags" validate:"max=5,dive,min=1"`
+	Address   *openAPIAddress   `json:"address"`
+	Previous  []openAPIAddress  `json:"previous"`
+	Labels    map[string]string `json:"labels"`
+	CreatedAt time.Time         `json:"createdAt"`
+	Ignored   string            `json:"-"`
+	internal  string
+}
+
+type createUserHandler struct {
+	Query struct {
+		DryRun bool     `query:"dry_run"`
+		Fields []string `query:"fields"`
+	}
+	URLParams struct {
+		OrgID int `url:"org" validate:"min=1"`
+	}
+	Headers struct {
+		RequestID string `header:"X-Request-ID" validate:"required,uuid"`
+	}
+	Body openAPIUser
+}
+
+func (h *createUserHandler) Handle(Context) error { return nil }
+
+func generateOpenAPI(t *testing.T, r *Router) map[string]interface{} {
+	b, err := json.Marshal(r.OpenAPI(OpenAPIInfo{Title: "Users", Version: "1.0.0"}))
+	require.NoError(t, err)
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal(b, &doc))
+	return doc
+}
+
+func jsonAt(t *testing.T, v interface{}, keys ...string) interface{} {
+	for _, k := range keys {
+		m, ok := v.(map[string]interface{})
+		require.True(t, ok, "%s is not an object", k)
+		v, ok = m[k]
+		require.True(t, ok, "missing %s", k)
+	}
+	return v
+}
+
+func TestOpenAPIDocument(t *testing.T) {
+	r := NewRouter()
+	r.Post("/orgs/:org/users", func(Context) (Handler, error) {
+		return &createUserHandler{}, nil
+	}, Name("users.create"), Handles(&createUserHandler{}))
+
+	doc := generateOpenAPI(t, r)
+	assert.Equal(t, OpenAPIVersion, doc["openapi"])
+	assert.Equal(t, "Users", jsonAt(t, doc, "info", "title"))
+
+	op := jsonAt(t, doc, "paths", "/orgs/{org}/users", "post")
+	assert.Equal(t, "users.create", jsonAt(t, op, "operationId"))
+
+	b, err := json.Marshal(jsonAt(t, op, "parameters"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"name": "org", "in": "path", "required": true, "schema": {"type": "integer", "format": "int64", "minimum": 1}},
+		{"name": "dry_run", "in": "query", "schema": {"type": "boolean"}},
+		{"name": "fields", "in": "query", "schema": {"type": "array", "items": {"type": "string"}}},
+		{"name": "X-Request-ID", "in": "header", "required": true, "schema": {"type": "string", "format": "uuid"}}
+	]`, string(b))
+
+	assert.Equal(t, "#/components/schemas/openAPIUser",
+		jsonAt(t, op, "requestBody", "content", "application/json", "schema", "$ref"))
+	for _, status := range []string{"201", "400", "404", "415", "default"} {
+		jsonAt(t, op, "responses", status)
+	}
+	assert.NotContains(t, jsonAt(t, op, "responses"), "200")
+	assert.Equal(t, "#/components/schemas/ValidationError",
+		jsonAt(t, op, "responses", "400", "content", "application/json", "schema", "$ref"))
+	assert.Equal(t, "#/components/schemas/Error",
+		jsonAt(t, op, "responses", "default", "content", "application/json", "schema", "$ref"))
+}
+
+func TestOpenAPIBodySchema(t *testing.T) {
+	r := NewRouter()
+	r.Post("/users", func(Context) (Handler, error) {
+		return &createUserHandler{}, nil
+	}, Handles(&createUserHandler{}))
+
+	doc := generateOpenAPI(t, r)
+	b, err := json.Marshal(jsonAt(t, doc, "components", "schemas", "openAPIUser"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"type": "object",
+		"required": ["name"],
+		"properties": {
+			"name": {"type": "string", "minLength": 2, "maxLength": 50},
+			"email": {"type": "string", "format": "email"},
+			"age": {"type": "integer", "format": "int64", "minimum": 18, "maximum": 130},
+			"role": {"type": "string", "enum": ["admin", "member"]},
+			"tags": {"type": "array", "maxItems": 5, "items": {"type": "string", "minLength": 1}},
+			"address": {"anyOf": [{"$ref": "#/components/schemas/openAPIAddress"}, {"type": "null"}]},
+			"previous": {"type": "array", "items": {"$ref": "#/components/schemas/openAPIAddress"}},
+			"labels": {"type": "object", "additionalProperties": {"type": "string"}},
+			"createdAt": {"type": "string", "format": "date-time"}
+		}
+	}`, string(b))
+
+	b, err = json.Marshal(jsonAt(t, doc, "components", "schemas", "openAPIAddress"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"type": "object", "required": ["city"], "properties": {"city": {"type": "string"}}}`, string(b))
+}
+
+type openAPINode struct {
+	Value    int            `json:"value"`
+	Children []*openAPINode `json:"children"`
+}
+
+type createTreeHandler struct {
+	createUserHandler
+	Body openAPINode
+}
+
+func TestOpenAPIRecursiveSchema(t *testing.T) {
+	r := NewRouter()
+	r.Post("/tree", func(Context) (Handler, error) {
+		return &createTreeHandler{}, nil
+	}, Handles(&createTreeHandler{}))
+
+	doc := generateOpenAPI(t, r)
+	assert.Equal(t, "#/components/schemas/openAPINode",
+		jsonAt(t, doc, "components", "schemas", "openAPINode", "properties", "children", "items", "anyOf").([]interface{})[0].(map[string]interface{})["$ref"])
+}
+
+func TestOpenAPIRoutesWithoutHandlerTypes(t *testing.T) {
+	r := NewRouter()
+	r.MethodFunc(http.MethodGet, "/files/*filepath", noopHandler)
+	r.MethodFunc(http.MethodGet, "/health", noopHandler)
+
+	doc := generateOpenAPI(t, r)
+	b, err := json.Marshal(jsonAt(t, doc, "paths", "/files/{filepath}", "get", "parameters"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"name": "filepath", "in": "path", "required": true, "schema": {"type": "string"}}]`, string(b))
+
+	op := jsonAt(t, doc, "paths", "/health", "get").(map[string]interface{})
+	assert.NotContains(t, op, "parameters")
+	assert.NotContains(t, op, "requestBody")
+	assert.NotContains(t, op["responses"], "400")
+}
+
+func TestOpenAPIConsumes(t *testing.T) {
+	r := NewRouter()
+	r.Post("/users", func(Context) (Handler, error) {
+		return &createUserHandler{}, nil
+	}, Handles(&createUserHandler{}), Consumes(contentTypeJSON, contentTypeFormEncoded))
+
+	doc := generateOpenAPI(t, r)
+	content := jsonAt(t, doc, "paths", "/users", "post", "requestBody", "content").(map[string]interface{})
+	assert.Contains(t, content, contentTypeJSON)
+	assert.Contains(t, content, contentTypeFormEncoded)
+}
+
+func TestOpenAPIResponseSchema(t *testing.T) {
+	r := NewRouter()
+	r.MethodFunc(http.MethodGet, "/users", noopHandler, Returns([]openAPIUser{}))
+	r.MethodFunc(http.MethodGet, "/health", noopHandler)
+
+	doc := generateOpenAPI(t, r)
+	b, err := json.Marshal(jsonAt(t, doc, "paths", "/users", "get", "responses", "200", "content", "application/json", "schema"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"type": "array", "items": {"$ref": "#/components/schemas/openAPIUser"}}`, string(b))
+	jsonAt(t, doc, "components", "schemas", "openAPIUser")
+
+	assert.NotContains(t, jsonAt(t, doc, "paths", "/health", "get", "responses", "200"), "content")
+}
+
+func TestOpenAPIOperationIDsOfSharedNames(t *testing.T) {
+	r := NewRouter()
+	r.MethodFunc(http.MethodGet, "/users/:id", noopHandler, Name("user"))
+	r.MethodFunc(http.MethodDelete, "/users/:id", noopHandler, Name("user"))
+	r.MethodFunc(http.MethodGet, "/users", noopHandler, Name("users"))
+
+	doc := generateOpenAPI(t, r)
+	assert.Equal(t, "get.user", jsonAt(t, doc, "paths", "/users/{id}", "get", "operationId"))
+	assert.Equal(t, "delete.user", jsonAt(t, doc, "paths", "/users/{id}", "delete", "operationId"))
+	assert.Equal(t, "users", jsonAt(t, doc, "paths", "/users", "get", "operationId"))
+}
+
+func TestOpenAPISkipsMounts(t *testing.T) {
+	r := NewRouter()
+	r.Mount("/legacy", http.NotFoundHandler(), Name("legacy"))
+	r.MethodFunc(http.MethodGet, "/health", noopHandler)
+
+	doc := generateOpenAPI(t, r)
+	paths := jsonAt(t, doc, "paths").(map[string]interface{})
+	assert.Len(t, paths, 1)
+	assert.Contains(t, paths, "/health")
+}
+
+func TestOpenAPISuccessStatus(t *testing.T) {
+	r := NewRouter()
+	r.MethodFunc(http.MethodPost, "/users", noopHandler, Returns(openAPIUser{}))
+	r.MethodFunc(http.MethodDelete, "/users/:id", noopHandler)
+	r.MethodFunc(http.MethodPut, "/users/:id", noopHandler)
+	r.MethodFunc(http.MethodDelete, "/orders/:id", noopHandler, Returns(openAPIUser{}))
+
+	doc := generateOpenAPI(t, r)
+	tests := []struct {
+		path, method, status string
+	}{
+		{"/users", "post", "201"},
+		{"/users/{id}", "delete", "204"},
+		{"/users/{id}", "put", "200"},
+		{"/orders/{id}", "delete", "200"},
+	}
+	for _, tt := range tests {
+		responses := jsonAt(t, doc, "paths", tt.path, tt.method, "responses").(map[string]interface{})
+		assert.Contains(t, responses, tt.status, tt.method+" "+tt.path)
+		for _, other := range []string{"200", "201", "204"} {
+			if other != tt.status {
+				assert.NotContains(t, responses, other, tt.method+" "+tt.path)
+			}
+		}
+	}
+	jsonAt(t, doc, "paths", "/users", "post", "responses", "201", "content", "application/json", "schema")
+}
+
+func TestOpenAPIPath(t *testing.T) {
+	assert.Equal(t, "/users/{id}/orders/{order}", openAPIPath("/users/:id/orders/:order"))
+	assert.Equal(t, "/static/{filepath}", openAPIPath("/static/*filepath"))
+	assert.Equal(t, "/health", openAPIPath("/health"))
+}
+
+func TestOpenAPIHandler(t *testing.T) {
+	r := NewRouter()
+	r.MethodFunc(http.MethodGet, "/openapi.json", r.OpenAPIHandler(OpenAPIInfo{Title: "Users", Version: "1.0.0"}))
+	r.MethodFunc(http.MethodGet, "/health", noopHandler)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, contentTypeJSON, rec.Header().Get("content-type"))
+
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &doc))
+	jsonAt(t, doc, "paths", "/health", "get")
+}
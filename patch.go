@@ -0,0 +1,492 @@
+package boar
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	contentTypeMergePatch = "application/merge-patch+json"
+	contentTypeJSONPatch  = "application/json-patch+json"
+
+	errNotAPatch       = errors.New("patch must be a JSON object or an array of operations")
+	errPatchTarget     = errors.New("patch can only be applied to a pointer to a struct")
+	errJSONPatchTarget = fmt.Errorf("%s can only be bound to a boar.Patch", contentTypeJSONPatch)
+)
+
+// Patch is a partial update read from a JSON Merge Patch (RFC 7396) or a JSON Patch
+// (RFC 6902) request body. Unlike decoding into a struct, a Patch knows which fields
+// were present in the request so that omitted fields can be told apart from fields
+// that were set to their zero value. Use it as the Body of PATCH handlers and Apply it
+// to the existing entity.
+//
+// Example:
+//
+//	type updateUser struct {
+//	    URLParams struct {
+//	        ID int `url:"id"`
+//	    }
+//	    Body boar.Patch
+//	}
+//
+//	func (h *updateUser) Handle(c boar.Context) error {
+//	    user, err := h.store.Get(h.URLParams.ID)
+//	    if err != nil {
+//	        return err
+//	    }
+//	    if err := h.Body.Apply(user); err != nil {
+//	        return err
+//	    }
+//	    return h.store.Save(user)
+//	}
+//
+// Requests with a content-type of application/json are treated as a merge patch when
+// the body is an object and as a JSON Patch when the body is an array
+type Patch struct {
+	merge  map[string]interface{}
+	ops    []PatchOperation
+	fields []string
+}
+
+// PatchOperation is a single operation of a JSON Patch document
+type PatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// IsMergePatch reports whether the patch is a JSON Merge Patch
+func (p *Patch) IsMergePatch() bool {
+	return p.merge != nil
+}
+
+// Operations returns the operations of a JSON Patch. It is empty for merge patches
+func (p *Patch) Operations() []PatchOperation {
+	return p.ops
+}
+
+// Fields returns the dotted JSON paths (e.g. address.city) that were present in the
+// patch. Array indexes are included as path segments
+func (p *Patch) Fields() []string {
+	return p.fields
+}
+
+// Has reports whether field, or any field nested within it, was present in the patch.
+// field is the dotted path using JSON names (e.g. address.city)
+func (p *Patch) Has(field string) bool {
+	for _, f := range p.fields {
+		if f == field || strings.HasPrefix(f, field+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// UnmarshalJSON reads a merge patch from an object or JSON Patch operations from an array
+func (p *Patch) UnmarshalJSON(b []byte) error {
+	switch b = bytes.TrimSpace(b); {
+	case len(b) > 0 && b[0] == '{':
+		return p.unmarshalMergePatch(b)
+	case len(b) > 0 && b[0] == '[':
+		return p.unmarshalJSONPatch(b)
+	default:
+		return errNotAPatch
+	}
+}
+
+func (p *Patch) unmarshalMergePatch(b []byte) error {
+	var merge map[string]interface{}
+	if err := json.Unmarshal(b, &merge); err != nil {
+		return err
+	}
+	if merge == nil {
+		return errNotAPatch
+	}
+	fields := mergeFields("", merge, nil)
+	sort.Strings(fields)
+	*p = Patch{
+		merge:  merge,
+		fields: fields,
+	}
+	return nil
+}
+
+func (p *Patch) unmarshalJSONPatch(b []byte) error {
+	var ops []PatchOperation
+	if err := json.Unmarshal(b, &ops); err != nil {
+		return err
+	}
+
+	fields := make([]string, 0, len(ops))
+	for i, op := range ops {
+		switch op.Op {
+		case "add", "replace", "test":
+			if op.Value == nil {
+				return fmt.Errorf("operation %d (%s) is missing a value", i, op.Op)
+			}
+		case "move", "copy":
+			if _, err := parsePointer(op.From); err != nil {
+				return fmt.Errorf("operation %d (%s): %v", i, op.Op, err)
+			}
+			if op.Op == "move" {
+				fields = append(fields, pointerField(op.From))
+			}
+		case "remove":
+		default:
+			return fmt.Errorf("operation %d has an unknown op %q", i, op.Op)
+		}
+		if _, err := parsePointer(op.Path); err != nil {
+			return fmt.Errorf("operation %d (%s): %v", i, op.Op, err)
+		}
+		if op.Op != "test" {
+			fields = append(fields, pointerField(op.Path))
+		}
+	}
+
+	*p = Patch{
+		ops:    ops,
+		fields: fields,
+	}
+	return nil
+}
+
+// Apply applies the patch to v, which must be a pointer to a struct. The patched
+// result is validated with the validate tags of the struct and v is only modified
+// when validation succeeds. A ValidationError is returned when the patched result
+// is invalid or the patch does not match the struct, an HTTPError with a status of
+// 409 Conflict when a JSON Patch test operation fails and 422 Unprocessable Entity when
+// an operation refers to a path which does not exist
+func (p *Patch) Apply(v interface{}) error {
+	target := reflect.ValueOf(v)
+	if target.Kind() != reflect.Ptr || target.Elem().Kind() != reflect.Struct {
+		return errPatchTarget
+	}
+
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var doc interface{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return err
+	}
+
+	if p.IsMergePatch() {
+		doc = mergePatch(doc, p.merge)
+	} else if doc, err = applyOperations(doc, p.ops); err != nil {
+		return err
+	}
+
+	if raw, err = json.Marshal(doc); err != nil {
+		return err
+	}
+
+	// fields which are invisible to JSON are preserved by starting with a copy of v
+	result := reflect.New(target.Elem().Type())
+	result.Elem().Set(target.Elem())
+	zeroJSONFields(result.Elem())
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(result.Interface()); err != nil {
+		return NewValidationError(bodyField, jsonError(dec, err))
+	}
+
+	if err := validate(bodyField, result.Interface()); err != nil {
+		return err
+	}
+	target.Elem().Set(result.Elem())
+	return nil
+}
+
+// mergePatch applies patch to target according to RFC 7396
+func mergePatch(target interface{}, patch interface{}) interface{} {
+	patchObj, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObj, ok := target.(map[string]interface{})
+	if !ok {
+		targetObj = map[string]interface{}{}
+	}
+	for k, v := range patchObj {
+		if v == nil {
+			delete(targetObj, k)
+			continue
+		}
+		targetObj[k] = mergePatch(targetObj[k], v)
+	}
+	return targetObj
+}
+
+func mergeFields(prefix string, obj map[string]interface{}, fields []string) []string {
+	for k, v := range obj {
+		field := k
+		if prefix != "" {
+			field = prefix + "." + k
+		}
+		fields = append(fields, field)
+		if nested, ok := v.(map[string]interface{}); ok {
+			fields = mergeFields(field, nested, fields)
+		}
+	}
+	return fields
+}
+
+// zeroJSONFields sets the exported fields of v which are visible to encoding/json to
+// their zero value
+func zeroJSONFields(v reflect.Value) {
+	typ := v.Type()
+	for i := 0; i < v.NumField(); i++ {
+		f := typ.Field(i)
+		if f.PkgPath != "" || f.Tag.Get("json") == "-" {
+			continue
+		}
+		v.Field(i).Set(reflect.Zero(f.Type))
+	}
+}
+
+// applyOperations applies JSON Patch operations to doc according to RFC 6902
+func applyOperations(doc interface{}, ops []PatchOperation) (interface{}, error) {
+	for i, op := range ops {
+		path, err := parsePointer(op.Path)
+		if err != nil {
+			return nil, NewValidationError(bodyField, err)
+		}
+
+		var value interface{}
+		if op.Value != nil {
+			if err := json.Unmarshal(op.Value, &value); err != nil {
+				return nil, NewValidationError(bodyField, err)
+			}
+		}
+
+		switch op.Op {
+		case "add":
+			doc, err = pointerAdd(doc, path, value)
+		case "remove":
+			doc, _, err = pointerRemove(doc, path)
+		case "replace":
+			if doc, _, err = pointerRemove(doc, path); err == nil {
+				doc, err = pointerAdd(doc, path, value)
+			}
+		case "move", "copy":
+			from, _ := parsePointer(op.From)
+			var moved interface{}
+			if op.Op == "move" {
+				doc, moved, err = pointerRemove(doc, from)
+			} else {
+				moved, err = pointerGet(doc, from)
+				moved = deepCopy(moved)
+			}
+			if err == nil {
+				doc, err = pointerAdd(doc, path, moved)
+			}
+		case "test":
+			var actual interface{}
+			if actual, err = pointerGet(doc, path); err == nil && !reflect.DeepEqual(actual, value) {
+				return nil, NewHTTPError(http.StatusConflict, fmt.Errorf("test failed for operation %d at %q", i, op.Path))
+			}
+		}
+		if err != nil {
+			return nil, NewHTTPError(http.StatusUnprocessableEntity, fmt.Errorf("operation %d (%s): %v", i, op.Op, err))
+		}
+	}
+	return doc, nil
+}
+
+// parsePointer parses a JSON Pointer (RFC 6901) into its unescaped reference tokens
+func parsePointer(ptr string) ([]string, error) {
+	if ptr == "" {
+		return []string{}, nil
+	}
+	if ptr[0] != '/' {
+		return nil, fmt.Errorf("invalid JSON pointer %q", ptr)
+	}
+	tokens := strings.Split(ptr[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.Replace(strings.Replace(t, "~1", "/", -1), "~0", "~", -1)
+	}
+	return tokens, nil
+}
+
+// pointerField converts a JSON Pointer into a dotted path
+func pointerField(ptr string) string {
+	tokens, _ := parsePointer(ptr)
+	return strings.Join(tokens, ".")
+}
+
+func pointerGet(doc interface{}, path []string) (interface{}, error) {
+	for _, token := range path {
+		switch node := doc.(type) {
+		case map[string]interface{}:
+			v, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("path %q does not exist", token)
+			}
+			doc = v
+		case []interface{}:
+			i, err := arrayIndex(token, len(node)-1)
+			if err != nil {
+				return nil, err
+			}
+			doc = node[i]
+		default:
+			return nil, fmt.Errorf("path %q does not exist", token)
+		}
+	}
+	return doc, nil
+}
+
+func pointerAdd(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	parent, err := pointerGet(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+
+	token := path[len(path)-1]
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		node[token] = value
+		return doc, nil
+	case []interface{}:
+		i := len(node)
+		if token != "-" {
+			if i, err = arrayIndex(token, len(node)); err != nil {
+				return nil, err
+			}
+		}
+		node = append(node, nil)
+		copy(node[i+1:], node[i:])
+		node[i] = value
+		return pointerReplaceParent(doc, path[:len(path)-1], node)
+	default:
+		return nil, fmt.Errorf("cannot add %q to a %T", token, parent)
+	}
+}
+
+func pointerRemove(doc interface{}, path []string) (interface{}, interface{}, error) {
+	if len(path) == 0 {
+		return nil, doc, nil
+	}
+	parent, err := pointerGet(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	token := path[len(path)-1]
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		removed, ok := node[token]
+		if !ok {
+			return nil, nil, fmt.Errorf("path %q does not exist", token)
+		}
+		delete(node, token)
+		return doc, removed, nil
+	case []interface{}:
+		i, err := arrayIndex(token, len(node)-1)
+		if err != nil {
+			return nil, nil, err
+		}
+		removed := node[i]
+		node = append(node[:i:i], node[i+1:]...)
+		doc, err = pointerReplaceParent(doc, path[:len(path)-1], node)
+		return doc, removed, err
+	default:
+		return nil, nil, fmt.Errorf("path %q does not exist", token)
+	}
+}
+
+// pointerReplaceParent replaces the array at path because appending to or removing from
+// a slice may create a new slice
+func pointerReplaceParent(doc interface{}, path []string, node []interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return node, nil
+	}
+	parent, err := pointerGet(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	token := path[len(path)-1]
+	switch p := parent.(type) {
+	case map[string]interface{}:
+		p[token] = node
+	case []interface{}:
+		i, err := arrayIndex(token, len(p)-1)
+		if err != nil {
+			return nil, err
+		}
+		p[i] = node
+	}
+	return doc, nil
+}
+
+func arrayIndex(token string, max int) (int, error) {
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || i > max || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	return i, nil
+}
+
+func deepCopy(v interface{}) interface{} {
+	switch node := v.(type) {
+	case map[string]interface{}:
+		cp := make(map[string]interface{}, len(node))
+		for k, v := range node {
+			cp[k] = deepCopy(v)
+		}
+		return cp
+	case []interface{}:
+		cp := make([]interface{}, len(node))
+		for i, v := range node {
+			cp[i] = deepCopy(v)
+		}
+		return cp
+	default:
+		return v
+	}
+}
+
+func decodeMergePatch(c Context, v interface{}) error {
+	p, ok := v.(*Patch)
+	if !ok {
+		// merging into an empty struct is the same as decoding into it
+		return c.ReadJSON(v)
+	}
+	var raw json.RawMessage
+	if err := c.ReadJSON(&raw); err != nil {
+		return err
+	}
+	if b := bytes.TrimSpace(raw); len(b) == 0 || b[0] != '{' {
+		return NewValidationError(bodyField, errNotAPatch)
+	}
+	return p.UnmarshalJSON(raw)
+}
+
+func decodeJSONPatch(c Context, v interface{}) error {
+	p, ok := v.(*Patch)
+	if !ok {
+		return NewHTTPError(http.StatusUnsupportedMediaType, errJSONPatchTarget)
+	}
+	var raw json.RawMessage
+	if err := c.ReadJSON(&raw); err != nil {
+		return err
+	}
+	if b := bytes.TrimSpace(raw); len(b) == 0 || b[0] != '[' {
+		return NewValidationError(bodyField, errNotAPatch)
+	}
+	return p.UnmarshalJSON(raw)
+}
@@ -0,0 +1,219 @@
+package boar
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type patchAddress struct {
+	City string `json:"city"`
+	Zip  string `json:"zip"`
+}
+
+type patchUser struct {
+	ID       int          `json:"-"`
+	Name     string       `json:"name" validate:"required"`
+	Age      int          `json:"age" validate:"gte=0"`
+	Tags     []string     `json:"tags"`
+	Address  patchAddress `json:"address"`
+	Nickname *string      `json:"nickname,omitempty"`
+	secret   string
+}
+
+func newPatchUser() *patchUser {
+	nick := "b"
+	return &patchUser{
+		ID:       1,
+		Name:     "brett",
+		Age:      30,
+		Tags:     []string{"a", "b"},
+		Address:  patchAddress{City: "Austin", Zip: "78701"},
+		Nickname: &nick,
+		secret:   "shh",
+	}
+}
+
+func TestMergePatchRecordsPresentFields(t *testing.T) {
+	var p Patch
+	require.NoError(t, p.UnmarshalJSON([]byte(`{"age": 0, "address": {"city": "Dallas"}}`)))
+
+	assert.True(t, p.IsMergePatch())
+	assert.True(t, p.Has("age"))
+	assert.True(t, p.Has("address"))
+	assert.True(t, p.Has("address.city"))
+	assert.False(t, p.Has("address.zip"))
+	assert.False(t, p.Has("name"))
+}
+
+func TestMergePatchApply(t *testing.T) {
+	var p Patch
+	require.NoError(t, p.UnmarshalJSON([]byte(`{"age": 0, "address": {"city": "Dallas"}, "nickname": null}`)))
+
+	user := newPatchUser()
+	require.NoError(t, p.Apply(user))
+
+	assert.Equal(t, 1, user.ID)
+	assert.Equal(t, "brett", user.Name)
+	assert.Equal(t, 0, user.Age)
+	assert.Equal(t, []string{"a", "b"}, user.Tags)
+	assert.Equal(t, patchAddress{City: "Dallas", Zip: "78701"}, user.Address)
+	assert.Nil(t, user.Nickname)
+	assert.Equal(t, "shh", user.secret)
+}
+
+func TestMergePatchApplyValidatesResult(t *testing.T) {
+	var p Patch
+	require.NoError(t, p.UnmarshalJSON([]byte(`{"name": null}`)))
+
+	user := newPatchUser()
+	err := p.Apply(user)
+	require.IsType(t, &ValidationError{}, err)
+	assert.Equal(t, "brett", user.Name, "target should not be modified when invalid")
+}
+
+func TestMergePatchApplyRejectsUnknownFields(t *testing.T) {
+	var p Patch
+	require.NoError(t, p.UnmarshalJSON([]byte(`{"admin": true}`)))
+
+	err := p.Apply(newPatchUser())
+	assert.IsType(t, &ValidationError{}, err)
+}
+
+func TestJSONPatchApply(t *testing.T) {
+	var p Patch
+	require.NoError(t, p.UnmarshalJSON([]byte(`[
+		{"op": "test", "path": "/name", "value": "brett"},
+		{"op": "replace", "path": "/age", "value": 31},
+		{"op": "add", "path": "/tags/1", "value": "x"},
+		{"op": "add", "path": "/tags/-", "value": "z"},
+		{"op": "remove", "path": "/tags/0"},
+		{"op": "copy", "from": "/address/city", "path": "/address/zip"},
+		{"op": "move", "from": "/nickname", "path": "/name"}
+	]`)))
+
+	assert.False(t, p.IsMergePatch())
+	assert.Len(t, p.Operations(), 7)
+	assert.True(t, p.Has("tags"))
+	assert.True(t, p.Has("nickname"))
+	assert.False(t, p.Has("address.city"))
+
+	user := newPatchUser()
+	require.NoError(t, p.Apply(user))
+
+	assert.Equal(t, "b", user.Name)
+	assert.Equal(t, 31, user.Age)
+	assert.Equal(t, []string{"x", "b", "z"}, user.Tags)
+	assert.Equal(t, patchAddress{City: "Austin", Zip: "Austin"}, user.Address)
+	assert.Nil(t, user.Nickname)
+}
+
+func TestJSONPatchApplyFailedTestIsConflict(t *testing.T) {
+	var p Patch
+	require.NoError(t, p.UnmarshalJSON([]byte(`[{"op": "test", "path": "/age", "value": 1}]`)))
+
+	err := p.Apply(newPatchUser())
+	require.Implements(t, (*HTTPError)(nil), err)
+	assert.Equal(t, http.StatusConflict, err.(HTTPError).Status())
+}
+
+func TestJSONPatchApplyMissingPathIsUnprocessable(t *testing.T) {
+	var p Patch
+	require.NoError(t, p.UnmarshalJSON([]byte(`[{"op": "remove", "path": "/address/street"}]`)))
+
+	err := p.Apply(newPatchUser())
+	require.Implements(t, (*HTTPError)(nil), err)
+	assert.Equal(t, http.StatusUnprocessableEntity, err.(HTTPError).Status())
+}
+
+func TestJSONPatchRejectsInvalidOperations(t *testing.T) {
+	for _, body := range []string{
+		`[{"op": "explode", "path": "/name"}]`,
+		`[{"op": "add", "path": "/name"}]`,
+		`[{"op": "move", "from": "name", "path": "/name"}]`,
+		`[{"op": "remove", "path": "name"}]`,
+		`"hello"`,
+	} {
+		var p Patch
+		assert.Error(t, p.UnmarshalJSON([]byte(body)), body)
+	}
+}
+
+func TestPatchApplyRequiresStructPointer(t *testing.T) {
+	var p Patch
+	require.NoError(t, p.UnmarshalJSON([]byte(`{}`)))
+	assert.Equal(t, errPatchTarget, p.Apply(patchUser{}))
+}
+
+func TestParsePointerUnescapes(t *testing.T) {
+	tokens, err := parsePointer("/a~1b/c~0d")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a/b", "c~d"}, tokens)
+}
+
+type patchHandler struct {
+	handle HandlerFunc
+	Body   Patch
+}
+
+func (h *patchHandler) Handle(c Context) error { return h.handle(c) }
+
+func servePatch(t *testing.T, contentType, body string) (*patchUser, *httptest.ResponseRecorder) {
+	r := NewRouter()
+	user := newPatchUser()
+
+	h := &patchHandler{}
+	h.handle = func(c Context) error {
+		if err := h.Body.Apply(user); err != nil {
+			return err
+		}
+		return c.WriteJSON(http.StatusOK, user)
+	}
+	r.Patch("/users/:id", func(Context) (Handler, error) {
+		return h, nil
+	})
+
+	req := httptest.NewRequest(http.MethodPatch, "/users/1", bytes.NewBufferString(body))
+	req.Header.Set("content-type", contentType)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return user, rec
+}
+
+func TestPatchBodyWithMergePatchContentType(t *testing.T) {
+	user, rec := servePatch(t, contentTypeMergePatch, `{"age": 40}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, 40, user.Age)
+	assert.Equal(t, "brett", user.Name)
+}
+
+func TestPatchBodyWithJSONPatchContentType(t *testing.T) {
+	user, rec := servePatch(t, contentTypeJSONPatch, `[{"op": "replace", "path": "/name", "value": "kristy"}]`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "kristy", user.Name)
+}
+
+func TestPatchBodyRejectsMismatchedContentType(t *testing.T) {
+	_, rec := servePatch(t, contentTypeJSONPatch, `{"name": "kristy"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestPatchBodyValidationErrorIsBadRequest(t *testing.T) {
+	_, rec := servePatch(t, contentTypeMergePatch, `{"age": -1}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestJSONPatchCannotBindToStruct(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPatch, "/", bytes.NewBufferString(`[]`))
+	req.Header.Set("content-type", contentTypeJSONPatch)
+	c := NewContext(req, nil, nil)
+
+	var user patchUser
+	err := c.Bind(&user)
+	require.Implements(t, (*HTTPError)(nil), err)
+	assert.Equal(t, http.StatusUnsupportedMediaType, err.(HTTPError).Status())
+}
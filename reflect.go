@@ -6,23 +6,23 @@ import (
 	"net/http"
 	"net/url"
 	"reflect"
-	"strings"
 
 	"github.com/blockloop/boar/bind"
 	"github.com/julienschmidt/httprouter"
-	"gopkg.in/go-playground/validator.v9"
 )
 
 const (
 	queryField     = "Query"
 	urlParamsField = "URLParams"
+	headersField   = "Headers"
 	bodyField      = "Body"
 )
 
 var (
 	// MultiPartFormMaxMemory says how much memory to send to (*http.Request).ParseMultipartForm
-	// Default is 2MB
-	MultiPartFormMaxMemory = int64(1 << 20) // 2MB
+	// Default is 1MB. Use the StreamBody RouteOption and Context.MultipartReader for uploads
+	// which should not be buffered at all
+	MultiPartFormMaxMemory = int64(1 << 20) // 1MB
 
 	errNotAStruct    = errors.New("not a struct")
 	errNotSettable   = errors.New("not settable")
@@ -32,9 +32,23 @@ var (
 	contentTypeFormEncoded   = "application/x-www-form-urlencoded"
 	contentTypeMultipartForm = "multipart/form-data"
 
-	validateImpl = validator.New()
+	validateImpl = newValidator()
 )
 
+// QueryBinder is implemented by handlers that populate their own Query field from the query
+// string, such as those generated by cmd/boargen. BindQuery is called in place of binding
+// the field with reflection and the field is still validated afterwards
+type QueryBinder interface {
+	BindQuery(url.Values) error
+}
+
+// ParamsBinder is implemented by handlers that populate their own URLParams field from the
+// url parameters, such as those generated by cmd/boargen. BindParams is called in place of
+// binding the field with reflection and the field is still validated afterwards
+type ParamsBinder interface {
+	BindParams(httprouter.Params) error
+}
+
 func checkField(field reflect.Value) (bool, error) {
 	if !field.IsValid() {
 		return false, nil
@@ -67,6 +81,27 @@ func setQuery(handler reflect.Value, qs url.Values) error {
 	return validate(queryField, field.Addr().Interface())
 }
 
+// bindQuery populates the Query field of handler with b in place of setQuery. The field is
+// not validated when handler has no Query field
+func bindQuery(b QueryBinder, handler reflect.Value, qs url.Values) error {
+	field := handler.FieldByName(queryField)
+	ok, err := checkField(field)
+	if err != nil {
+		return &badFieldError{
+			field:   queryField,
+			handler: handler,
+			err:     err,
+		}
+	}
+	if err := b.BindQuery(qs); err != nil {
+		return NewValidationError(queryField, err)
+	}
+	if !ok {
+		return nil
+	}
+	return validate(queryField, field.Addr().Interface())
+}
+
 func setURLParams(handler reflect.Value, params httprouter.Params) error {
 	field := handler.FieldByName(urlParamsField)
 	ok, err := checkField(field)
@@ -81,14 +116,64 @@ func setURLParams(handler reflect.Value, params httprouter.Params) error {
 		}
 	}
 	if err := bind.ParamsValue(field, params); err != nil {
-		if tme, ok := err.(*bind.TypeMismatchError); ok {
-			return NewValidationError(urlParamsField, tme)
+		return paramsError(err)
+	}
+	return validate(urlParamsField, field.Addr().Interface())
+}
+
+// bindParams populates the URLParams field of handler with b in place of setURLParams. The
+// field is not validated when handler has no URLParams field
+func bindParams(b ParamsBinder, handler reflect.Value, params httprouter.Params) error {
+	field := handler.FieldByName(urlParamsField)
+	ok, err := checkField(field)
+	if err != nil {
+		return &badFieldError{
+			field:   urlParamsField,
+			handler: handler,
+			err:     err,
 		}
-		return err
+	}
+	if err := b.BindParams(params); err != nil {
+		return paramsError(err)
+	}
+	if !ok {
+		return nil
 	}
 	return validate(urlParamsField, field.Addr().Interface())
 }
 
+func paramsError(err error) error {
+	if tme, ok := err.(*bind.TypeMismatchError); ok {
+		return NewValidationError(urlParamsField, tme)
+	}
+	return err
+}
+
+// setHeaders populates the Headers field of handler. Handlers may already use a Headers
+// field that is not a struct, such as an http.Header, for their own purposes, so only
+// struct fields are bound
+func setHeaders(handler reflect.Value, h http.Header) error {
+	field := handler.FieldByName(headersField)
+	if field.IsValid() && field.Kind() != reflect.Struct {
+		return nil
+	}
+	ok, err := checkField(field)
+	if !ok {
+		if err == nil {
+			return nil
+		}
+		return &badFieldError{
+			field:   headersField,
+			handler: handler,
+			err:     err,
+		}
+	}
+	if err := bind.HeaderValue(field, h); err != nil {
+		return NewValidationError(headersField, err)
+	}
+	return validate(headersField, field.Addr().Interface())
+}
+
 func setBody(handler reflect.Value, c Context) error {
 	field := handler.FieldByName(bodyField)
 	ok, err := checkField(field)
@@ -102,36 +187,15 @@ func setBody(handler reflect.Value, c Context) error {
 			err:     err,
 		}
 	}
-	binder, err := getBinder(c)
-	if err != nil {
-		return NewHTTPError(http.StatusBadRequest, err)
-	}
-
-	if err := binder(field.Addr().Interface()); err != nil {
+	if err := c.Bind(field.Addr().Interface()); err != nil {
+		if _, ok := err.(HTTPError); ok {
+			return err
+		}
 		return NewValidationError(bodyField, err)
 	}
 	return validate(bodyField, field.Addr().Interface())
 }
 
-type binderFunc func(interface{}) error
-
-func getBinder(c Context) (binderFunc, error) {
-	ct := c.Request().Header.Get("content-type")
-	switch ct {
-	case "":
-		return nil, errNoContentType
-	case contentTypeJSON:
-		return c.ReadJSON, nil
-	case contentTypeFormEncoded:
-		return c.ReadForm, c.Request().ParseForm()
-	default:
-		if strings.HasPrefix(ct, contentTypeMultipartForm) {
-			return c.ReadForm, c.Request().ParseMultipartForm(MultiPartFormMaxMemory)
-		}
-		return nil, fmt.Errorf("unknown content type: %q", ct)
-	}
-}
-
 func validate(fieldName string, v interface{}) error {
 	if err := validateImpl.Struct(v); err != nil {
 		return NewValidationErrors(fieldName, []error{err})
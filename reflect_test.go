@@ -183,7 +183,7 @@ func TestSetBodyShouldReturnValidationErrorWhenCheckFieldFails(t *testing.T) {
 	assert.Contains(t, err.Error(), bodyField)
 }
 
-func TestSetBodyShouldReturnValidationErrorWhenReadJSONFails(t *testing.T) {
+func TestSetBodyShouldReturnValidationErrorWhenBindFails(t *testing.T) {
 	var handler struct {
 		Body struct {
 			Age int
@@ -194,10 +194,7 @@ func TestSetBodyShouldReturnValidationErrorWhenReadJSONFails(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 	mc := NewMockContext(ctrl)
-	mc.EXPECT().ReadJSON(gomock.Any()).Return(expErr)
-	req := httptest.NewRequest("GET", "/", bytes.NewBufferString("{}"))
-	req.Header.Set("content-type", contentTypeJSON)
-	mc.EXPECT().Request().Return(req)
+	mc.EXPECT().Bind(gomock.Any()).Return(expErr)
 
 	err := setBody(reflect.Indirect(reflect.ValueOf(&handler)), mc)
 	if !assert.IsType(t, &ValidationError{}, err) {
@@ -216,11 +213,7 @@ func TestSetBodyShouldReturnValidationErrorWhenValidationFails(t *testing.T) {
 	defer ctrl.Finish()
 	mc := NewMockContext(ctrl)
 
-	req := httptest.NewRequest("GET", "/", bytes.NewBufferString("{}"))
-	req.Header.Set("content-type", contentTypeJSON)
-	mc.EXPECT().Request().Return(req)
-
-	mc.EXPECT().ReadJSON(gomock.Any()).Do(func(v interface{}) {
+	mc.EXPECT().Bind(gomock.Any()).Do(func(v interface{}) {
 		json.Unmarshal([]byte(`{"Name": "1234"}`), v)
 	}).Return(nil)
 
@@ -236,12 +229,9 @@ func TestSetBodyShouldReturnErrorWhenUnknownContentType(t *testing.T) {
 		}
 	}
 
-	request := httptest.NewRequest("POST", "/", bytes.NewBufferString(`<xml></xml>`))
-	request.Header.Set("content-type", "application/xml")
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
-	mc := NewMockContext(ctrl)
-	mc.EXPECT().Request().Return(request)
+	request := httptest.NewRequest("POST", "/", bytes.NewBufferString(`name: brett`))
+	request.Header.Set("content-type", "application/yaml")
+	mc := NewContext(request, nil, nil)
 
 	err := setBody(reflect.Indirect(reflect.ValueOf(&handler)), mc)
 	require.Error(t, err)
@@ -254,16 +244,13 @@ func TestSetBodyUnknownContentTypeErrorShouldExplain(t *testing.T) {
 		}
 	}
 
-	request := httptest.NewRequest("POST", "/", bytes.NewBufferString(`<xml></xml>`))
-	request.Header.Set("content-type", "application/xml")
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
-	mc := NewMockContext(ctrl)
-	mc.EXPECT().Request().Return(request)
+	request := httptest.NewRequest("POST", "/", bytes.NewBufferString(`name: brett`))
+	request.Header.Set("content-type", "application/yaml")
+	mc := NewContext(request, nil, nil)
 
 	err := setBody(reflect.Indirect(reflect.ValueOf(&handler)), mc)
 	require.NotNil(t, err)
-	assert.Contains(t, err.Error(), "application/xml")
+	assert.Contains(t, err.Error(), "application/yaml")
 }
 
 func TestSetBodyShouldRequireContentType(t *testing.T) {
@@ -274,10 +261,7 @@ func TestSetBodyShouldRequireContentType(t *testing.T) {
 	}
 
 	request := httptest.NewRequest("POST", "/", nil)
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
-	mc := NewMockContext(ctrl)
-	mc.EXPECT().Request().Return(request)
+	mc := NewContext(request, nil, nil)
 
 	err := setBody(reflect.Indirect(reflect.ValueOf(&handler)), mc)
 	assert.IsType(t, &httpError{}, err)
@@ -330,9 +314,189 @@ brett
 	assert.Equal(t, "brett", handler.Body.Name)
 }
 
+func TestSetBodyShouldParseJSONWithCharsetParameter(t *testing.T) {
+	var handler struct {
+		Body struct {
+			Name string
+		}
+	}
+
+	request := httptest.NewRequest("POST", "/", bytes.NewBufferString(`{"Name": "brett"}`))
+	request.Header.Set("content-type", "application/json; charset=utf-8")
+
+	err := setBody(reflect.Indirect(reflect.ValueOf(&handler)), NewContext(request, nil, nil))
+	require.NoError(t, err)
+	assert.Equal(t, "brett", handler.Body.Name)
+}
+
+func TestSetBodyShouldParseJSONSuffixContentType(t *testing.T) {
+	var handler struct {
+		Body struct {
+			Name string
+		}
+	}
+
+	request := httptest.NewRequest("POST", "/", bytes.NewBufferString(`{"Name": "brett"}`))
+	request.Header.Set("content-type", "application/vnd.api+json")
+
+	err := setBody(reflect.Indirect(reflect.ValueOf(&handler)), NewContext(request, nil, nil))
+	require.NoError(t, err)
+	assert.Equal(t, "brett", handler.Body.Name)
+}
+
+func TestSetBodyShouldTranscodeFormCharset(t *testing.T) {
+	var handler struct {
+		Body struct {
+			Name string
+		}
+	}
+
+	request := httptest.NewRequest("POST", "/", bytes.NewBuffer([]byte{'N', 'a', 'm', 'e', '=', 'J', 0xF6, 'r', 'g'}))
+	request.Header.Set("content-type", contentTypeFormEncoded+"; charset=ISO-8859-1")
+
+	err := setBody(reflect.Indirect(reflect.ValueOf(&handler)), NewContext(request, nil, nil))
+	require.NoError(t, err)
+	assert.Equal(t, "Jörg", handler.Body.Name)
+}
+
+func TestSetBodyShouldReturnUnsupportedMediaTypeWhenUnknownContentType(t *testing.T) {
+	var handler struct {
+		Body struct {
+			Name string
+		}
+	}
+
+	request := httptest.NewRequest("POST", "/", bytes.NewBufferString(`name,age`))
+	request.Header.Set("content-type", "text/csv")
+
+	err := setBody(reflect.Indirect(reflect.ValueOf(&handler)), NewContext(request, nil, nil))
+	require.Implements(t, (*HTTPError)(nil), err)
+	assert.Equal(t, http.StatusUnsupportedMediaType, err.(HTTPError).Status())
+}
+
+func TestSetBodyShouldReturnUnsupportedMediaTypeWhenUnknownCharset(t *testing.T) {
+	var handler struct {
+		Body struct {
+			Name string
+		}
+	}
+
+	request := httptest.NewRequest("POST", "/", bytes.NewBufferString(`{}`))
+	request.Header.Set("content-type", "application/json; charset=shift_jis")
+
+	err := setBody(reflect.Indirect(reflect.ValueOf(&handler)), NewContext(request, nil, nil))
+	require.Implements(t, (*HTTPError)(nil), err)
+	assert.Equal(t, http.StatusUnsupportedMediaType, err.(HTTPError).Status())
+	assert.Contains(t, err.Error(), "shift_jis")
+}
+
+func TestSetBodyShouldReturnBadRequestWhenMalformedContentType(t *testing.T) {
+	var handler struct {
+		Body struct {
+			Name string
+		}
+	}
+
+	request := httptest.NewRequest("POST", "/", bytes.NewBufferString(`{}`))
+	request.Header.Set("content-type", "application/json; charset")
+
+	err := setBody(reflect.Indirect(reflect.ValueOf(&handler)), NewContext(request, nil, nil))
+	require.Implements(t, (*HTTPError)(nil), err)
+	assert.Equal(t, http.StatusBadRequest, err.(HTTPError).Status())
+}
+
 func TestValidateShouldErrorWhenBadValue(t *testing.T) {
 	var f string
 
 	err := validate("", &f)
 	require.Error(t, err)
 }
+
+func TestSetBodyShouldParseXMLContentTypes(t *testing.T) {
+	for _, ct := range []string{contentTypeXML, contentTypeTextXML, "application/atom+xml; charset=utf-8"} {
+		var handler struct {
+			Body struct {
+				Name string `xml:"name"`
+			}
+		}
+
+		request := httptest.NewRequest("POST", "/", bytes.NewBufferString(`<body><name>brett</name></body>`))
+		request.Header.Set("content-type", ct)
+
+		err := setBody(reflect.Indirect(reflect.ValueOf(&handler)), NewContext(request, nil, nil))
+		require.NoError(t, err, ct)
+		assert.Equal(t, "brett", handler.Body.Name, ct)
+	}
+}
+
+func TestSetBodyShouldParseXMLWithDeclaredEncoding(t *testing.T) {
+	for _, ct := range []string{contentTypeXML, contentTypeXML + "; charset=iso-8859-1"} {
+		var handler struct {
+			Body struct {
+				Name string `xml:"name"`
+			}
+		}
+
+		body := append([]byte(`<?xml version="1.0" encoding="ISO-8859-1"?><body><name>`), 'J', 'o', 's', 0xE9, 'e')
+		body = append(body, []byte(`</name></body>`)...)
+		request := httptest.NewRequest("POST", "/", bytes.NewBuffer(body))
+		request.Header.Set("content-type", ct)
+
+		err := setBody(reflect.Indirect(reflect.ValueOf(&handler)), NewContext(request, nil, nil))
+		require.NoError(t, err, ct)
+		assert.Equal(t, "Josée", handler.Body.Name, ct)
+	}
+}
+
+func TestSetBodyShouldRejectXMLWithUnknownDeclaredEncoding(t *testing.T) {
+	var handler struct {
+		Body struct {
+			Name string `xml:"name"`
+		}
+	}
+
+	request := httptest.NewRequest("POST", "/", bytes.NewBufferString(`<?xml version="1.0" encoding="Shift_JIS"?><body/>`))
+	request.Header.Set("content-type", contentTypeXML)
+
+	err := setBody(reflect.Indirect(reflect.ValueOf(&handler)), NewContext(request, nil, nil))
+	require.Error(t, err)
+	assert.Equal(t, http.StatusBadRequest, err.(HTTPError).Status())
+}
+
+func TestSetHeadersPopulatesField(t *testing.T) {
+	var handler struct {
+		Headers struct {
+			RequestID string `header:"x-request-id" validate:"required"`
+		}
+	}
+
+	h := http.Header{}
+	h.Set("X-Request-Id", "abc")
+	err := setHeaders(reflect.Indirect(reflect.ValueOf(&handler)), h)
+	require.NoError(t, err)
+	assert.Equal(t, "abc", handler.Headers.RequestID)
+}
+
+func TestSetHeadersShouldReturnValidationErrorWhenValidationFails(t *testing.T) {
+	var handler struct {
+		Headers struct {
+			RequestID string `header:"x-request-id" validate:"required"`
+		}
+	}
+
+	err := setHeaders(reflect.Indirect(reflect.ValueOf(&handler)), http.Header{})
+	require.Error(t, err)
+	assert.IsType(t, &ValidationError{}, err)
+}
+
+func TestSetHeadersIgnoresFieldsThatAreNotStructs(t *testing.T) {
+	var handler struct {
+		Headers http.Header
+	}
+
+	h := http.Header{}
+	h.Set("X-Request-Id", "abc")
+	err := setHeaders(reflect.Indirect(reflect.ValueOf(&handler)), h)
+	require.NoError(t, err)
+	assert.Nil(t, handler.Headers)
+}
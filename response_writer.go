@@ -1,14 +1,40 @@
 package boar
 
 import (
+	"bufio"
 	"bytes"
+	"fmt"
 	"io"
+	"io/ioutil"
+	"net"
 	"net/http"
+	"os"
 	"sync"
 )
 
+// BufferOptions limit how much of a buffered response is held in memory
+type BufferOptions struct {
+	// MemoryThreshold is the number of bytes of the response body held in memory. Once
+	// the body grows beyond it, the body is moved to a temporary file and the rest of the
+	// response is written there. Zero means the body is always held in memory
+	MemoryThreshold int64
+
+	// MaxSize is the maximum size of a buffered response body. Writes beyond it discard
+	// the buffered body and fail with ErrResponseTooLarge, which is then sent to the client
+	// by the ErrorHandler. Zero means there is no limit. Streamed responses are not limited
+	MaxSize int64
+
+	// TempDir is the directory temporary files are created in. The default directory
+	// for temporary files (see os.TempDir) is used when it is empty
+	TempDir string
+}
+
 // ResponseWriter is an http.ResponseWriter that captures the status code and body
-// written for retrieval after the response has been sent
+// written for retrieval after the response has been sent.
+//
+// A ResponseWriter is not an http.Flusher, because its Flush returns an error, so
+// middleware which type-asserts c.Response().(http.Flusher) finds no flusher and cannot
+// stream. Such code should be given BufferedResponseWriter.HTTPResponseWriter instead
 type ResponseWriter interface {
 	http.ResponseWriter
 	io.Closer
@@ -16,9 +42,24 @@ type ResponseWriter interface {
 	Flush() error
 	Status() int
 	Len() int
+
+	// Stream stops buffering the response. All subsequent writes are sent straight to the
+	// client, preceded by the status and headers and anything that has already been written.
+	// Calling Flush while streaming pushes written data to the client
+	Stream() error
 }
 
-var _ ResponseWriter = (*BufferedResponseWriter)(nil)
+var (
+	_ ResponseWriter = (*BufferedResponseWriter)(nil)
+	_ http.Hijacker  = (*BufferedResponseWriter)(nil)
+	_ http.Pusher    = (*BufferedResponseWriter)(nil)
+	_ io.ReaderFrom  = (*BufferedResponseWriter)(nil)
+
+	_ http.Flusher  = httpResponseWriter{}
+	_ http.Hijacker = httpResponseWriter{}
+	_ http.Pusher   = httpResponseWriter{}
+	_ io.ReaderFrom = httpResponseWriter{}
+)
 
 // BufferedResponseWriter is an http.ResponseWriter that captures the status code and body
 // written for retrieval after the response has been sent
@@ -28,6 +69,20 @@ type BufferedResponseWriter struct {
 	body      *bytes.Buffer
 	status    int
 	flushOnce *sync.Once
+	length    int
+	streaming bool
+	// committed is set once the status and headers have been written to base
+	committed bool
+	// streamOnWrite begins streaming on the first call to Write or WriteHeader
+	streamOnWrite bool
+
+	opts BufferOptions
+	// file holds the body once it has grown beyond opts.MemoryThreshold
+	file *os.File
+	// overflow is set once the body has grown beyond opts.MaxSize
+	overflow bool
+	// hijacked is set once the connection has been taken over by Hijack
+	hijacked bool
 }
 
 // NewBufferedResponseWriter creates a new BufferedResponseWriter
@@ -47,24 +102,168 @@ func NewBufferedResponseWriter(base http.ResponseWriter) *BufferedResponseWriter
 // Flush is called internally by the Router once all middlewares, handlers, and error handlers
 // have completely executed. This allows the middlewares access to writing headers, reading
 // contents, etc.
+//
+// When streaming, Flush sends any data buffered by the underlying http.ResponseWriter to
+// the client if it implements http.Flusher.
+//
+// Because Flush returns an error, BufferedResponseWriter does not implement http.Flusher
+// and a type assertion of http.Flusher on it fails. Use HTTPResponseWriter, whose Flush
+// streams the response, for code which type-asserts http.Flusher
 func (w *BufferedResponseWriter) Flush() (err error) {
-	w.m.RLock()
-	defer w.m.RUnlock()
+	w.m.Lock()
+	defer w.m.Unlock()
+	err = w.commitOnce()
+	if err == nil && w.streaming {
+		w.flushBase()
+	}
+	return err
+}
+
+// Stream stops buffering the response. The status (http.StatusOK if unset) and headers
+// are sent along with anything that has already been written, and all subsequent writes
+// are sent straight to the client. When nothing has been written yet, the status and
+// headers are not sent until the first Write or Flush so that the ErrorHandler can still
+// set the status of an error returned before anything is written. The status and headers
+// can no longer be changed once they are sent. Status and Len continue to report the
+// status sent and the total bytes written. ErrResponseTooLarge is returned, and streaming
+// does not begin, when the buffered body has already grown beyond BufferOptions.MaxSize
+func (w *BufferedResponseWriter) Stream() (err error) {
+	w.m.Lock()
+	defer w.m.Unlock()
+	if w.streaming {
+		return nil
+	}
+	if w.overflow {
+		// the buffered response is incomplete, so it is replaced by the error instead
+		return ErrResponseTooLarge
+	}
+	w.streaming = true
+	if w.length == 0 {
+		return nil
+	}
+	if err = w.commitOnce(); err == nil {
+		w.flushBase()
+	}
+	return err
+}
+
+// commitOnce commits the response unless it has already been committed
+func (w *BufferedResponseWriter) commitOnce() (err error) {
 	w.flushOnce.Do(func() {
-		w.base.WriteHeader(w.Status())
-		_, err = w.body.WriteTo(w.base)
+		err = w.commit()
 	})
 	return err
 }
 
+// commit writes the status, headers and buffered body to the base writer. It must only
+// be called within flushOnce
+func (w *BufferedResponseWriter) commit() error {
+	w.committed = true
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	w.base.WriteHeader(w.status)
+	if w.file == nil {
+		_, err := w.body.WriteTo(w.base)
+		return err
+	}
+
+	defer w.removeFile()
+	if _, err := w.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	_, err := io.Copy(w.base, w.file)
+	return err
+}
+
+// spill moves the buffered body into a temporary file
+func (w *BufferedResponseWriter) spill() error {
+	f, err := ioutil.TempFile(w.opts.TempDir, "boar-response-")
+	if err != nil {
+		return fmt.Errorf("could not create response buffer file: %v", err)
+	}
+	if _, err := w.body.WriteTo(f); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return fmt.Errorf("could not write response buffer file: %v", err)
+	}
+	w.file = f
+	return nil
+}
+
+func (w *BufferedResponseWriter) removeFile() {
+	if w.file == nil {
+		return
+	}
+	w.file.Close()
+	os.Remove(w.file.Name())
+	w.file = nil
+}
+
+// bufferWrite appends b to the buffered body, moving it to a temporary file when
+// it grows beyond the memory threshold
+func (w *BufferedResponseWriter) bufferWrite(b []byte) (int, error) {
+	size := int64(w.length + len(b))
+	if w.overflow || (w.opts.MaxSize > 0 && size > w.opts.MaxSize) {
+		w.overflow = true
+		w.body.Reset()
+		w.removeFile()
+		return 0, ErrResponseTooLarge
+	}
+
+	if w.file == nil && w.opts.MemoryThreshold > 0 && size > w.opts.MemoryThreshold {
+		if err := w.spill(); err != nil {
+			return 0, err
+		}
+	}
+	if w.file != nil {
+		return w.file.Write(b)
+	}
+	return w.body.Write(b)
+}
+
+// reset discards the buffered status and body after the body has grown beyond
+// BufferOptions.MaxSize so that an error can be written in its place. The error is not
+// subject to MaxSize. false is returned if the body has not overflowed
+func (w *BufferedResponseWriter) reset() bool {
+	w.m.Lock()
+	defer w.m.Unlock()
+	if !w.overflow || w.streaming {
+		return false
+	}
+	w.overflow = false
+	w.opts.MaxSize = 0
+	w.status = 0
+	w.length = 0
+	w.body.Reset()
+	w.removeFile()
+	w.base.Header().Del("content-length")
+	return true
+}
+
+func (w *BufferedResponseWriter) flushBase() {
+	if w.hijacked {
+		return
+	}
+	if f, ok := w.base.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
 // Close flushes the response stream and closes the writer. Subsequent calls to Body(), Len(),
-// etc will yield no results
+// etc will yield no results. Any temporary file used to buffer the body is removed
 func (w *BufferedResponseWriter) Close() error {
-	return w.Flush()
+	err := w.Flush()
+	w.m.Lock()
+	w.removeFile()
+	w.m.Unlock()
+	return err
 }
 
 // Status returns the currently set HTTP status code
 func (w *BufferedResponseWriter) Status() int {
+	w.m.RLock()
+	defer w.m.RUnlock()
 	return w.status
 }
 
@@ -72,7 +271,7 @@ func (w *BufferedResponseWriter) Status() int {
 func (w *BufferedResponseWriter) Len() int {
 	w.m.RLock()
 	defer w.m.RUnlock()
-	return w.body.Len()
+	return w.length
 }
 
 // Header returns the header map that will be sent by WriteHeader. The Header map
@@ -89,17 +288,175 @@ func (w *BufferedResponseWriter) Header() http.Header {
 func (w *BufferedResponseWriter) Write(b []byte) (n int, err error) {
 	w.m.Lock()
 	defer w.m.Unlock()
+	if w.hijacked {
+		return 0, http.ErrHijacked
+	}
+	if w.streamOnWrite {
+		w.streaming = true
+	}
+	if w.streaming {
+		if err := w.commitOnce(); err != nil {
+			return 0, err
+		}
+	}
 	// This is what the http.ResponseWriter does by default. If the status code has
 	// not already been set then it defaults to http.StatusOK
 	if w.status == 0 {
 		w.status = http.StatusOK
 	}
-	return w.body.Write(b)
+
+	if w.streaming {
+		n, err = w.base.Write(b)
+	} else {
+		n, err = w.bufferWrite(b)
+	}
+	w.length += n
+	return n, err
 }
 
 // WriteHeader sets the http status code. Unlike the default http.ResponseWriter, this does
 // _not_ begin the response transaction. This will simply store the status code until Flush
-// is executed
+// is executed. Once the status has been sent while streaming it cannot be changed
 func (w *BufferedResponseWriter) WriteHeader(status int) {
+	w.m.Lock()
+	defer w.m.Unlock()
+	if w.committed || w.hijacked {
+		return
+	}
 	w.status = status
+	if w.streamOnWrite {
+		w.streaming = true
+		w.commitOnce()
+	}
+}
+
+// Unwrap returns the underlying http.ResponseWriter. It allows http.ResponseController to
+// reach the optional methods of the underlying writer, such as SetWriteDeadline
+func (w *BufferedResponseWriter) Unwrap() http.ResponseWriter {
+	return w.base
+}
+
+// FlushError commits the status, headers and buffered body and then sends them to the client.
+// The response is streamed from then on, as it is with Stream. http.ErrNotSupported is returned
+// when the underlying http.ResponseWriter cannot be flushed. FlushError is used by
+// http.ResponseController to flush the response. BufferedResponseWriter is not an http.Flusher
+// because Flush returns an error, so pass HTTPResponseWriter to code which type-asserts
+// http.Flusher
+func (w *BufferedResponseWriter) FlushError() error {
+	if err := w.Stream(); err != nil {
+		return err
+	}
+	w.m.Lock()
+	defer w.m.Unlock()
+	if w.hijacked {
+		return http.ErrHijacked
+	}
+	if err := w.commitOnce(); err != nil {
+		return err
+	}
+	switch f := w.base.(type) {
+	case interface{ FlushError() error }:
+		return f.FlushError()
+	case http.Flusher:
+		f.Flush()
+		return nil
+	}
+	return http.ErrNotSupported
+}
+
+// HTTPResponseWriter returns w as an http.ResponseWriter for code written for net/http, such
+// as http.Handlers and middleware which type-assert http.Flusher. It implements http.Flusher
+// by streaming the response as FlushError does, along with http.Hijacker, http.Pusher and
+// io.ReaderFrom. Unwrap returns the underlying http.ResponseWriter
+func (w *BufferedResponseWriter) HTTPResponseWriter() http.ResponseWriter {
+	return httpResponseWriter{w}
+}
+
+// httpResponseWriter adapts a BufferedResponseWriter for net/http, which expects an
+// http.Flusher rather than Flush() error
+type httpResponseWriter struct {
+	*BufferedResponseWriter
+}
+
+func (w httpResponseWriter) Flush() {
+	w.FlushError()
+}
+
+// Hijack implements http.Hijacker by taking over the connection of the underlying
+// http.ResponseWriter. The buffered status, headers and body are discarded because they
+// are not a valid response on their own. All subsequent writes to the response fail with
+// http.ErrHijacked and Status reports http.StatusSwitchingProtocols. http.ErrNotSupported is
+// returned when the underlying http.ResponseWriter is not an http.Hijacker
+func (w *BufferedResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.m.Lock()
+	defer w.m.Unlock()
+	hj, ok := w.base.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	if w.hijacked {
+		return nil, nil, http.ErrHijacked
+	}
+	conn, brw, err := hj.Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	w.hijacked = true
+	w.streaming = true
+	w.status = http.StatusSwitchingProtocols
+	w.body.Reset()
+	w.removeFile()
+	w.flushOnce.Do(func() {})
+	return conn, brw, nil
+}
+
+// Push implements http.Pusher by initiating an HTTP/2 server push with the underlying
+// http.ResponseWriter. http.ErrNotSupported is returned when it is not an http.Pusher
+func (w *BufferedResponseWriter) Push(target string, opts *http.PushOptions) error {
+	if p, ok := w.base.(http.Pusher); ok {
+		return p.Push(target, opts)
+	}
+	return http.ErrNotSupported
+}
+
+// ReadFrom implements io.ReaderFrom. While the response is buffered, r is read into the
+// buffer. Once the response is streamed the buffered data is committed first and r is copied
+// straight to the underlying http.ResponseWriter, which allows io.Copy to use sendfile when
+// the body is an *os.File
+func (w *BufferedResponseWriter) ReadFrom(r io.Reader) (int64, error) {
+	w.m.RLock()
+	streaming := w.streaming || w.streamOnWrite
+	w.m.RUnlock()
+	if !streaming {
+		// writerOnly hides ReadFrom so that io.Copy does not recurse
+		return io.Copy(writerOnly{w}, r)
+	}
+
+	if err := w.Stream(); err != nil {
+		return 0, err
+	}
+	w.m.Lock()
+	defer w.m.Unlock()
+	if w.hijacked {
+		return 0, http.ErrHijacked
+	}
+	if err := w.commitOnce(); err != nil {
+		return 0, err
+	}
+	var (
+		n   int64
+		err error
+	)
+	if rf, ok := w.base.(io.ReaderFrom); ok {
+		n, err = rf.ReadFrom(r)
+	} else {
+		n, err = io.Copy(w.base, r)
+	}
+	w.length += int(n)
+	return n, err
+}
+
+type writerOnly struct {
+	io.Writer
 }
@@ -1,12 +1,17 @@
 package boar
 
 import (
+	"bufio"
 	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"math/rand"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -83,7 +88,7 @@ func TestBodyReturnsWrittenBytes(t *testing.T) {
 	w := NewBufferedResponseWriter(rec)
 
 	exp := "kajshdfalsdf"
-	fmt.Fprintf(w, exp)
+	fmt.Fprint(w, exp)
 
 	body, err := ioutil.ReadAll(w.body)
 	require.NoError(t, err)
@@ -129,3 +134,375 @@ func TestHeaderSetsHeaders(t *testing.T) {
 	val := rec.Result().Header.Get("hello")
 	assert.Equal(t, "world", val)
 }
+
+func TestStreamSendsBufferedDataAndHeaders(t *testing.T) {
+	rec := httptest.NewRecorder()
+	w := NewBufferedResponseWriter(rec)
+
+	w.Header().Set("hello", "world")
+	w.WriteHeader(http.StatusAccepted)
+	w.Write([]byte("buffered"))
+	require.NoError(t, w.Stream())
+
+	assert.True(t, rec.Flushed)
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.Equal(t, "world", rec.Header().Get("hello"))
+	assert.Equal(t, "buffered", rec.Body.String())
+}
+
+func TestStreamWritesStraightThrough(t *testing.T) {
+	rec := httptest.NewRecorder()
+	w := NewBufferedResponseWriter(rec)
+	require.NoError(t, w.Stream())
+
+	w.Write([]byte("hello"))
+	assert.Equal(t, "hello", rec.Body.String())
+	assert.Equal(t, 0, w.body.Len())
+
+	w.Write([]byte(" world"))
+	assert.Equal(t, "hello world", rec.Body.String())
+	assert.Equal(t, len("hello world"), w.Len())
+	assert.Equal(t, http.StatusOK, w.Status())
+}
+
+func TestStreamIgnoresWriteHeaderAfterCommit(t *testing.T) {
+	rec := httptest.NewRecorder()
+	w := NewBufferedResponseWriter(rec)
+	require.NoError(t, w.Stream())
+	w.Write([]byte("hello"))
+
+	w.WriteHeader(http.StatusTeapot)
+	assert.Equal(t, http.StatusOK, w.Status())
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestStreamDefersStatusUntilFirstWrite(t *testing.T) {
+	rec := httptest.NewRecorder()
+	w := NewBufferedResponseWriter(rec)
+	require.NoError(t, w.Stream())
+	assert.False(t, rec.Flushed, "nothing should be sent before the first write")
+
+	w.WriteHeader(http.StatusForbidden)
+	w.Write([]byte("forbidden"))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Equal(t, "forbidden", rec.Body.String())
+}
+
+func TestFlushWhileStreamingFlushesBase(t *testing.T) {
+	rec := httptest.NewRecorder()
+	w := NewBufferedResponseWriter(rec)
+	require.NoError(t, w.Stream())
+	rec.Flushed = false
+
+	w.Write([]byte("hello"))
+	require.NoError(t, w.Flush())
+	assert.True(t, rec.Flushed)
+}
+
+func TestStreamOnWriteCommitsOnWriteHeader(t *testing.T) {
+	rec := httptest.NewRecorder()
+	w := NewBufferedResponseWriter(rec)
+	w.streamOnWrite = true
+
+	w.Header().Set("content-type", "text/plain")
+	w.WriteHeader(http.StatusCreated)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "text/plain", rec.Header().Get("content-type"))
+
+	w.Write([]byte("hello"))
+	assert.Equal(t, "hello", rec.Body.String())
+}
+
+func TestLenCountsBytesAfterFlush(t *testing.T) {
+	rec := httptest.NewRecorder()
+	w := NewBufferedResponseWriter(rec)
+
+	w.Write([]byte("hello"))
+	require.NoError(t, w.Flush())
+	assert.Equal(t, 5, w.Len())
+}
+
+func TestBufferSpillsToFileAfterMemoryThreshold(t *testing.T) {
+	dir := t.TempDir()
+	rec := httptest.NewRecorder()
+	w := NewBufferedResponseWriter(rec)
+	w.opts = BufferOptions{MemoryThreshold: 8, TempDir: dir}
+
+	w.Write([]byte("hello"))
+	require.Nil(t, w.file)
+
+	w.Write([]byte(" world"))
+	require.NotNil(t, w.file)
+	assert.Equal(t, 0, w.body.Len())
+	assert.Equal(t, len("hello world"), w.Len())
+
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, files, 1)
+
+	require.NoError(t, w.Close())
+	assert.Equal(t, "hello world", rec.Body.String())
+
+	files, err = ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestCloseRemovesSpilledFileAfterFlush(t *testing.T) {
+	dir := t.TempDir()
+	w := NewBufferedResponseWriter(httptest.NewRecorder())
+	w.opts = BufferOptions{MemoryThreshold: 1, TempDir: dir}
+	w.Write([]byte("hello"))
+	require.NoError(t, w.Stream())
+
+	w.Write([]byte(" world"))
+	require.NoError(t, w.Close())
+
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestBufferWriteBeyondMaxSizeFails(t *testing.T) {
+	dir := t.TempDir()
+	w := NewBufferedResponseWriter(httptest.NewRecorder())
+	w.opts = BufferOptions{MemoryThreshold: 2, MaxSize: 8, TempDir: dir}
+
+	_, err := w.Write([]byte("hello"))
+	require.NoError(t, err)
+
+	_, err = w.Write([]byte(" world"))
+	assert.Equal(t, ErrResponseTooLarge, err)
+	assert.Nil(t, w.file, "spilled file should be discarded")
+
+	_, err = w.Write([]byte("!"))
+	assert.Equal(t, ErrResponseTooLarge, err, "writes after overflow should keep failing")
+
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestBufferMaxSizeIgnoredWhileStreaming(t *testing.T) {
+	rec := httptest.NewRecorder()
+	w := NewBufferedResponseWriter(rec)
+	w.opts = BufferOptions{MaxSize: 2}
+	require.NoError(t, w.Stream())
+
+	_, err := w.Write([]byte("hello"))
+	require.NoError(t, err)
+	assert.Equal(t, "hello", rec.Body.String())
+}
+
+// plainResponseWriter implements none of the optional interfaces
+type plainResponseWriter struct {
+	http.ResponseWriter
+}
+
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+	server, client net.Conn
+}
+
+func newHijackRecorder(t *testing.T) *hijackRecorder {
+	server, client := net.Pipe()
+	t.Cleanup(func() {
+		server.Close()
+		client.Close()
+	})
+	return &hijackRecorder{ResponseRecorder: httptest.NewRecorder(), server: server, client: client}
+}
+
+func (h *hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return h.server, bufio.NewReadWriter(bufio.NewReader(h.server), bufio.NewWriter(h.server)), nil
+}
+
+type pushRecorder struct {
+	*httptest.ResponseRecorder
+	pushed []string
+}
+
+func (p *pushRecorder) Push(target string, opts *http.PushOptions) error {
+	p.pushed = append(p.pushed, target)
+	return nil
+}
+
+type readerFromRecorder struct {
+	*httptest.ResponseRecorder
+	readFrom int
+}
+
+func (r *readerFromRecorder) ReadFrom(src io.Reader) (int64, error) {
+	r.readFrom++
+	return io.Copy(r.ResponseRecorder, src)
+}
+
+func TestUnwrapReturnsBase(t *testing.T) {
+	rec := httptest.NewRecorder()
+	w := NewBufferedResponseWriter(rec)
+	assert.Equal(t, rec, w.Unwrap())
+}
+
+func TestResponseControllerReachesBaseThroughUnwrap(t *testing.T) {
+	w := NewBufferedResponseWriter(httptest.NewRecorder())
+	err := http.NewResponseController(w).SetWriteDeadline(time.Now())
+	assert.True(t, errors.Is(err, http.ErrNotSupported))
+}
+
+func TestResponseControllerFlushCommitsBufferedData(t *testing.T) {
+	rec := httptest.NewRecorder()
+	w := NewBufferedResponseWriter(rec)
+	w.Header().Set("hello", "world")
+	w.WriteHeader(http.StatusAccepted)
+	w.Write([]byte("hello"))
+
+	require.NoError(t, http.NewResponseController(w).Flush())
+	assert.True(t, rec.Flushed)
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.Equal(t, "world", rec.Header().Get("hello"))
+	assert.Equal(t, "hello", rec.Body.String())
+
+	w.Write([]byte(" world"))
+	assert.Equal(t, "hello world", rec.Body.String(), "writes after flushing should be streamed")
+}
+
+func TestHTTPResponseWriterIsFlusher(t *testing.T) {
+	rec := httptest.NewRecorder()
+	bw := NewBufferedResponseWriter(rec)
+	w := bw.HTTPResponseWriter()
+	w.WriteHeader(http.StatusAccepted)
+	w.Write([]byte("hello"))
+
+	f, ok := w.(http.Flusher)
+	require.True(t, ok)
+	f.Flush()
+	assert.True(t, rec.Flushed)
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.Equal(t, "hello", rec.Body.String())
+
+	w.Write([]byte(" world"))
+	assert.Equal(t, "hello world", rec.Body.String(), "writes after flushing should be streamed")
+
+	_, ok = w.(http.Hijacker)
+	assert.True(t, ok)
+	_, ok = w.(http.Pusher)
+	assert.True(t, ok)
+	_, ok = w.(io.ReaderFrom)
+	assert.True(t, ok)
+	assert.Equal(t, rec, w.(interface{ Unwrap() http.ResponseWriter }).Unwrap())
+}
+
+func TestBufferedResponseWriterIsNotFlusher(t *testing.T) {
+	var w http.ResponseWriter = NewBufferedResponseWriter(httptest.NewRecorder())
+	_, ok := w.(http.Flusher)
+	assert.False(t, ok, "Flush() error does not implement http.Flusher")
+}
+
+func TestFlushErrorWithoutFlusher(t *testing.T) {
+	rec := httptest.NewRecorder()
+	w := NewBufferedResponseWriter(plainResponseWriter{rec})
+	w.Write([]byte("hello"))
+
+	assert.Equal(t, http.ErrNotSupported, w.FlushError())
+	assert.Equal(t, "hello", rec.Body.String(), "buffered data should still be committed")
+}
+
+func TestHijackDiscardsBufferedResponse(t *testing.T) {
+	rec := newHijackRecorder(t)
+	w := NewBufferedResponseWriter(rec)
+	w.WriteHeader(http.StatusAccepted)
+	w.Write([]byte("buffered "))
+
+	conn, brw, err := w.Hijack()
+	require.NoError(t, err)
+	assert.Equal(t, rec.server, conn)
+
+	go func() {
+		brw.WriteString("raw")
+		brw.Flush()
+	}()
+	b := make([]byte, len("raw"))
+	_, err = io.ReadFull(rec.client, b)
+	require.NoError(t, err)
+	assert.Equal(t, "raw", string(b))
+
+	_, err = w.Write([]byte("late"))
+	assert.Equal(t, http.ErrHijacked, err)
+	assert.Equal(t, http.StatusSwitchingProtocols, w.Status())
+	assert.Equal(t, http.StatusOK, rec.Code, "nothing should be written to the base")
+	assert.Empty(t, rec.Body.String())
+
+	_, _, err = w.Hijack()
+	assert.Equal(t, http.ErrHijacked, err)
+}
+
+func TestHijackWithoutHijacker(t *testing.T) {
+	w := NewBufferedResponseWriter(httptest.NewRecorder())
+	_, _, err := w.Hijack()
+	assert.Equal(t, http.ErrNotSupported, err)
+}
+
+func TestResponseControllerHijack(t *testing.T) {
+	rec := newHijackRecorder(t)
+	w := NewBufferedResponseWriter(rec)
+
+	conn, _, err := http.NewResponseController(w).Hijack()
+	require.NoError(t, err)
+	assert.Equal(t, rec.server, conn)
+}
+
+func TestPushPassesThrough(t *testing.T) {
+	rec := &pushRecorder{ResponseRecorder: httptest.NewRecorder()}
+	w := NewBufferedResponseWriter(rec)
+
+	require.NoError(t, w.Push("/app.js", nil))
+	assert.Equal(t, []string{"/app.js"}, rec.pushed)
+}
+
+func TestPushWithoutPusher(t *testing.T) {
+	w := NewBufferedResponseWriter(httptest.NewRecorder())
+	assert.Equal(t, http.ErrNotSupported, w.Push("/app.js", nil))
+}
+
+func TestReadFromWhileBuffered(t *testing.T) {
+	rec := &readerFromRecorder{ResponseRecorder: httptest.NewRecorder()}
+	w := NewBufferedResponseWriter(rec)
+
+	n, err := io.Copy(w, strings.NewReader("hello"))
+	require.NoError(t, err)
+	assert.EqualValues(t, 5, n)
+	assert.Equal(t, 0, rec.readFrom)
+	assert.Empty(t, rec.Body.String())
+	assert.Equal(t, 5, w.Len())
+
+	require.NoError(t, w.Flush())
+	assert.Equal(t, "hello", rec.Body.String())
+}
+
+func TestReadFromWhileStreaming(t *testing.T) {
+	rec := &readerFromRecorder{ResponseRecorder: httptest.NewRecorder()}
+	w := NewBufferedResponseWriter(rec)
+	w.streamOnWrite = true
+	w.WriteHeader(http.StatusCreated)
+
+	// hide WriteTo so that io.Copy uses ReadFrom
+	n, err := io.Copy(w, struct{ io.Reader }{strings.NewReader("hello")})
+	require.NoError(t, err)
+	assert.EqualValues(t, 5, n)
+	assert.Equal(t, 1, rec.readFrom)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "hello", rec.Body.String())
+	assert.Equal(t, 5, w.Len())
+}
+
+func TestReadFromCommitsBufferedDataFirst(t *testing.T) {
+	rec := httptest.NewRecorder()
+	w := NewBufferedResponseWriter(plainResponseWriter{rec})
+	w.Write([]byte("hello "))
+	require.NoError(t, w.Stream())
+
+	_, err := w.ReadFrom(strings.NewReader("world"))
+	require.NoError(t, err)
+	assert.Equal(t, "hello world", rec.Body.String())
+	assert.Equal(t, len("hello world"), w.Len())
+}
@@ -0,0 +1,132 @@
+package boar
+
+import "reflect"
+
+// RouteOption configures a single route when it is registered with the Router
+type RouteOption func(*route)
+
+// route is the configuration of a single registered route
+type route struct {
+	method     string
+	path       string
+	name       string
+	consumes   []string
+	streamBody bool
+	// streamResponse sends the response to the client as it is written
+	streamResponse bool
+
+	decodeOptions *DecodeOptions
+	bufferOptions *BufferOptions
+
+	webSocketOptions WebSocketOptions
+	staticOptions    StaticOptions
+
+	// handlerType is the type of the Handler returned by the provider of the route, given
+	// by Handles. handlerName describes handlers which are not described by their type, such as
+	// those registered with MethodFunc
+	handlerType reflect.Type
+	handlerName string
+
+	// responseType is the type of the body of a successful response
+	responseType reflect.Type
+
+	// mounted is set for the routes registered by Mount, which are listed in the route
+	// table as a single route. hidden routes are left out of the route table
+	mounted bool
+	hidden  bool
+}
+
+func newRoute(method, path string, opts []RouteOption) *route {
+	rt := &route{
+		method: method,
+		path:   path,
+	}
+	for _, opt := range opts {
+		opt(rt)
+	}
+	return rt
+}
+
+// Name names a route so that its URL can be built with Router.URL and Context.URLFor
+// instead of being hardcoded. Names must be unique within a Router, although the same name
+// may be given to routes of different methods that share a path
+func Name(name string) RouteOption {
+	return func(rt *route) {
+		rt.name = name
+	}
+}
+
+// Returns documents the type of the JSON body of a successful response of a route, such as
+// the value passed to Context.WriteJSON. The type is used by the route table and the
+// documents and clients generated from it; responses are not checked against it
+//
+//	r.Get("/users/:id", getUser, boar.Name("user"), boar.Returns(User{}))
+func Returns(v interface{}) RouteOption {
+	return func(rt *route) {
+		rt.responseType = reflect.TypeOf(v)
+	}
+}
+
+// Handles documents the type of the handler returned by the provider of a route. The Query,
+// URLParams, Headers and Body fields of the handler describe the route in the route table and
+// the documents and clients generated from it. Providers are not called when routes are
+// registered, so the handler of a route registered without Handles is unknown
+//
+//	r.Get("/users/:id", newGetUser, boar.Name("user"), boar.Handles(&getUser{}))
+func Handles(h interface{}) RouteOption {
+	return func(rt *route) {
+		rt.handlerType = reflect.TypeOf(h)
+	}
+}
+
+// handlerName describes the handler of a route in the route table
+func handlerName(name string) RouteOption {
+	return func(rt *route) {
+		rt.handlerName = name
+	}
+}
+
+// Consumes restricts the content types that are accepted for the request body of
+// a route. Requests with any other content type are rejected with a 415 Unsupported
+// Media Type. Content types with a structured syntax suffix, such as
+// application/vnd.api+json, are also accepted when their generic type is listed
+func Consumes(mediaTypes ...string) RouteOption {
+	return func(rt *route) {
+		rt.consumes = append(rt.consumes, mediaTypes...)
+	}
+}
+
+// WithDecodeOptions overrides Router.DecodeOptions for a single route
+func WithDecodeOptions(opts DecodeOptions) RouteOption {
+	return func(rt *route) {
+		rt.decodeOptions = &opts
+	}
+}
+
+// WithBufferOptions overrides Router.BufferOptions for a single route
+func WithBufferOptions(opts BufferOptions) RouteOption {
+	return func(rt *route) {
+		rt.bufferOptions = &opts
+	}
+}
+
+// StreamBody disables populating the Body field of the handler so that the request
+// body is left unread for the handler to stream, such as with Context.MultipartReader.
+// This is useful for large uploads which should not be buffered in memory or spooled to
+// disk before the handler executes
+func StreamBody() RouteOption {
+	return func(rt *route) {
+		rt.streamBody = true
+	}
+}
+
+// StreamResponse disables buffering of the response for a route. The status and headers
+// are sent on the first call to WriteHeader or Write and the body is sent straight to the
+// client as it is written. Middlewares can still read the final status and the number of
+// bytes written, but cannot change headers after the handler has begun writing. A single
+// request can be streamed with Context.Response().Stream() instead
+func StreamResponse() RouteOption {
+	return func(rt *route) {
+		rt.streamResponse = true
+	}
+}
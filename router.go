@@ -5,10 +5,22 @@ import (
 	"net/http"
 	"reflect"
 	"runtime/debug"
+	"strings"
 
 	"github.com/julienschmidt/httprouter"
 )
 
+var allMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodOptions,
+	http.MethodTrace,
+}
+
 // JSON is a shortcut for map[string]interface{}
 type JSON map[string]interface{}
 
@@ -41,9 +53,17 @@ var defaultErrorHandler = func(c Context, err error) {
 	}
 
 	if c.Response().Len() == 0 {
-		werr := c.WriteJSON(httperr.Status(), httperr)
-		if werr != nil {
-			log.Printf("ERROR: unable to serialize JSON to response: %s", werr)
+		werr := c.Render(httperr.Status(), httperr)
+		if werr != nil && werr != http.ErrHijacked && c.Response().Len() == 0 {
+			// the client should still be told what went wrong even if it does not
+			// accept any of the formats that are available, or the encoder that was
+			// chosen cannot encode errors
+			werr = c.WriteJSON(httperr.Status(), httperr)
+		}
+		// the connection was taken over, such as by a websocket upgrade, and can
+		// no longer be written to
+		if werr != nil && werr != http.ErrHijacked {
+			log.Printf("ERROR: unable to serialize error to response: %s", werr)
 		}
 	}
 
@@ -71,30 +91,47 @@ func NewRouterWithBase(r *httprouter.Router) *Router {
 		base:         r,
 		ErrorHandler: defaultErrorHandler,
 		middlewares:  make([]Middleware, 0),
+		decoders:     defaultDecoders(),
+		encoders:     defaultEncoders(),
+		names:        make(map[string]*route),
 	}
 }
 
 // NewRouter creates a new router for handling http requests
 func NewRouter() *Router {
-	r := httprouter.New()
-	r.NotFound = func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusNotFound)
-	}
-	r.MethodNotAllowed = func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusMethodNotAllowed)
-	}
-
-	return NewRouterWithBase(r)
+	rtr := NewRouterWithBase(httprouter.New())
+	rtr.NotFound(func(Context) error {
+		return ErrNotFound
+	})
+	rtr.MethodNotAllowed(func(Context) error {
+		return ErrMethodNotAllowed
+	})
+	return rtr
 }
 
 // Router is an http router
 type Router struct {
 	base        *httprouter.Router
 	middlewares []Middleware
+	decoders    map[string]DecoderFunc
+	encoders    []encoder
+	names       map[string]*route
+	routes      []*route
+	// methods are the distinct methods of routes in the order they were first registered
+	methods []string
+	notFound HandlerFunc
+	// rootStatic serves the files of Static("/") for requests which match no route
+	rootStatic *staticFS
 	// ErrorHandler is a middleware that handles writing errors back to the client when an error
 	// an error occurs in the handler. It is the first middleware executed therefore It should
 	// always return the error that it handled
 	ErrorHandler ErrorHandlerFunc
+	// DecodeOptions configure how request bodies are read for every route. They can
+	// be overridden for a single route with the WithDecodeOptions RouteOption
+	DecodeOptions DecodeOptions
+	// BufferOptions limit the memory used to buffer responses for every route. They can
+	// be overridden for a single route with the WithBufferOptions RouteOption
+	BufferOptions BufferOptions
 }
 
 // RealRouter returns the httprouter.Router used for actual serving
@@ -108,20 +145,190 @@ func (rtr *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 // Method is a path handler that uses a factory to generate the handler
 // this is particularly useful for filling contextual information into a struct
-// before passing it along to handle the request
-func (rtr *Router) Method(method string, path string, createHandler HandlerProviderFunc) {
-	rtr.RealRouter().Handle(method, path, func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
-		c := newContext(r, w, ps)
-		defer c.Response().Flush()
-
-		wrappedHandler := rtr.withMiddlewares(requestParserMiddleware(createHandler))
-		wrappedHandler(c)
+// before passing it along to handle the request. The Query, URLParams, Headers and Body
+// fields of the handler are populated from the request and validated before Handle is
+// called. The fields of a Headers struct are bound by their header tag or their name, and
+// header names are not case sensitive. A Headers field which is not a struct, such as an
+// http.Header, is left untouched. Handlers which already had a Headers struct field for
+// their own use now have it bound and validated, so such fields should be renamed or their
+// fields tagged with header:"-"
+func (rtr *Router) Method(method string, path string, createHandler HandlerProviderFunc, opts ...RouteOption) {
+	rt := newRoute(method, path, opts)
+	rtr.handle(rt, requestParserMiddleware(rt, createHandler))
+}
+
+// handle records rt in the route table and registers h to serve it
+func (rtr *Router) handle(rt *route, h HandlerFunc) {
+	if rt.name != "" {
+		rtr.addName(rt)
+	}
+	rtr.routes = append(rtr.routes, rt)
+	rtr.addMethod(rt.method)
+	rtr.RealRouter().Handle(rt.method, rt.path, func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		rtr.serve(w, r, ps, rt, h)
 	})
 }
 
+// RegisterDecoder registers a decoder for request bodies with the given media type. The
+// decoder is used by Context.Bind and when populating the Body of handlers. Registering
+// a media type that already has a decoder replaces it, including the defaults for
+// application/json, application/x-www-form-urlencoded and multipart/form-data. mediaType
+// must be a type and subtype, such as text/csv, without wildcards or parameters
+func (rtr *Router) RegisterDecoder(mediaType string, fn DecoderFunc) {
+	if fn == nil {
+		log.Panicf("cannot register nil decoder for %q", mediaType)
+	}
+	typ, ok := registeredMediaType(mediaType)
+	if !ok {
+		log.Panicf("cannot register decoder for invalid media type %q", mediaType)
+	}
+	rtr.decoders[typ] = fn
+}
+
+// RegisterEncoder registers an encoder for responses with the given media type. The
+// encoder is used by Context.Render and the default ErrorHandler when it is the best match
+// for the Accept header of the request. Encoders registered first are preferred when the
+// client accepts multiple media types equally, application/json is the first default.
+// Registering a media type that already has an encoder replaces it. mediaType must be a
+// type and subtype, such as text/csv, without wildcards or parameters
+func (rtr *Router) RegisterEncoder(mediaType string, fn EncoderFunc) {
+	if fn == nil {
+		log.Panicf("cannot register nil encoder for %q", mediaType)
+	}
+	typ, ok := registeredMediaType(mediaType)
+	if !ok {
+		log.Panicf("cannot register encoder for invalid media type %q", mediaType)
+	}
+	mediaType = typ
+	for i, enc := range rtr.encoders {
+		if enc.mediaType == mediaType {
+			rtr.encoders[i].encode = fn
+			return
+		}
+	}
+	rtr.encoders = append(rtr.encoders, encoder{mediaType: mediaType, encode: fn})
+}
+
+// registeredMediaType returns the lowercased mediaType of a decoder or encoder. false is
+// returned unless mediaType is a type and subtype without wildcards or parameters, which
+// could never match the media type of a request
+func registeredMediaType(mediaType string) (string, bool) {
+	mt, err := parseMediaType(mediaType)
+	if err != nil || !strings.Contains(mt.Type, "/") || strings.Contains(mt.Type, "*") || len(mt.Params) > 0 {
+		return "", false
+	}
+	return mt.Type, true
+}
+
+// NotFound sets the handler that is executed when no route matches the request.
+// The handler receives a full Context and is executed through the global middlewares
+// and the ErrorHandler just like any other route. The default handler returns ErrNotFound
+func (rtr *Router) NotFound(h HandlerFunc) {
+	rtr.notFound = h
+	rtr.RealRouter().NotFound = func(w http.ResponseWriter, r *http.Request) {
+		rtr.serve(w, r, nil, nil, rtr.serveNotFound)
+	}
+}
+
+// serveNotFound serves the files of Static("/") for requests which match no route, and
+// executes the NotFound handler for everything else
+func (rtr *Router) serveNotFound(c Context) error {
+	if s := rtr.rootStatic; s != nil {
+		if m := c.Request().Method; m == http.MethodGet || m == http.MethodHead {
+			if err := s.serveFile(c, c.Request().URL.Path); err != ErrNotFound {
+				return err
+			}
+		}
+	}
+	if rtr.notFound == nil {
+		return ErrNotFound
+	}
+	return rtr.notFound(c)
+}
+
+// MethodNotAllowed sets the handler that is executed when a route matches the request
+// path, but not the request method. The Allow header is set to the methods registered
+// for the path before the handler is executed. The vendored httprouter does not answer
+// OPTIONS requests itself, so OPTIONS is only allowed for paths with an OPTIONS route. The
+// handler is executed through the global middlewares and the ErrorHandler. The default
+// handler returns ErrMethodNotAllowed
+func (rtr *Router) MethodNotAllowed(h HandlerFunc) {
+	rtr.RealRouter().MethodNotAllowed = func(w http.ResponseWriter, r *http.Request) {
+		rtr.serve(w, r, nil, nil, func(c Context) error {
+			c.Response().Header().Set("Allow", strings.Join(rtr.allowed(r.URL.Path), ", "))
+			return h(c)
+		})
+	}
+}
+
+func (rtr *Router) addMethod(method string) {
+	for _, m := range rtr.methods {
+		if m == method {
+			return
+		}
+	}
+	rtr.methods = append(rtr.methods, method)
+}
+
+// allowed returns the methods that have a handler registered for path
+func (rtr *Router) allowed(path string) []string {
+	methods := make([]string, 0, len(rtr.methods))
+	for _, method := range rtr.methods {
+		if h, _, _ := rtr.RealRouter().Lookup(method, path); h != nil {
+			methods = append(methods, method)
+		}
+	}
+	return methods
+}
+
+// serve executes h with a new Context through all middlewares and flushes the response
+func (rtr *Router) serve(w http.ResponseWriter, r *http.Request, ps httprouter.Params, rt *route, h HandlerFunc) {
+	c := newRequestContext(r, w, ps, rtr.decoders, rtr.encoders)
+	defer removeMultipartFiles(r)
+	c.router = rtr
+	c.decodeOptions = rtr.DecodeOptions
+	bw, _ := c.response.(*BufferedResponseWriter)
+	if bw != nil {
+		bw.opts = rtr.BufferOptions
+	}
+	if rt != nil {
+		c.route = rt
+		if rt.decodeOptions != nil {
+			c.decodeOptions = *rt.decodeOptions
+		}
+		if bw != nil {
+			bw.streamOnWrite = rt.streamResponse
+			if rt.bufferOptions != nil {
+				bw.opts = *rt.bufferOptions
+			}
+		}
+	}
+	if c.decodeOptions.MaxBytes > 0 && r.Body != nil {
+		r.Body = http.MaxBytesReader(w, r.Body, c.decodeOptions.MaxBytes)
+	}
+	defer c.Response().Close()
+	defer func() {
+		if c.sse != nil {
+			c.sse.Close()
+		}
+	}()
+
+	wrappedHandler := rtr.withMiddlewares(h)
+	err := wrappedHandler(c)
+	if c.jsonStream != nil {
+		c.jsonStream.finish(err)
+	}
+
+	// the response outgrew BufferOptions.MaxSize but the error was never returned
+	if bw != nil && bw.reset() {
+		rtr.ErrorHandler(c, ErrResponseTooLarge)
+	}
+}
+
 // requestParserMiddleware provides the handler with request objects populated by request data such
-// as query string, post body, and url parameters
-func requestParserMiddleware(createHandler HandlerProviderFunc) HandlerFunc {
+// as query string, headers, post body, and url parameters. The post body is not read for routes
+// registered with StreamBody
+func requestParserMiddleware(rt *route, createHandler HandlerProviderFunc) HandlerFunc {
 	return func(c Context) error {
 		handler, err := createHandler(c)
 		if err != nil {
@@ -131,33 +338,68 @@ func requestParserMiddleware(createHandler HandlerProviderFunc) HandlerFunc {
 			log.Panicf("nil handler provided for %q %q", c.Request().Method, c.Request().URL.Path)
 		}
 
-		handlerValue := reflect.Indirect(reflect.ValueOf(handler))
-
-		if err := setQuery(handlerValue, c.Request().URL.Query()); err != nil {
+		if err := bindRequest(rt, c, handler); err != nil {
 			return err
 		}
+		return handler.Handle(c)
+	}
+}
 
-		if err := setURLParams(handlerValue, c.URLParams()); err != nil {
-			if _, ok := err.(*ValidationError); ok {
-				return ErrNotFound
-			}
-			return err
+// bindRequest populates the Query, URLParams, Headers and Body fields of handler. Handlers
+// which are not structs have no fields to populate
+func bindRequest(rt *route, c Context, handler interface{}) error {
+	// generated binders replace reflection, so they are found before reflecting on handler
+	queryBinder, _ := handler.(QueryBinder)
+	paramsBinder, _ := handler.(ParamsBinder)
+
+	handlerValue := reflect.Indirect(reflect.ValueOf(handler))
+	if handlerValue.Kind() != reflect.Struct {
+		return nil
+	}
+
+	req := c.Request()
+	var err error
+	if queryBinder != nil {
+		err = bindQuery(queryBinder, handlerValue, req.URL.Query())
+	} else {
+		err = setQuery(handlerValue, req.URL.Query())
+	}
+	if err != nil {
+		return err
+	}
+
+	if paramsBinder != nil {
+		err = bindParams(paramsBinder, handlerValue, c.URLParams())
+	} else {
+		err = setURLParams(handlerValue, c.URLParams())
+	}
+	if err != nil {
+		if _, ok := err.(*ValidationError); ok {
+			return ErrNotFound
 		}
+		return err
+	}
 
+	if err := setHeaders(handlerValue, req.Header); err != nil {
+		return err
+	}
+
+	if !rt.streamBody {
 		if err := setBody(handlerValue, c); err != nil {
 			return err
 		}
-		return handler.Handle(c)
 	}
+	return nil
 }
 
 // MethodFunc sets a HandlerFunc for a url with the given method. It is used for
 // simple handlers that do not require any building. This is not a recommended
 // for common use cases
-func (rtr *Router) MethodFunc(method string, path string, h HandlerFunc) {
+func (rtr *Router) MethodFunc(method string, path string, h HandlerFunc, opts ...RouteOption) {
+	opts = append([]RouteOption{handlerName(funcName(h))}, opts...)
 	rtr.Method(method, path, func(Context) (Handler, error) {
 		return &simpleHandler{handle: h}, nil
-	})
+	}, opts...)
 }
 
 // Use injects a middleware into the http requests. They are executed in the
@@ -181,6 +423,11 @@ func (rtr *Router) errorHandlerWrap(next HandlerFunc) HandlerFunc {
 	return func(c Context) error {
 		err := next(c)
 		if err != nil {
+			// the buffered response outgrew BufferOptions.MaxSize so it is discarded and
+			// replaced with the error, regardless of how the handler reported it
+			if bw, ok := c.Response().(*BufferedResponseWriter); ok && bw.reset() {
+				err = ErrResponseTooLarge
+			}
 			rtr.ErrorHandler(c, err)
 		}
 		return err
@@ -196,45 +443,55 @@ func (rtr *Router) withMiddlewares(next HandlerFunc) HandlerFunc {
 }
 
 // Head is a handler that acceps HEAD requests
-func (rtr *Router) Head(path string, h HandlerProviderFunc) {
-	rtr.Method(http.MethodHead, path, h)
+func (rtr *Router) Head(path string, h HandlerProviderFunc, opts ...RouteOption) {
+	rtr.Method(http.MethodHead, path, h, opts...)
 }
 
 // Trace is a handler that accepts only TRACE requests
-func (rtr *Router) Trace(path string, h HandlerProviderFunc) {
-	rtr.Method(http.MethodTrace, path, h)
+func (rtr *Router) Trace(path string, h HandlerProviderFunc, opts ...RouteOption) {
+	rtr.Method(http.MethodTrace, path, h, opts...)
 }
 
 // Delete is a handler that accepts only DELETE requests
-func (rtr *Router) Delete(path string, h HandlerProviderFunc) {
-	rtr.Method(http.MethodDelete, path, h)
+func (rtr *Router) Delete(path string, h HandlerProviderFunc, opts ...RouteOption) {
+	rtr.Method(http.MethodDelete, path, h, opts...)
 }
 
 // Options is a handler that accepts only OPTIONS requests
 // It is not recommended to use this as the router automatically
 // handles OPTIONS requests by default
-func (rtr *Router) Options(path string, h HandlerProviderFunc) {
-	rtr.Method(http.MethodOptions, path, h)
+func (rtr *Router) Options(path string, h HandlerProviderFunc, opts ...RouteOption) {
+	rtr.Method(http.MethodOptions, path, h, opts...)
 }
 
 // Get is a handler that accepts only GET requests
-func (rtr *Router) Get(path string, h HandlerProviderFunc) {
-	rtr.Method(http.MethodGet, path, h)
+func (rtr *Router) Get(path string, h HandlerProviderFunc, opts ...RouteOption) {
+	rtr.Method(http.MethodGet, path, h, opts...)
 }
 
 // Put is a handler that accepts only PUT requests
-func (rtr *Router) Put(path string, h HandlerProviderFunc) {
-	rtr.Method(http.MethodPut, path, h)
+func (rtr *Router) Put(path string, h HandlerProviderFunc, opts ...RouteOption) {
+	rtr.Method(http.MethodPut, path, h, opts...)
 }
 
 // Post is a handler that accepts only POST requests
-func (rtr *Router) Post(path string, h HandlerProviderFunc) {
-	rtr.Method(http.MethodPost, path, h)
+func (rtr *Router) Post(path string, h HandlerProviderFunc, opts ...RouteOption) {
+	rtr.Method(http.MethodPost, path, h, opts...)
 }
 
 // Patch is a handler that accepts only PATCH requests
-func (rtr *Router) Patch(path string, h HandlerProviderFunc) {
-	rtr.Method(http.MethodPatch, path, h)
+func (rtr *Router) Patch(path string, h HandlerProviderFunc, opts ...RouteOption) {
+	rtr.Method(http.MethodPatch, path, h, opts...)
+}
+
+// removeMultipartFiles removes the temporary files created for uploads larger than
+// MultiPartFormMaxMemory once the request is complete
+func removeMultipartFiles(r *http.Request) {
+	if r.MultipartForm != nil {
+		if err := r.MultipartForm.RemoveAll(); err != nil {
+			log.Printf("ERROR: unable to remove multipart temp files: %s", err)
+		}
+	}
 }
 
 type simpleHandler struct {
@@ -8,12 +8,15 @@ import (
 	"log"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
+	"reflect"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
 
+	"github.com/blockloop/boar/bind"
 	gomock "github.com/golang/mock/gomock"
 	"github.com/julienschmidt/httprouter"
 	"github.com/stretchr/testify/assert"
@@ -38,7 +41,7 @@ func TestDefaultErrorHandlerWritesExistingHTTPErrorIfNotAlreadyWritten(t *testin
 
 	status := http.StatusBadRequest
 	err := NewHTTPErrorStatus(status)
-	mc.EXPECT().WriteJSON(gomock.Any(), gomock.Any()).Return(nil).Do(func(st int, er error) {
+	mc.EXPECT().Render(gomock.Any(), gomock.Any()).Return(nil).Do(func(st int, er error) {
 		assert.Equal(t, status, st)
 		assert.Equal(t, err, er)
 	})
@@ -54,11 +57,12 @@ func TestDefaultErrorHandlerPrintsErrIfWriteJSONFails(t *testing.T) {
 	err := NewHTTPErrorStatus(status)
 	writeErr := errors.New("something went wrong")
 
+	mc.EXPECT().Render(gomock.Any(), gomock.Any()).Return(writeErr)
 	mc.EXPECT().WriteJSON(gomock.Any(), gomock.Any()).Return(writeErr)
 
 	mr := NewMockResponseWriter(ctrl)
-	mr.EXPECT().Len().Return(0)
-	mc.EXPECT().Response().Return(mr)
+	mr.EXPECT().Len().Return(0).Times(2)
+	mc.EXPECT().Response().Return(mr).Times(2)
 
 	buf := bytes.NewBufferString("")
 	log.SetOutput(buf)
@@ -70,6 +74,22 @@ func TestDefaultErrorHandlerPrintsErrIfWriteJSONFails(t *testing.T) {
 	assert.Contains(t, string(buf.Bytes()), writeErr.Error())
 }
 
+func TestDefaultErrorHandlerWritesJSONWhenNotAcceptable(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mr := NewMockResponseWriter(ctrl)
+	mr.EXPECT().Len().Return(0).Times(2)
+
+	mc := NewMockContext(ctrl)
+	mc.EXPECT().Response().Return(mr).Times(2)
+
+	err := NewHTTPErrorStatus(http.StatusBadRequest)
+	mc.EXPECT().Render(http.StatusBadRequest, err).Return(ErrNotAcceptable)
+	mc.EXPECT().WriteJSON(http.StatusBadRequest, err).Return(nil)
+
+	defaultErrorHandler(mc, err)
+}
+
 func TestDefaultErrorHandlerDoesNotWriteIfAlreadyWritten(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -84,7 +104,7 @@ func TestDefaultErrorHandlerDoesNotWriteIfAlreadyWritten(t *testing.T) {
 }
 
 func TestRequestParserMiddlewarePanicsWhenNilHandler(t *testing.T) {
-	handle := requestParserMiddleware(func(Context) (Handler, error) {
+	handle := requestParserMiddleware(&route{}, func(Context) (Handler, error) {
 		return nil, nil
 	})
 
@@ -95,7 +115,7 @@ func TestRequestParserMiddlewarePanicsWhenNilHandler(t *testing.T) {
 
 func TestMakeHandlerReturnsErrorWhenErrorOnCreateHandler(t *testing.T) {
 	err := errors.New("something broke")
-	handle := requestParserMiddleware(func(Context) (Handler, error) {
+	handle := requestParserMiddleware(&route{}, func(Context) (Handler, error) {
 		return nil, err
 	})
 
@@ -110,7 +130,7 @@ type badQueryHandler struct {
 func (h *badQueryHandler) Handle(Context) error { return nil }
 
 func TestRequestParserMiddlewareReturnsErrorWhenSetQueryFails(t *testing.T) {
-	handle := requestParserMiddleware(func(Context) (Handler, error) {
+	handle := requestParserMiddleware(&route{}, func(Context) (Handler, error) {
 		return &badQueryHandler{}, nil
 	})
 
@@ -132,7 +152,7 @@ type badURLParamsHandler struct {
 func (h *badURLParamsHandler) Handle(Context) error { return nil }
 
 func TestRequestParserMiddlewareReturnsErrorWhenSetURLParamsFails(t *testing.T) {
-	handle := requestParserMiddleware(func(Context) (Handler, error) {
+	handle := requestParserMiddleware(&route{}, func(Context) (Handler, error) {
 		return &badURLParamsHandler{}, nil
 	})
 
@@ -197,6 +217,144 @@ func TestRequestParserMiddlewareDoesNotPrintErrorWhenValidationError(t *testing.
 	assert.Contains(t, body, "not found")
 }
 
+type binderHandler struct {
+	Query struct {
+		Page int `validate:"max=10"`
+	}
+	URLParams struct {
+		ID int
+	}
+	queries []url.Values
+	params  []httprouter.Params
+	err     error
+}
+
+func (h *binderHandler) BindQuery(q url.Values) error {
+	h.queries = append(h.queries, q)
+	h.Query.Page = len(q.Get("page"))
+	return h.err
+}
+
+func (h *binderHandler) BindParams(p httprouter.Params) error {
+	h.params = append(h.params, p)
+	h.URLParams.ID = len(p.ByName("id"))
+	return h.err
+}
+
+func (h *binderHandler) Handle(Context) error { return nil }
+
+func TestRequestParserMiddlewareUsesBinders(t *testing.T) {
+	h := &binderHandler{}
+	r := NewRouter()
+	r.Get("/users/:id", func(Context) (Handler, error) {
+		return h, nil
+	})
+
+	req := httptest.NewRequest("GET", "/users/abc?page=xyz", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, []url.Values{{"page": {"xyz"}}}, h.queries)
+	assert.Equal(t, []httprouter.Params{{{Key: "id", Value: "abc"}}}, h.params)
+	assert.Equal(t, 3, h.Query.Page)
+	assert.Equal(t, 3, h.URLParams.ID)
+}
+
+func TestRequestParserMiddlewareValidatesBoundFields(t *testing.T) {
+	r := NewRouter()
+	r.Get("/users/:id", func(Context) (Handler, error) {
+		return &binderHandler{}, nil
+	})
+
+	req := httptest.NewRequest("GET", "/users/1?page=abcdefghijk", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestRequestParserMiddlewareReturnsBinderErrors(t *testing.T) {
+	r := NewRouter()
+	r.Get("/users/:id", func(Context) (Handler, error) {
+		return &binderHandler{err: &bind.TypeMismatchError{Kind: reflect.Int, Val: "abc", FieldName: "page"}}, nil
+	})
+
+	req := httptest.NewRequest("GET", "/users/1?page=abc", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "value(abc) is not a valid int for page")
+}
+
+// fieldlessBinderHandler implements the binders without the fields that they populate
+type fieldlessBinderHandler struct {
+	page, id string
+}
+
+func (h *fieldlessBinderHandler) BindQuery(q url.Values) error {
+	h.page = q.Get("page")
+	return nil
+}
+
+func (h *fieldlessBinderHandler) BindParams(p httprouter.Params) error {
+	h.id = p.ByName("id")
+	return nil
+}
+
+func (h *fieldlessBinderHandler) Handle(Context) error { return nil }
+
+func TestRequestParserMiddlewareUsesBindersWithoutFields(t *testing.T) {
+	h := &fieldlessBinderHandler{}
+	r := NewRouter()
+	r.Get("/users/:id", func(Context) (Handler, error) {
+		return h, nil
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/users/abc?page=2", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "2", h.page)
+	assert.Equal(t, "abc", h.id)
+}
+
+type badFieldBinderHandler struct {
+	fieldlessBinderHandler
+	Query int
+}
+
+func TestRequestParserMiddlewareRejectsBindersWithBadFields(t *testing.T) {
+	handle := requestParserMiddleware(&route{}, func(Context) (Handler, error) {
+		return &badFieldBinderHandler{}, nil
+	})
+	c := newContext(httptest.NewRequest("GET", "/users/abc", nil), httptest.NewRecorder(), nil)
+	err := handle(c)
+	require.Error(t, err)
+	assert.IsType(t, &badFieldError{}, err)
+}
+
+type responseHeadersHandler struct {
+	Headers http.Header
+}
+
+func (h *responseHeadersHandler) Handle(c Context) error {
+	for k, v := range h.Headers {
+		c.Response().Header()[k] = v
+	}
+	return c.WriteJSON(http.StatusOK, JSON{})
+}
+
+func TestRequestParserMiddlewareLeavesHeadersFieldsThatAreNotStructs(t *testing.T) {
+	r := NewRouter()
+	r.Get("/", func(Context) (Handler, error) {
+		return &responseHeadersHandler{Headers: http.Header{"X-Served-By": {"boar"}}}, nil
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "boar", rec.Header().Get("X-Served-By"))
+}
+
 type bodyHandler struct {
 	handle HandlerFunc
 	Body   struct {
@@ -215,7 +373,7 @@ func (*badBodyHandler) Handle(Context) error {
 }
 
 func TestRequestParserMiddlewareReturnsErrorWhenSetBodyFails(t *testing.T) {
-	handle := requestParserMiddleware(func(Context) (Handler, error) {
+	handle := requestParserMiddleware(&route{}, func(Context) (Handler, error) {
 		return &badBodyHandler{}, nil
 	})
 
@@ -298,7 +456,7 @@ func TestRealRouterReturnsUnderlyingRouter(t *testing.T) {
 func TestShouldCreateMethodHandlers(t *testing.T) {
 	r := NewRouter()
 
-	items := map[string]func(string, HandlerProviderFunc){
+	items := map[string]func(string, HandlerProviderFunc, ...RouteOption){
 		http.MethodGet:     r.Get,
 		http.MethodDelete:  r.Delete,
 		http.MethodHead:    r.Head,
@@ -598,13 +756,10 @@ func TestPanicHandlerConvertsPanicStringsToHTTPError(t *testing.T) {
 	rec.Flush()
 }
 
-func TestNotFoundHandlerDoesNotPrintBody(t *testing.T) {
+func TestNotFoundHandlerWritesErrorThroughErrorHandler(t *testing.T) {
 	r := NewRouter()
 	r.Use(PanicMiddleware)
 
-	done := &sync.WaitGroup{}
-	done.Add(1)
-
 	r.MethodFunc(http.MethodGet, "/hello", func(Context) error {
 		return nil
 	})
@@ -619,16 +774,15 @@ func TestNotFoundHandlerDoesNotPrintBody(t *testing.T) {
 	body, err := ioutil.ReadAll(resp.Body)
 	require.NoError(t, err)
 
-	assert.Empty(t, body)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("content-type"))
+	assert.Contains(t, string(body), http.StatusText(http.StatusNotFound))
 }
 
-func TestMethodNodAllowedHandlerDoesNotPrintBody(t *testing.T) {
+func TestMethodNotAllowedHandlerWritesErrorThroughErrorHandler(t *testing.T) {
 	r := NewRouter()
 	r.Use(PanicMiddleware)
 
-	done := &sync.WaitGroup{}
-	done.Add(1)
-
 	r.MethodFunc(http.MethodGet, "/", func(Context) error {
 		return nil
 	})
@@ -643,5 +797,287 @@ func TestMethodNodAllowedHandlerDoesNotPrintBody(t *testing.T) {
 	body, err := ioutil.ReadAll(resp.Body)
 	require.NoError(t, err)
 
-	assert.Empty(t, body)
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	assert.Contains(t, string(body), http.StatusText(http.StatusMethodNotAllowed))
+}
+
+func TestMethodNotAllowedHandlerSetsAllowHeader(t *testing.T) {
+	r := NewRouter()
+
+	r.MethodFunc(http.MethodGet, "/users/:id", func(Context) error {
+		return nil
+	})
+	r.MethodFunc(http.MethodPut, "/users/:id", func(Context) error {
+		return nil
+	})
+	r.MethodFunc(http.MethodPost, "/users", func(Context) error {
+		return nil
+	})
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/users/1", nil)
+
+	r.ServeHTTP(rec, req)
+	rec.Flush()
+	resp := rec.Result()
+
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	assert.Equal(t, "GET, PUT", resp.Header.Get("Allow"))
+}
+
+func TestMethodNotAllowedAllowHeaderIncludesCustomMethods(t *testing.T) {
+	r := NewRouter()
+
+	r.MethodFunc("PURGE", "/cache/:key", func(Context) error {
+		return nil
+	})
+	r.MethodFunc(http.MethodDelete, "/cache/:key", func(Context) error {
+		return nil
+	})
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/cache/users", nil)
+
+	r.ServeHTTP(rec, req)
+	resp := rec.Result()
+
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	assert.Equal(t, "PURGE, DELETE", resp.Header.Get("Allow"))
+}
+
+func TestMethodNotAllowedAllowHeaderIncludesOptionsRoutes(t *testing.T) {
+	r := NewRouter()
+
+	r.MethodFunc(http.MethodGet, "/users", func(Context) error {
+		return nil
+	})
+	r.MethodFunc(http.MethodOptions, "/users", func(Context) error {
+		return nil
+	})
+	r.MethodFunc(http.MethodGet, "/orders", func(Context) error {
+		return nil
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/users", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Equal(t, "GET, OPTIONS", rec.Header().Get("Allow"))
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodOptions, "/orders", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code, "OPTIONS is not answered without a route")
+	assert.Equal(t, "GET", rec.Header().Get("Allow"))
+}
+
+func TestNotFoundUsesCustomHandlerAndMiddlewares(t *testing.T) {
+	r := NewRouter()
+
+	var calls int32
+	r.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c Context) error {
+			atomic.AddInt32(&calls, 1)
+			return next(c)
+		}
+	})
+
+	r.NotFound(func(c Context) error {
+		return c.WriteJSON(http.StatusNotFound, JSON{"path": c.Request().URL.Path})
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/missing", nil))
+	rec.Flush()
+	resp := rec.Result()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	assert.EqualValues(t, 1, calls)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Contains(t, string(body), "/missing")
+}
+
+func TestMethodNotAllowedUsesCustomHandler(t *testing.T) {
+	r := NewRouter()
+
+	r.MethodFunc(http.MethodGet, "/", func(Context) error {
+		return nil
+	})
+
+	r.MethodNotAllowed(func(c Context) error {
+		return ErrTooManyRequests
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/", nil))
+	rec.Flush()
+	resp := rec.Result()
+
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, http.MethodGet, resp.Header.Get("Allow"))
+}
+
+func TestStreamResponseWritesBeforeHandlerReturns(t *testing.T) {
+	r := NewRouter()
+
+	var status, length int
+	r.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c Context) error {
+			err := next(c)
+			status, length = c.Response().Status(), c.Response().Len()
+			return err
+		}
+	})
+
+	rec := httptest.NewRecorder()
+	r.MethodFunc(http.MethodGet, "/", func(c Context) error {
+		c.WriteStatus(http.StatusAccepted)
+		fmt.Fprint(c.Response(), "first")
+		require.NoError(t, c.Response().Flush())
+		assert.Equal(t, "first", rec.Body.String(), "first write should not be buffered")
+		assert.True(t, rec.Flushed)
+
+		fmt.Fprint(c.Response(), " second")
+		return nil
+	}, StreamResponse())
+
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.Equal(t, "first second", rec.Body.String())
+	assert.Equal(t, http.StatusAccepted, status)
+	assert.Equal(t, len("first second"), length)
+}
+
+func TestStreamResponseErrorBeforeWriteUsesErrorHandler(t *testing.T) {
+	r := NewRouter()
+	r.MethodFunc(http.MethodGet, "/", func(c Context) error {
+		return ErrForbidden
+	}, StreamResponse())
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Equal(t, contentTypeJSON, rec.Header().Get("content-type"))
+	assert.Contains(t, rec.Body.String(), "Forbidden")
+}
+
+func TestStreamPerRequest(t *testing.T) {
+	r := NewRouter()
+	rec := httptest.NewRecorder()
+	r.MethodFunc(http.MethodGet, "/", func(c Context) error {
+		c.Response().Header().Set("content-type", "text/plain")
+		require.NoError(t, c.Response().Stream())
+		fmt.Fprint(c.Response(), "streamed")
+		assert.Equal(t, "streamed", rec.Body.String())
+		return nil
+	})
+
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/plain", rec.Header().Get("content-type"))
+}
+
+func TestStreamErrorBeforeFirstWriteUsesErrorStatus(t *testing.T) {
+	r := NewRouter()
+	r.MethodFunc(http.MethodGet, "/", func(c Context) error {
+		require.NoError(t, c.Response().Stream())
+		return ErrForbidden
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Equal(t, contentTypeJSON, rec.Header().Get("content-type"))
+	assert.Contains(t, rec.Body.String(), "Forbidden")
+}
+
+func TestResponseLargerThanMaxSizeUsesErrorHandler(t *testing.T) {
+	r := NewRouter()
+	r.BufferOptions = BufferOptions{MaxSize: 16}
+	r.MethodFunc(http.MethodGet, "/", func(c Context) error {
+		c.Response().Header().Set("content-type", "text/plain")
+		_, err := c.Response().Write(bytes.Repeat([]byte("a"), 32))
+		return err
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusInsufficientStorage, rec.Code)
+	assert.Equal(t, contentTypeJSON, rec.Header().Get("content-type"))
+	assert.NotContains(t, rec.Body.String(), "aaaa")
+}
+
+func TestResponseLargerThanMaxSizeWithIgnoredWriteError(t *testing.T) {
+	r := NewRouter()
+	r.MethodFunc(http.MethodGet, "/", func(c Context) error {
+		fmt.Fprint(c.Response(), strings.Repeat("a", 32))
+		return nil
+	}, WithBufferOptions(BufferOptions{MaxSize: 16}))
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusInsufficientStorage, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "aaaa")
+}
+
+func TestWriteJSONLargerThanMaxSizeUsesErrorHandler(t *testing.T) {
+	r := NewRouter()
+	r.MethodFunc(http.MethodGet, "/", func(c Context) error {
+		return c.WriteJSON(http.StatusOK, strings.Repeat("a", 32))
+	}, WithBufferOptions(BufferOptions{MaxSize: 16}))
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusInsufficientStorage, rec.Code)
+}
+
+func TestStreamAfterResponseLargerThanMaxSizeUsesErrorHandler(t *testing.T) {
+	for name, stream := range map[string]func(Context) error{
+		"Stream": func(c Context) error {
+			return c.Response().Stream()
+		},
+		"SSE": func(c Context) error {
+			_, err := c.SSE()
+			return err
+		},
+		"StreamJSON": func(c Context) error {
+			_, err := c.StreamJSON(http.StatusOK)
+			return err
+		},
+	} {
+		stream := stream
+		r := NewRouter()
+		r.MethodFunc(http.MethodGet, "/", func(c Context) error {
+			fmt.Fprint(c.Response(), strings.Repeat("a", 32))
+			return stream(c)
+		}, WithBufferOptions(BufferOptions{MaxSize: 16}))
+
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		assert.Equal(t, http.StatusInsufficientStorage, rec.Code, name)
+		assert.Equal(t, contentTypeJSON, rec.Header().Get("content-type"), name)
+		assert.NotContains(t, rec.Body.String(), "aaaa", name)
+		assert.Empty(t, rec.Header().Get("Trailer"), name)
+	}
+}
+
+func TestSpilledResponseIsSent(t *testing.T) {
+	r := NewRouter()
+	r.BufferOptions = BufferOptions{MemoryThreshold: 4, TempDir: t.TempDir()}
+	r.MethodFunc(http.MethodGet, "/", func(c Context) error {
+		return c.WriteJSON(http.StatusCreated, strings.Repeat("a", 32))
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, `"`+strings.Repeat("a", 32)+`"`+"\n", rec.Body.String())
 }
@@ -0,0 +1,226 @@
+package boar
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"runtime"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/blockloop/boar/internal/pattern"
+)
+
+// RouteInfo describes a route registered with the Router
+type RouteInfo struct {
+	// Method is * for handlers served with Mount and MountRouter, which are listed once
+	// with the catch-all Path beneath their prefix
+	Method string
+	Path   string
+	Name   string
+
+	// Mount is set for handlers served with Mount and MountRouter. Their requests are not
+	// described by the route table, so they are left out of generated documents and clients
+	Mount bool
+
+	// Handler is the type of the handler, or the name of the function for routes registered
+	// with MethodFunc. It is empty when the handler could not be determined
+	Handler string
+
+	// HandlerType is the type of the Handler returned by the HandlerProviderFunc of the
+	// route given by Handles, or nil when it is unknown
+	HandlerType reflect.Type
+
+	// Query, URLParams, Headers and Body are the types of the fields of the handler that
+	// are populated from the request, or nil when the handler has no such field. Body is
+	// nil for routes registered with StreamBody
+	Query     reflect.Type
+	URLParams reflect.Type
+	Headers   reflect.Type
+	Body      reflect.Type
+
+	// Response is the type of the body of a successful response given by Returns, or nil
+	Response reflect.Type
+
+	// Consumes are the content types accepted for the request body
+	Consumes []string
+
+	// Middlewares are the names of the global middlewares that requests pass through, in
+	// the order in which they are executed
+	Middlewares []string
+}
+
+// MarshalJSON encodes the types of the route by name
+func (ri RouteInfo) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Method      string   `json:"method"`
+		Path        string   `json:"path"`
+		Name        string   `json:"name,omitempty"`
+		Mount       bool     `json:"mount,omitempty"`
+		Handler     string   `json:"handler,omitempty"`
+		Query       string   `json:"query,omitempty"`
+		URLParams   string   `json:"urlParams,omitempty"`
+		Headers     string   `json:"headers,omitempty"`
+		Body        string   `json:"body,omitempty"`
+		Response    string   `json:"response,omitempty"`
+		Consumes    []string `json:"consumes,omitempty"`
+		Middlewares []string `json:"middlewares"`
+	}{
+		Method:      ri.Method,
+		Path:        ri.Path,
+		Name:        ri.Name,
+		Mount:       ri.Mount,
+		Handler:     ri.Handler,
+		Query:       typeName(ri.Query),
+		URLParams:   typeName(ri.URLParams),
+		Headers:     typeName(ri.Headers),
+		Body:        typeName(ri.Body),
+		Response:    typeName(ri.Response),
+		Consumes:    ri.Consumes,
+		Middlewares: ri.Middlewares,
+	})
+}
+
+// RouteParam is a :param or *catchall segment of the path of a route
+type RouteParam = pattern.Param
+
+// Params returns the parameters of the path of the route in order
+func (ri RouteInfo) Params() []RouteParam {
+	return pattern.Params(ri.Path)
+}
+
+// Routes returns the route table of the Router in the order in which routes were
+// registered. The handler of a route registered with Method or WebSocket is described by
+// the Handles RouteOption. Each handler served with Mount is listed once
+func (rtr *Router) Routes() []RouteInfo {
+	middlewares := make([]string, len(rtr.middlewares))
+	for i, mw := range rtr.middlewares {
+		middlewares[i] = funcName(mw)
+	}
+
+	routes := make([]RouteInfo, 0, len(rtr.routes))
+	for _, rt := range rtr.routes {
+		if rt.hidden {
+			continue
+		}
+		ri := RouteInfo{
+			Method:      rt.method,
+			Path:        rt.path,
+			Name:        rt.name,
+			Mount:       rt.mounted,
+			Handler:     rt.handlerName,
+			HandlerType: rt.handlerType,
+			Response:    rt.responseType,
+			Consumes:    rt.consumes,
+			Middlewares: middlewares,
+		}
+		if t := rt.handlerType; t != nil {
+			ri.Handler = t.String()
+			for t.Kind() == reflect.Ptr {
+				t = t.Elem()
+			}
+			ri.Query = fieldType(t, queryField)
+			ri.URLParams = fieldType(t, urlParamsField)
+			// Headers fields which are not structs are not bound from the request
+			if h := fieldType(t, headersField); h != nil && h.Kind() == reflect.Struct {
+				ri.Headers = h
+			}
+			if !rt.streamBody {
+				ri.Body = fieldType(t, bodyField)
+			}
+		}
+		if rt.mounted {
+			ri.Method = "*"
+		}
+		routes = append(routes, ri)
+	}
+	return routes
+}
+
+// WriteRoutes writes routes to w as a table with a row for each route
+func WriteRoutes(w io.Writer, routes []RouteInfo) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "METHOD\tPATH\tNAME\tHANDLER\tQUERY\tURLPARAMS\tHEADERS\tBODY\tMIDDLEWARES")
+	for _, ri := range routes {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			ri.Method,
+			ri.Path,
+			orDash(ri.Name),
+			orDash(ri.Handler),
+			orDash(typeName(ri.Query)),
+			orDash(typeName(ri.URLParams)),
+			orDash(typeName(ri.Headers)),
+			orDash(typeName(ri.Body)),
+			orDash(strings.Join(ri.Middlewares, ",")),
+		)
+	}
+	return tw.Flush()
+}
+
+// RoutesHandler returns a handler which lists the routes of rtr for debugging. The routes
+// are written as JSON when the format query parameter is json or the client prefers
+// application/json to text/plain, and as the table of WriteRoutes otherwise
+//
+//	rtr.MethodFunc(http.MethodGet, "/debug/routes", rtr.RoutesHandler())
+func (rtr *Router) RoutesHandler() HandlerFunc {
+	return func(c Context) error {
+		routes := rtr.Routes()
+
+		format := c.Request().URL.Query().Get("format")
+		if format == "" {
+			ranges := parseAccept(c.Request().Header.Get("accept"))
+			if quality(ranges, contentTypeJSON) > quality(ranges, contentTypeTextPlain) {
+				format = "json"
+			}
+		}
+		if format == "json" {
+			return c.WriteJSON(http.StatusOK, routes)
+		}
+
+		c.Response().Header().Set("content-type", contentTypeTextPlain+"; charset=utf-8")
+		c.WriteStatus(http.StatusOK)
+		return WriteRoutes(c.Response(), routes)
+	}
+}
+
+// fieldType returns the type of the named field of the struct type t, or nil if there is
+// no such field
+func fieldType(t reflect.Type, name string) reflect.Type {
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	f, ok := t.FieldByName(name)
+	if !ok {
+		return nil
+	}
+	return f.Type
+}
+
+// funcName returns the name of the function fn, qualified by the name of its package
+func funcName(fn interface{}) string {
+	f := runtime.FuncForPC(reflect.ValueOf(fn).Pointer())
+	if f == nil {
+		return ""
+	}
+	name := strings.TrimSuffix(f.Name(), "-fm")
+	if i := strings.LastIndexByte(name, '/'); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
+
+func typeName(t reflect.Type) string {
+	if t == nil {
+		return ""
+	}
+	return t.String()
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,212 @@
+package boar
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type listOrdersHandler struct {
+	Query struct {
+		Page int `query:"page"`
+	}
+	URLParams struct {
+		UserID int `url:"id"`
+	}
+}
+
+func (h *listOrdersHandler) Handle(Context) error { return nil }
+
+type createOrderHandler struct {
+	Headers struct {
+		IdempotencyKey string `header:"Idempotency-Key"`
+	}
+	Body struct {
+		Item string `json:"item"`
+	}
+}
+
+func (h *createOrderHandler) Handle(Context) error { return nil }
+
+func loggingMiddleware(next HandlerFunc) HandlerFunc { return next }
+
+func TestRoutesDescribesHandlers(t *testing.T) {
+	r := NewRouter()
+	r.Use(loggingMiddleware)
+	r.Get("/users/:id/orders", func(Context) (Handler, error) {
+		return &listOrdersHandler{}, nil
+	}, Name("user.orders"), Handles(&listOrdersHandler{}), Returns([]string{}))
+	r.Post("/orders", func(Context) (Handler, error) {
+		return &createOrderHandler{}, nil
+	}, Handles(&createOrderHandler{}), Consumes(contentTypeJSON))
+
+	routes := r.Routes()
+	require.Len(t, routes, 2)
+
+	list := routes[0]
+	assert.Equal(t, http.MethodGet, list.Method)
+	assert.Equal(t, "/users/:id/orders", list.Path)
+	assert.Equal(t, "user.orders", list.Name)
+	assert.Equal(t, "*boar.listOrdersHandler", list.Handler)
+	assert.Equal(t, reflect.TypeOf(&listOrdersHandler{}), list.HandlerType)
+	assert.Equal(t, reflect.TypeOf(listOrdersHandler{}.Query), list.Query)
+	assert.Equal(t, reflect.TypeOf(listOrdersHandler{}.URLParams), list.URLParams)
+	assert.Nil(t, list.Headers)
+	assert.Nil(t, list.Body)
+	assert.Equal(t, reflect.TypeOf([]string{}), list.Response)
+	assert.Equal(t, []string{"boar.loggingMiddleware"}, list.Middlewares)
+
+	create := routes[1]
+	assert.Equal(t, http.MethodPost, create.Method)
+	assert.Equal(t, reflect.TypeOf(createOrderHandler{}.Headers), create.Headers)
+	assert.Equal(t, reflect.TypeOf(createOrderHandler{}.Body), create.Body)
+	assert.Equal(t, []string{contentTypeJSON}, create.Consumes)
+	assert.Nil(t, create.Response)
+}
+
+func TestRoutesDescribesFuncHandlers(t *testing.T) {
+	r := NewRouter()
+	r.MethodFunc(http.MethodGet, "/health", noopHandler)
+	r.Mount("/legacy", http.NotFoundHandler())
+
+	routes := r.Routes()
+	assert.Equal(t, "boar.noopHandler", routes[0].Handler)
+	assert.Nil(t, routes[0].HandlerType)
+	assert.Equal(t, "http.HandlerFunc", routes[1].Handler)
+}
+
+func TestRoutesListsMountsOnce(t *testing.T) {
+	r := NewRouter()
+	r.Mount("/legacy", http.NotFoundHandler(), Name("legacy"))
+	r.MethodFunc(http.MethodGet, "/health", noopHandler)
+
+	routes := r.Routes()
+	require.Len(t, routes, 2)
+	assert.Equal(t, "*", routes[0].Method)
+	assert.Equal(t, "/legacy/*mountpath", routes[0].Path)
+	assert.Equal(t, "legacy", routes[0].Name)
+	assert.True(t, routes[0].Mount)
+	assert.False(t, routes[1].Mount)
+
+	u, err := r.URL("legacy", "mountpath", "debug/vars")
+	require.NoError(t, err)
+	assert.Equal(t, "/legacy/debug/vars", u)
+
+	named := 0
+	for _, rt := range r.routes {
+		if rt.name == "legacy" {
+			named++
+		}
+	}
+	assert.Equal(t, 1, named)
+}
+
+func TestRoutesDoesNotCallProviders(t *testing.T) {
+	r := NewRouter()
+	r.Get("/orders", func(c Context) (Handler, error) {
+		t.Error("provider should not be called when the route is registered")
+		return &listOrdersHandler{}, nil
+	})
+
+	ri := r.Routes()[0]
+	assert.Empty(t, ri.Handler)
+	assert.Nil(t, ri.HandlerType)
+	assert.Nil(t, ri.Query)
+}
+
+func TestRoutesOmitsStreamedBody(t *testing.T) {
+	r := NewRouter()
+	r.Post("/upload", func(Context) (Handler, error) {
+		return &createOrderHandler{}, nil
+	}, Handles(&createOrderHandler{}), StreamBody())
+
+	assert.Nil(t, r.Routes()[0].Body)
+}
+
+func TestRoutesIncludesWebSockets(t *testing.T) {
+	r := NewRouter()
+	r.WebSocket("/ws", func(Context) (WebSocketHandler, error) {
+		return webSocketHandlerFunc(nil), nil
+	}, Name("ws"), Handles(webSocketHandlerFunc(nil)))
+
+	routes := r.Routes()
+	require.Len(t, routes, 1)
+	assert.Equal(t, http.MethodGet, routes[0].Method)
+	assert.Equal(t, "boar.webSocketHandlerFunc", routes[0].Handler)
+
+	u, err := r.URL("ws")
+	require.NoError(t, err)
+	assert.Equal(t, "/ws", u)
+}
+
+func TestRouteInfoMarshalJSON(t *testing.T) {
+	r := NewRouter()
+	r.Get("/users/:id/orders", func(Context) (Handler, error) {
+		return &listOrdersHandler{}, nil
+	}, Name("user.orders"), Handles(&listOrdersHandler{}))
+
+	b, err := json.Marshal(r.Routes()[0])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"method": "GET",
+		"path": "/users/:id/orders",
+		"name": "user.orders",
+		"handler": "*boar.listOrdersHandler",
+		"query": "struct { Page int \"query:\\\"page\\\"\" }",
+		"urlParams": "struct { UserID int \"url:\\\"id\\\"\" }",
+		"middlewares": []
+	}`, string(b))
+}
+
+func TestWriteRoutes(t *testing.T) {
+	r := NewRouter()
+	r.MethodFunc(http.MethodGet, "/health", noopHandler, Name("health"))
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, WriteRoutes(buf, r.Routes()))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Equal(t, []string{"METHOD", "PATH", "NAME", "HANDLER", "QUERY", "URLPARAMS", "HEADERS", "BODY", "MIDDLEWARES"}, strings.Fields(lines[0]))
+	assert.Equal(t, []string{"GET", "/health", "health", "boar.noopHandler", "-", "-", "-", "-", "-"}, strings.Fields(lines[1]))
+}
+
+func TestRoutesHandler(t *testing.T) {
+	r := NewRouter()
+	r.MethodFunc(http.MethodGet, "/debug/routes", r.RoutesHandler())
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/routes", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/plain; charset=utf-8", rec.Header().Get("content-type"))
+	assert.Contains(t, rec.Body.String(), "/debug/routes")
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/routes?format=json", nil))
+	assert.Equal(t, contentTypeJSON, rec.Header().Get("content-type"))
+	var routes []map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &routes))
+	require.Len(t, routes, 1)
+	assert.Equal(t, "/debug/routes", routes[0]["path"])
+
+	req := httptest.NewRequest(http.MethodGet, "/debug/routes", nil)
+	req.Header.Set("accept", "application/json")
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, contentTypeJSON, rec.Header().Get("content-type"))
+}
+
+func TestRouteInfoParams(t *testing.T) {
+	ri := RouteInfo{Path: "/users/:id/files/*filepath"}
+	assert.Equal(t, []RouteParam{{Name: "id"}, {Name: "filepath", CatchAll: true}}, ri.Params())
+	assert.Equal(t, ":id", ri.Params()[0].String())
+	assert.Equal(t, "*filepath", ri.Params()[1].String())
+	assert.Empty(t, RouteInfo{Path: "/health"}.Params())
+}
@@ -0,0 +1,178 @@
+package schema
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// formats are the validate rules which correspond to a JSON Schema format
+var formats = map[string]string{
+	"email":    "email",
+	"url":      "uri",
+	"uri":      "uri",
+	"uuid":     "uuid",
+	"uuid4":    "uuid",
+	"ipv4":     "ipv4",
+	"ipv6":     "ipv6",
+	"hostname": "hostname",
+	"datetime": "date-time",
+}
+
+// HasRule returns true if the validate tag contains rule before any dive
+func HasRule(validate, rule string) bool {
+	for _, r := range strings.Split(validate, ",") {
+		if r == "dive" {
+			return false
+		}
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplyRules adds the constraints equivalent to the rules of a validate tag to s, the schema
+// of a value of type t. Rules following dive apply to the items of slices and the values of
+// maps. Rules without an equivalent, such as those combined with |, are ignored
+func ApplyRules(s *Schema, t reflect.Type, validate string) {
+	if validate == "" {
+		return
+	}
+	t = indirect(t)
+	rules := strings.Split(validate, ",")
+	for i, rule := range rules {
+		if rule == "dive" {
+			rest := strings.Join(rules[i+1:], ",")
+			switch {
+			case s.Items != nil && s.Items.Ref == "":
+				ApplyRules(s.Items, t.Elem(), rest)
+			case s.AdditionalProperties != nil && s.AdditionalProperties.Ref == "":
+				ApplyRules(s.AdditionalProperties, t.Elem(), rest)
+			}
+			return
+		}
+
+		name, param := rule, ""
+		if j := strings.IndexByte(rule, '='); j >= 0 {
+			name, param = rule[:j], rule[j+1:]
+		}
+		switch name {
+		case "min", "gte":
+			setMin(s, t, param, false)
+		case "max", "lte":
+			setMax(s, t, param, false)
+		case "gt":
+			setMin(s, t, param, true)
+		case "lt":
+			setMax(s, t, param, true)
+		case "len":
+			if isNumber(t) {
+				if v, ok := parseNumber(t, param); ok {
+					s.Const = v
+				}
+				continue
+			}
+			setMin(s, t, param, false)
+			setMax(s, t, param, false)
+		case "oneof":
+			s.Enum = nil
+			for _, v := range strings.Fields(param) {
+				if n, ok := parseNumber(t, v); ok {
+					s.Enum = append(s.Enum, n)
+				} else {
+					s.Enum = append(s.Enum, v)
+				}
+			}
+		default:
+			if format, ok := formats[name]; ok {
+				s.Format = format
+			}
+		}
+	}
+}
+
+// setMin constrains the minimum value of numbers, the length of strings and the number of
+// items of slices and maps. Exclusive bounds of lengths are converted to inclusive bounds
+func setMin(s *Schema, t reflect.Type, param string, exclusive bool) {
+	if isNumber(t) {
+		if f, err := strconv.ParseFloat(param, 64); err == nil {
+			if exclusive {
+				s.ExclusiveMinimum = &f
+			} else {
+				s.Minimum = &f
+			}
+		}
+		return
+	}
+	n, err := strconv.Atoi(param)
+	if err != nil {
+		return
+	}
+	if exclusive {
+		n++
+	}
+	switch t.Kind() {
+	case reflect.String:
+		s.MinLength = &n
+	case reflect.Slice, reflect.Array:
+		s.MinItems = &n
+	case reflect.Map:
+		s.MinProperties = &n
+	}
+}
+
+// setMax is the counterpart of setMin for upper bounds
+func setMax(s *Schema, t reflect.Type, param string, exclusive bool) {
+	if isNumber(t) {
+		if f, err := strconv.ParseFloat(param, 64); err == nil {
+			if exclusive {
+				s.ExclusiveMaximum = &f
+			} else {
+				s.Maximum = &f
+			}
+		}
+		return
+	}
+	n, err := strconv.Atoi(param)
+	if err != nil {
+		return
+	}
+	if exclusive {
+		n--
+	}
+	switch t.Kind() {
+	case reflect.String:
+		s.MaxLength = &n
+	case reflect.Slice, reflect.Array:
+		s.MaxItems = &n
+	case reflect.Map:
+		s.MaxProperties = &n
+	}
+}
+
+func isNumber(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// parseNumber parses v as a number if t is numeric
+func parseNumber(t reflect.Type, v string) (interface{}, bool) {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(v, 10, 64)
+		return n, err == nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(v, 10, 64)
+		return n, err == nil
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(v, 64)
+		return n, err == nil
+	}
+	return nil, false
+}
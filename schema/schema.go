@@ -0,0 +1,349 @@
+// Package schema reflects Go types into JSON Schema (draft 2020-12) documents. Struct fields
+// are described by the same tags that boar uses to bind and validate them, so the schemas of
+// the Query, URLParams and Body types of handlers can be used to validate requests before
+// they are sent
+package schema
+
+import (
+	"encoding"
+	"encoding/json"
+	"mime/multipart"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Draft is the URI of the JSON Schema dialect of documents
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// DefsPrefix is the prefix of the references to the definitions of a document
+const DefsPrefix = "#/$defs/"
+
+// Schema is a JSON Schema. Only the keywords that can be derived from Go types and validate
+// tags are included
+type Schema struct {
+	Schema string             `json:"$schema,omitempty"`
+	Ref    string             `json:"$ref,omitempty"`
+	Defs   map[string]*Schema `json:"$defs,omitempty"`
+
+	Type     string        `json:"type,omitempty"`
+	Nullable bool          `json:"-"`
+	Format   string        `json:"format,omitempty"`
+	Enum     []interface{} `json:"enum,omitempty"`
+	Const    interface{}   `json:"const,omitempty"`
+
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	MinProperties        *int               `json:"minProperties,omitempty"`
+	MaxProperties        *int               `json:"maxProperties,omitempty"`
+
+	Items    *Schema `json:"items,omitempty"`
+	MinItems *int    `json:"minItems,omitempty"`
+	MaxItems *int    `json:"maxItems,omitempty"`
+
+	MinLength *int `json:"minLength,omitempty"`
+	MaxLength *int `json:"maxLength,omitempty"`
+
+	Minimum          *float64 `json:"minimum,omitempty"`
+	Maximum          *float64 `json:"maximum,omitempty"`
+	ExclusiveMinimum *float64 `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum *float64 `json:"exclusiveMaximum,omitempty"`
+
+	// AnyOf is used to allow null in place of a reference
+	AnyOf []*Schema `json:"anyOf,omitempty"`
+}
+
+// MarshalJSON encodes the type of a Nullable schema as an array of Type and "null", so that
+// the schema also allows null
+func (s Schema) MarshalJSON() ([]byte, error) {
+	type plain Schema
+	if !s.Nullable || s.Type == "" {
+		return json.Marshal(plain(s))
+	}
+	return json.Marshal(struct {
+		plain
+		Type []string `json:"type"`
+	}{plain(s), []string{s.Type, "null"}})
+}
+
+// For returns a JSON Schema document describing the type of v. Named struct types are
+// described once in $defs and referenced wherever they are used, including by the root of
+// the document. Struct fields are named by their json tags
+func For(v interface{}) *Schema {
+	return ForTag(v, "json")
+}
+
+// ForTag returns a JSON Schema document describing the type of v like For, but the
+// properties of the root struct are named by the tagKey tag, such as query or url for the
+// Query and URLParams of a handler
+func ForTag(v interface{}, tagKey string) *Schema {
+	r := NewReflector(DefsPrefix)
+	t := reflect.TypeOf(v)
+	var s *Schema
+	if t == nil {
+		s = &Schema{}
+	} else if tagKey == "json" {
+		s = r.Reflect(t)
+	} else {
+		s = r.object(indirect(t), tagKey)
+	}
+	s.Schema = Draft
+	s.Defs = r.Defs()
+	return s
+}
+
+// Reflector builds the schemas of Go types. The named struct types that are reflected are
+// collected as definitions, so that a Reflector can be used to build many schemas which
+// share definitions, as in the components of an OpenAPI document
+type Reflector struct {
+	prefix string
+	defs   map[string]*Schema
+	names  map[reflect.Type]string
+}
+
+// NewReflector creates a Reflector which references definitions by prefix followed by their
+// name, such as DefsPrefix or "#/components/schemas/"
+func NewReflector(prefix string) *Reflector {
+	return &Reflector{
+		prefix: prefix,
+		defs:   make(map[string]*Schema),
+		names:  make(map[reflect.Type]string),
+	}
+}
+
+// Defs returns the definitions of the named struct types reflected so far, keyed by name
+func (r *Reflector) Defs() map[string]*Schema {
+	if len(r.defs) == 0 {
+		return nil
+	}
+	return r.defs
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	fileHeaderType    = reflect.TypeOf(multipart.FileHeader{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// Encoded returns the schema of the types which encoding/json does not encode by their kind.
+// time.Time is a date-time string, the JSON of other json.Marshalers is not known,
+// encoding.TextMarshalers are strings and byte slices are base64 strings. ok is false for
+// every other type, including pointers to these types
+func Encoded(t reflect.Type) (s *Schema, ok bool) {
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}, true
+	case t.Kind() == reflect.Ptr:
+		return nil, false
+	case implements(t, jsonMarshalerType):
+		return &Schema{}, true
+	case implements(t, textMarshalerType):
+		return &Schema{Type: "string"}, true
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		// arrays of bytes are encoded as arrays of numbers
+		return &Schema{Type: "string", Format: "byte"}, true
+	}
+	return nil, false
+}
+
+// implements reports whether t or a pointer to t implements iface, as encoding/json uses
+// the methods of addressable values
+func implements(t, iface reflect.Type) bool {
+	return t.Implements(iface) || reflect.PtrTo(t).Implements(iface)
+}
+
+// Reflect returns the schema of t. Named struct types are described by a reference to their
+// definition. A pointer at the root is described by the type it points to, but pointers
+// within t, such as the elements of slices and the fields of structs, also allow null as
+// encoding/json encodes nil pointers as null
+func (r *Reflector) Reflect(t reflect.Type) *Schema {
+	t = indirect(t)
+
+	if t == fileHeaderType {
+		return &Schema{Type: "string", Format: "binary"}
+	}
+	if s, ok := Encoded(t); ok {
+		return s
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: r.value(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: r.value(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return r.object(t, "json")
+		}
+		name, ok := r.names[t]
+		if !ok {
+			name = r.defName(t)
+			r.names[t] = name
+			// the name is reserved before the object is described so that recursive
+			// types refer to themselves
+			r.defs[name] = nil
+			r.defs[name] = r.object(t, "json")
+		}
+		return &Schema{Ref: r.prefix + name}
+	}
+	// interfaces and other kinds accept any value
+	return &Schema{}
+}
+
+// ReflectField returns the schema of the struct field f with the constraints of its
+// validate tag. Constraints are not added to references
+func (r *Reflector) ReflectField(f reflect.StructField) *Schema {
+	s := r.Reflect(f.Type)
+	if s.Ref == "" {
+		ApplyRules(s, f.Type, f.Tag.Get("validate"))
+	}
+	return s
+}
+
+// value returns the schema of a value of type t within a JSON document, which is null when
+// t is a pointer
+func (r *Reflector) value(t reflect.Type) *Schema {
+	s := r.Reflect(t)
+	if isNullable(t) {
+		return nullable(s)
+	}
+	return s
+}
+
+// isNullable reports whether JSON values of type t may be null. Uploaded files are parts of
+// multipart forms, which have no null
+func isNullable(t reflect.Type) bool {
+	return t.Kind() == reflect.Ptr && indirect(t) != fileHeaderType
+}
+
+// nullable returns s allowing null as well. Schemas without a type already allow null
+func nullable(s *Schema) *Schema {
+	switch {
+	case s.Ref != "":
+		return &Schema{AnyOf: []*Schema{s, {Type: "null"}}}
+	case s.Type != "":
+		s.Nullable = true
+		if len(s.Enum) > 0 {
+			s.Enum = append(s.Enum, nil)
+		}
+	}
+	return s
+}
+
+// defName returns a unique name for the definition of the named type t
+func (r *Reflector) defName(t reflect.Type) string {
+	name := t.Name()
+	if _, taken := r.defs[name]; !taken {
+		return name
+	}
+	qualified := path.Base(t.PkgPath()) + "." + t.Name()
+	name = qualified
+	for i := 2; ; i++ {
+		if _, taken := r.defs[name]; !taken {
+			return name
+		}
+		name = qualified + strconv.Itoa(i)
+	}
+}
+
+// object describes the exported fields of the struct type t by the names given by the
+// tagKey tag
+func (r *Reflector) object(t reflect.Type, tagKey string) *Schema {
+	if t.Kind() != reflect.Struct {
+		return r.Reflect(t)
+	}
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	r.properties(t, tagKey, s)
+	return s
+}
+
+func (r *Reflector) properties(t reflect.Type, tagKey string, s *Schema) {
+	for _, f := range Fields(t, tagKey) {
+		switch {
+		case strings.Contains(f.Opts, ",string"):
+			s.Properties[f.Key] = &Schema{Type: "string"}
+		case tagKey == "json" && isNullable(f.Type):
+			// only JSON encodes nil pointers as null; parameters are omitted instead
+			s.Properties[f.Key] = nullable(r.ReflectField(f.StructField))
+		default:
+			s.Properties[f.Key] = r.ReflectField(f.StructField)
+		}
+		if HasRule(f.Tag.Get("validate"), "required") {
+			s.Required = append(s.Required, f.Key)
+		}
+	}
+}
+
+// Field is a struct field with the name and options given by a tag
+type Field struct {
+	reflect.StructField
+	// Key is the name of the field given by the tag
+	Key string
+	// Opts are the options of the tag that follow the name, such as ",omitempty"
+	Opts string
+}
+
+// Fields returns the fields of the struct type t which are named by the tagKey tag, in the
+// order they are declared. The fields of embedded structs are promoted unless the struct is
+// named by a tag, and unexported fields and fields named "-" are skipped, as they are by
+// encoding/json
+func Fields(t reflect.Type, tagKey string) []Field {
+	var fields []Field
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, opts := FieldName(f, tagKey)
+		if name == "-" && opts == "" {
+			continue
+		}
+
+		ft := indirect(f.Type)
+		if f.Anonymous && ft.Kind() == reflect.Struct && strings.Split(f.Tag.Get(tagKey), ",")[0] == "" {
+			fields = append(fields, Fields(ft, tagKey)...)
+			continue
+		}
+		// embedded structs of unexported types are encoded when they are named by a tag
+		if f.PkgPath != "" && !(f.Anonymous && ft.Kind() == reflect.Struct) {
+			continue
+		}
+		fields = append(fields, Field{StructField: f, Key: name, Opts: opts})
+	}
+	return fields
+}
+
+// FieldName returns the name of f given by the tagKey tag, or the name of the field when
+// the tag is not set or is empty, and the options of the tag that follow the name. The name
+// is "-" for fields which are ignored
+func FieldName(f reflect.StructField, tagKey string) (name, opts string) {
+	tag := f.Tag.Get(tagKey)
+	name = tag
+	if i := strings.IndexByte(tag, ','); i >= 0 {
+		name, opts = tag[:i], tag[i:]
+	}
+	if name == "" {
+		name = f.Name
+	}
+	return name, opts
+}
+
+func indirect(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
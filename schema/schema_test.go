@@ -0,0 +1,324 @@
+package schema
+
+import (
+	"encoding/json"
+	"mime/multipart"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func marshal(t *testing.T, v interface{}) string {
+	b, err := json.Marshal(v)
+	require.NoError(t, err)
+	return string(b)
+}
+
+type address struct {
+	Street string `json:"street" validate:"required"`
+	City   string `json:"city" validate:"required,max=100"`
+}
+
+type timestamps struct {
+	CreatedAt time.Time  `json:"createdAt"`
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
+}
+
+type user struct {
+	timestamps
+	ID       int64              `json:"id,string"`
+	Name     string             `json:"name" validate:"required,min=2,max=50"`
+	Email    string             `json:"email" validate:"required,email"`
+	Age      *int               `json:"age,omitempty" validate:"omitempty,gte=18,lt=130"`
+	Score    float64            `json:"score" validate:"gt=0,lte=1"`
+	Role     string             `json:"role" validate:"oneof=admin member guest"`
+	Level    int                `json:"level" validate:"oneof=1 2 3"`
+	Tags     []string           `json:"tags" validate:"min=1,dive,max=10"`
+	Home     address            `json:"home"`
+	Work     *address           `json:"work"`
+	Previous []address          `json:"previous"`
+	Meta     map[string]float64 `json:"meta" validate:"max=3"`
+	Avatar   []byte             `json:"avatar"`
+	Extra    interface{}        `json:"extra"`
+	Raw      json.RawMessage    `json:"raw"`
+	Ignored  string             `json:"-"`
+	NoTag    bool
+	private  string
+}
+
+func TestForStruct(t *testing.T) {
+	s := For(user{})
+
+	assert.JSONEq(t, `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"$ref": "#/$defs/user",
+		"$defs": {
+			"user": {
+				"type": "object",
+				"required": ["name", "email"],
+				"properties": {
+					"createdAt": {"type": "string", "format": "date-time"},
+					"deletedAt": {"type": ["string", "null"], "format": "date-time"},
+					"id": {"type": "string"},
+					"name": {"type": "string", "minLength": 2, "maxLength": 50},
+					"email": {"type": "string", "format": "email"},
+					"age": {"type": ["integer", "null"], "format": "int64", "minimum": 18, "exclusiveMaximum": 130},
+					"score": {"type": "number", "format": "double", "exclusiveMinimum": 0, "maximum": 1},
+					"role": {"type": "string", "enum": ["admin", "member", "guest"]},
+					"level": {"type": "integer", "format": "int64", "enum": [1, 2, 3]},
+					"tags": {"type": "array", "minItems": 1, "items": {"type": "string", "maxLength": 10}},
+					"home": {"$ref": "#/$defs/address"},
+					"work": {"anyOf": [{"$ref": "#/$defs/address"}, {"type": "null"}]},
+					"previous": {"type": "array", "items": {"$ref": "#/$defs/address"}},
+					"meta": {"type": "object", "maxProperties": 3, "additionalProperties": {"type": "number", "format": "double"}},
+					"avatar": {"type": "string", "format": "byte"},
+					"extra": {},
+					"raw": {},
+					"NoTag": {"type": "boolean"}
+				}
+			},
+			"address": {
+				"type": "object",
+				"required": ["street", "city"],
+				"properties": {
+					"street": {"type": "string"},
+					"city": {"type": "string", "maxLength": 100}
+				}
+			}
+		}
+	}`, marshal(t, s))
+}
+
+type level int
+
+func (l level) MarshalText() ([]byte, error) { return []byte(strconv.Itoa(int(l))), nil }
+
+type point struct {
+	X, Y int
+}
+
+func (p *point) MarshalJSON() ([]byte, error) { return json.Marshal([]int{p.X, p.Y}) }
+
+func TestForEncodedTypes(t *testing.T) {
+	s := For(struct {
+		Level  level     `json:"level"`
+		Levels []*level  `json:"levels"`
+		Point  point     `json:"point"`
+		Digest [4]byte   `json:"digest"`
+		Data   []byte    `json:"data"`
+		At     time.Time `json:"at"`
+	}{})
+
+	assert.JSONEq(t, `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "object",
+		"properties": {
+			"level": {"type": "string"},
+			"levels": {"type": "array", "items": {"type": ["string", "null"]}},
+			"point": {},
+			"digest": {"type": "array", "items": {"type": "integer", "format": "int32"}},
+			"data": {"type": "string", "format": "byte"},
+			"at": {"type": "string", "format": "date-time"}
+		}
+	}`, marshal(t, s))
+}
+
+type node struct {
+	Value    string           `json:"value"`
+	Children []*node          `json:"children"`
+	Lookup   map[string]*node `json:"lookup"`
+}
+
+func TestForRecursiveType(t *testing.T) {
+	s := For(&node{})
+
+	assert.Equal(t, "#/$defs/node", s.Ref)
+	def := s.Defs["node"]
+	require.NotNil(t, def)
+	assert.Equal(t, "#/$defs/node", def.Properties["children"].Items.AnyOf[0].Ref)
+	assert.Equal(t, "#/$defs/node", def.Properties["lookup"].AdditionalProperties.AnyOf[0].Ref)
+}
+
+func TestForNullablePointers(t *testing.T) {
+	var v struct {
+		Role   *string   `json:"role" validate:"omitempty,oneof=admin member"`
+		Scores []*int    `json:"scores"`
+		Any    *struct{} `json:"any"`
+	}
+	assert.JSONEq(t, `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "object",
+		"properties": {
+			"role": {"type": ["string", "null"], "enum": ["admin", "member", null]},
+			"scores": {"type": "array", "items": {"type": ["integer", "null"], "format": "int64"}},
+			"any": {"type": ["object", "null"]}
+		}
+	}`, marshal(t, For(&v)))
+}
+
+func TestForTagDoesNotMakeParametersNullable(t *testing.T) {
+	var q struct {
+		Page *int `query:"page"`
+	}
+	assert.JSONEq(t, `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "object",
+		"properties": {"page": {"type": "integer", "format": "int64"}}
+	}`, marshal(t, ForTag(q, "query")))
+}
+
+func TestForNonStruct(t *testing.T) {
+	assert.JSONEq(t, `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "array",
+		"items": {"type": "integer", "format": "int32"}
+	}`, marshal(t, For([]int32{})))
+
+	assert.JSONEq(t, `{"$schema": "https://json-schema.org/draft/2020-12/schema"}`, marshal(t, For(nil)))
+}
+
+func TestForTag(t *testing.T) {
+	var query struct {
+		Page    int      `query:"page" validate:"min=1"`
+		PerPage int      `query:"per_page" validate:"max=100"`
+		Sort    []string `query:"sort" validate:"dive,oneof=name date"`
+		Search  string   `query:"q" validate:"required"`
+		Skip    string   `query:"-"`
+	}
+
+	assert.JSONEq(t, `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "object",
+		"required": ["q"],
+		"properties": {
+			"page": {"type": "integer", "format": "int64", "minimum": 1},
+			"per_page": {"type": "integer", "format": "int64", "maximum": 100},
+			"sort": {"type": "array", "items": {"type": "string", "enum": ["name", "date"]}},
+			"q": {"type": "string"}
+		}
+	}`, marshal(t, ForTag(query, "query")))
+}
+
+func TestReflectorSharesDefs(t *testing.T) {
+	type order struct {
+		Shipping address `json:"shipping"`
+		Billing  address `json:"billing"`
+	}
+
+	r := NewReflector("#/components/schemas/")
+	assert.Equal(t, "#/components/schemas/order", r.Reflect(reflect.TypeOf(order{})).Ref)
+	assert.Equal(t, "#/components/schemas/address", r.Reflect(reflect.TypeOf(address{})).Ref)
+
+	defs := r.Defs()
+	assert.Len(t, defs, 2)
+	assert.Equal(t, "#/components/schemas/address", defs["order"].Properties["shipping"].Ref)
+	assert.Equal(t, "#/components/schemas/address", defs["order"].Properties["billing"].Ref)
+}
+
+func TestReflectorUniqueNames(t *testing.T) {
+	type address struct {
+		Line string `json:"line"`
+	}
+
+	r := NewReflector(DefsPrefix)
+	first := r.Reflect(reflect.TypeOf(address{}))
+	second := r.Reflect(reflect.TypeOf(timestampsHolder{}))
+	assert.Equal(t, "#/$defs/address", first.Ref)
+	assert.Equal(t, "#/$defs/timestampsHolder", second.Ref)
+
+	other := r.Reflect(reflect.TypeOf(struct {
+		A address `json:"a"`
+	}{}))
+	assert.Equal(t, "#/$defs/address", other.Properties["a"].Ref, "the same type should reuse its definition")
+
+	pkgAddress := r.Reflect(reflect.TypeOf(user{}.Home))
+	assert.Equal(t, "#/$defs/schema.address", pkgAddress.Ref, "a different type with the same name should be qualified")
+}
+
+type timestampsHolder struct {
+	timestamps `json:"timestamps"`
+}
+
+func TestEmbeddedStructWithTagIsNotPromoted(t *testing.T) {
+	s := For(timestampsHolder{})
+	def := s.Defs["timestampsHolder"]
+	require.NotNil(t, def)
+	assert.Equal(t, "#/$defs/timestamps", def.Properties["timestamps"].Ref)
+}
+
+func TestFields(t *testing.T) {
+	var v struct {
+		timestamps
+		Home     address `json:"home,omitempty"`
+		Dash     string  `json:"-,"`
+		Ignored  string  `json:"-"`
+		Untagged string
+		hidden   string
+	}
+
+	var keys, opts []string
+	for _, f := range Fields(reflect.TypeOf(v), "json") {
+		keys = append(keys, f.Key)
+		opts = append(opts, f.Opts)
+	}
+	assert.Equal(t, []string{"createdAt", "deletedAt", "home", "-", "Untagged"}, keys)
+	assert.Equal(t, []string{"", ",omitempty", ",omitempty", ",", ""}, opts)
+}
+
+func TestFileUploads(t *testing.T) {
+	var form struct {
+		Avatar *multipart.FileHeader   `json:"avatar" validate:"required"`
+		Photos []*multipart.FileHeader `json:"photos" validate:"max=3"`
+	}
+
+	s := For(form)
+	assert.Equal(t, &Schema{Type: "string", Format: "binary"}, s.Properties["avatar"])
+	assert.Equal(t, "binary", s.Properties["photos"].Items.Format)
+	assert.Equal(t, 3, *s.Properties["photos"].MaxItems)
+	assert.Equal(t, []string{"avatar"}, s.Required)
+}
+
+func TestApplyRulesLen(t *testing.T) {
+	var v struct {
+		Code  string `json:"code" validate:"len=6"`
+		Pair  []int  `json:"pair" validate:"len=2"`
+		Exact int    `json:"exact" validate:"len=4"`
+	}
+
+	s := For(v)
+	assert.Equal(t, 6, *s.Properties["code"].MinLength)
+	assert.Equal(t, 6, *s.Properties["code"].MaxLength)
+	assert.Equal(t, 2, *s.Properties["pair"].MinItems)
+	assert.Equal(t, 2, *s.Properties["pair"].MaxItems)
+	assert.Equal(t, int64(4), s.Properties["exact"].Const)
+}
+
+func TestApplyRulesExclusiveLengths(t *testing.T) {
+	var v struct {
+		Name string `json:"name" validate:"gt=2,lt=10"`
+	}
+
+	s := For(v)
+	assert.Equal(t, 3, *s.Properties["name"].MinLength)
+	assert.Equal(t, 9, *s.Properties["name"].MaxLength)
+}
+
+func TestApplyRulesIgnoresUnknownRules(t *testing.T) {
+	var v struct {
+		Value string `json:"value" validate:"required,alphanum|numeric,startswith=a"`
+	}
+
+	s := For(v)
+	assert.Equal(t, &Schema{Type: "string"}, s.Properties["value"])
+}
+
+func TestHasRule(t *testing.T) {
+	assert.True(t, HasRule("omitempty,required", "required"))
+	assert.False(t, HasRule("dive,required", "required"), "rules after dive apply to elements")
+	assert.False(t, HasRule("required_with=Other", "required"))
+	assert.False(t, HasRule("", "required"))
+}
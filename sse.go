@@ -0,0 +1,183 @@
+package boar
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	contentTypeEventStream = "text/event-stream"
+
+	errEventStreamClosed = errors.New("event stream is closed")
+)
+
+// EventStream writes Server-Sent Events to the client. Events are sent as soon as they
+// are written. Once the request context is canceled, because the client disconnected or
+// the server is shutting down, all writes fail with the error of the context
+type EventStream struct {
+	m           sync.Mutex
+	w           ResponseWriter
+	ctx         context.Context
+	lastEventID string
+	closed      chan struct{}
+	closeOnce   sync.Once
+	done        chan struct{}
+}
+
+// newEventStream sends the headers of an event stream and begins streaming the response
+func newEventStream(c Context) (*EventStream, error) {
+	h := c.Response().Header()
+	h.Set("content-type", contentTypeEventStream)
+	h.Set("cache-control", "no-cache")
+	// disable response buffering in nginx
+	h.Set("x-accel-buffering", "no")
+	c.WriteStatus(http.StatusOK)
+	if err := c.Response().Stream(); err != nil {
+		return nil, err
+	}
+	// the headers are sent right away so that clients know the stream is open
+	if err := c.Response().Flush(); err != nil {
+		return nil, err
+	}
+
+	s := &EventStream{
+		w:           c.Response(),
+		ctx:         c.Context(),
+		lastEventID: c.Request().Header.Get("Last-Event-ID"),
+		closed:      make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+	go func() {
+		select {
+		case <-s.ctx.Done():
+		case <-s.closed:
+		}
+		close(s.done)
+	}()
+	return s, nil
+}
+
+// LastEventID returns the value of the Last-Event-ID header which is sent by clients
+// reconnecting to a stream. It is the id of the last event the client received
+func (s *EventStream) LastEventID() string {
+	return s.lastEventID
+}
+
+// Done returns a channel that is closed when the stream can no longer be written to
+func (s *EventStream) Done() <-chan struct{} {
+	return s.done
+}
+
+// Send writes an event to the client. event and id are omitted when they are empty.
+// data is sent as is when it is a string or []byte and is otherwise encoded as JSON.
+// Multiline data is split into multiple data fields which clients join back together
+func (s *EventStream) Send(event, id string, data interface{}) error {
+	if strings.ContainsAny(event, "\r\n") {
+		return fmt.Errorf("invalid event name %q", event)
+	}
+	if strings.ContainsAny(id, "\r\n\x00") {
+		return fmt.Errorf("invalid event id %q", id)
+	}
+
+	var payload []byte
+	switch d := data.(type) {
+	case string:
+		payload = []byte(d)
+	case []byte:
+		payload = d
+	default:
+		var err error
+		if payload, err = json.Marshal(data); err != nil {
+			return fmt.Errorf("could not encode event data: %+v", err)
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	if event != "" {
+		fmt.Fprintf(buf, "event: %s\n", event)
+	}
+	if id != "" {
+		fmt.Fprintf(buf, "id: %s\n", id)
+	}
+	payload = bytes.Replace(payload, []byte("\r\n"), []byte("\n"), -1)
+	for _, line := range bytes.Split(payload, []byte("\n")) {
+		buf.WriteString("data: ")
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	buf.WriteByte('\n')
+	return s.write(buf.Bytes())
+}
+
+// Retry tells the client how long to wait before reconnecting when the connection is lost
+func (s *EventStream) Retry(d time.Duration) error {
+	return s.write([]byte("retry: " + strconv.FormatInt(int64(d/time.Millisecond), 10) + "\n\n"))
+}
+
+// Comment writes a comment to the stream. Comments are ignored by clients but keep
+// idle connections from being closed by proxies
+func (s *EventStream) Comment(text string) error {
+	buf := &bytes.Buffer{}
+	for _, line := range strings.Split(strings.Replace(text, "\r\n", "\n", -1), "\n") {
+		fmt.Fprintf(buf, ": %s\n", line)
+	}
+	buf.WriteByte('\n')
+	return s.write(buf.Bytes())
+}
+
+// Heartbeat writes an empty comment to the stream at every interval until the stream is
+// closed or the request context is canceled
+func (s *EventStream) Heartbeat(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := s.write([]byte(":\n\n")); err != nil {
+					return
+				}
+			case <-s.done:
+				return
+			}
+		}
+	}()
+}
+
+// Close stops the heartbeat and prevents any further events from being written. It is
+// called by the Router once the handler returns
+func (s *EventStream) Close() error {
+	s.closeOnce.Do(func() {
+		s.m.Lock()
+		close(s.closed)
+		s.m.Unlock()
+	})
+	return nil
+}
+
+func (s *EventStream) write(b []byte) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	select {
+	case <-s.closed:
+		return errEventStreamClosed
+	default:
+	}
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+
+	if _, err := s.w.Write(b); err != nil {
+		return err
+	}
+	return s.w.Flush()
+}
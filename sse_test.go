@@ -0,0 +1,180 @@
+package boar
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newEventStreamRequest(ctx context.Context) (*requestContext, *httptest.ResponseRecorder) {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+	return newContext(req, rec, nil), rec
+}
+
+func TestSSESendsHeaders(t *testing.T) {
+	c, rec := newEventStreamRequest(context.Background())
+
+	_, err := c.SSE()
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, contentTypeEventStream, rec.Header().Get("content-type"))
+	assert.Equal(t, "no-cache", rec.Header().Get("cache-control"))
+	assert.True(t, rec.Flushed)
+}
+
+func TestSSEReturnsSameStream(t *testing.T) {
+	c, _ := newEventStreamRequest(context.Background())
+
+	s1, err := c.SSE()
+	require.NoError(t, err)
+	s2, err := c.SSE()
+	require.NoError(t, err)
+	assert.Equal(t, s1, s2)
+}
+
+func TestSSESend(t *testing.T) {
+	c, rec := newEventStreamRequest(context.Background())
+	s, err := c.SSE()
+	require.NoError(t, err)
+
+	require.NoError(t, s.Send("update", "1", "hello"))
+	assert.Equal(t, "event: update\nid: 1\ndata: hello\n\n", rec.Body.String())
+}
+
+func TestSSESendOmitsEmptyFields(t *testing.T) {
+	c, rec := newEventStreamRequest(context.Background())
+	s, err := c.SSE()
+	require.NoError(t, err)
+
+	require.NoError(t, s.Send("", "", []byte("hello")))
+	assert.Equal(t, "data: hello\n\n", rec.Body.String())
+}
+
+func TestSSESendSplitsMultilineData(t *testing.T) {
+	c, rec := newEventStreamRequest(context.Background())
+	s, err := c.SSE()
+	require.NoError(t, err)
+
+	require.NoError(t, s.Send("", "", "hello\r\nworld\n"))
+	assert.Equal(t, "data: hello\ndata: world\ndata: \n\n", rec.Body.String())
+}
+
+func TestSSESendEncodesJSON(t *testing.T) {
+	c, rec := newEventStreamRequest(context.Background())
+	s, err := c.SSE()
+	require.NoError(t, err)
+
+	require.NoError(t, s.Send("", "", map[string]int{"count": 1}))
+	assert.Equal(t, "data: {\"count\":1}\n\n", rec.Body.String())
+}
+
+func TestSSESendRejectsNewlinesInFields(t *testing.T) {
+	c, rec := newEventStreamRequest(context.Background())
+	s, err := c.SSE()
+	require.NoError(t, err)
+
+	assert.Error(t, s.Send("a\nb", "", "hello"))
+	assert.Error(t, s.Send("", "1\n2", "hello"))
+	assert.Empty(t, rec.Body.String())
+}
+
+func TestSSERetry(t *testing.T) {
+	c, rec := newEventStreamRequest(context.Background())
+	s, err := c.SSE()
+	require.NoError(t, err)
+
+	require.NoError(t, s.Retry(3*time.Second))
+	assert.Equal(t, "retry: 3000\n\n", rec.Body.String())
+}
+
+func TestSSEComment(t *testing.T) {
+	c, rec := newEventStreamRequest(context.Background())
+	s, err := c.SSE()
+	require.NoError(t, err)
+
+	require.NoError(t, s.Comment("hello\nworld"))
+	assert.Equal(t, ": hello\n: world\n\n", rec.Body.String())
+}
+
+func TestSSELastEventID(t *testing.T) {
+	c, _ := newEventStreamRequest(context.Background())
+	c.Request().Header.Set("Last-Event-ID", "42")
+
+	s, err := c.SSE()
+	require.NoError(t, err)
+	assert.Equal(t, "42", s.LastEventID())
+}
+
+func TestSSEStopsOnContextCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c, rec := newEventStreamRequest(ctx)
+	s, err := c.SSE()
+	require.NoError(t, err)
+
+	cancel()
+	select {
+	case <-s.Done():
+	case <-time.After(time.Second):
+		t.Fatal("stream was not done after cancel")
+	}
+
+	assert.Equal(t, context.Canceled, s.Send("", "", "hello"))
+	assert.Empty(t, rec.Body.String())
+}
+
+func TestSSEWriteAfterCloseFails(t *testing.T) {
+	c, _ := newEventStreamRequest(context.Background())
+	s, err := c.SSE()
+	require.NoError(t, err)
+
+	require.NoError(t, s.Close())
+	assert.Equal(t, errEventStreamClosed, s.Send("", "", "hello"))
+}
+
+func TestSSEOverHTTP(t *testing.T) {
+	r := NewRouter()
+	r.MethodFunc(http.MethodGet, "/events", func(c Context) error {
+		s, err := c.SSE()
+		if err != nil {
+			return err
+		}
+		s.Heartbeat(10 * time.Millisecond)
+		if err := s.Send("greeting", s.LastEventID()+"1", "hello"); err != nil {
+			return err
+		}
+		<-s.Done()
+		return nil
+	})
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/events", nil)
+	require.NoError(t, err)
+	req.Header.Set("Last-Event-ID", "4")
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, contentTypeEventStream, resp.Header.Get("content-type"))
+
+	// events must arrive while the handler is still running
+	lines := bufio.NewReader(resp.Body)
+	var got []string
+	for len(got) < 6 {
+		line, err := lines.ReadString('\n')
+		require.NoError(t, err)
+		got = append(got, strings.TrimSuffix(line, "\n"))
+	}
+	assert.Equal(t, []string{"event: greeting", "id: 41", "data: hello", "", ":", ""}, got)
+}
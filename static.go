@@ -0,0 +1,235 @@
+package boar
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StaticOptions configure how files are served by Router.Static
+type StaticOptions struct {
+	// Index is the file served for requests of a directory. Defaults to index.html
+	Index string
+
+	// Fallback is the file served, with a 200 status, for requests of paths that do not
+	// exist and have no file extension. It allows single page applications to handle
+	// their own routes with e.g. Fallback: "index.html". Missing files with an extension,
+	// such as /app.js, are still not found. The fallback is disabled when empty
+	Fallback string
+
+	// MaxAge is how long clients may cache files without revalidating them. Files are
+	// always served with an ETag computed from a hash of their content, so when MaxAge is
+	// zero clients are told to revalidate on every request and receive a 304 Not Modified
+	// when the file has not changed
+	MaxAge time.Duration
+}
+
+// WithStaticOptions configures the files served by Router.Static
+func WithStaticOptions(opts StaticOptions) RouteOption {
+	return func(rt *route) {
+		rt.staticOptions = opts
+	}
+}
+
+// Static serves the files of fsys, such as an embed.FS, for GET and HEAD requests of paths
+// beginning with prefix. Requests are handled through the global middlewares and errors
+// through the ErrorHandler like any other route.
+//
+// Paths are cleaned before they are opened so that requests cannot escape fsys. Directory
+// listings are never served. When the client accepts gzip and a file has a precompressed
+// sibling with a .gz extension, the sibling is served with Content-Encoding: gzip instead.
+// Range and conditional requests are supported as they are by Context.ServeContent.
+//
+// A catch-all route at the root would conflict with every other route, so when prefix is /
+// the files are served for GET and HEAD requests which match no route instead. The NotFound
+// handler is executed when there is no file, or Fallback, for the request. The files are
+// then not listed in the route table
+func (rtr *Router) Static(prefix string, fsys fs.FS, opts ...RouteOption) {
+	rt := newRoute(http.MethodGet, prefix, opts)
+	s := &staticFS{
+		fsys:   fsys,
+		opts:   rt.staticOptions,
+		hashes: make(map[string]staticHash),
+	}
+	if s.opts.Index == "" {
+		s.opts.Index = "index.html"
+	}
+
+	if strings.TrimSuffix(prefix, "/") == "" {
+		rtr.rootStatic = s
+		rtr.NotFound(rtr.notFound)
+		return
+	}
+
+	pattern := strings.TrimSuffix(prefix, "/") + "/*filepath"
+	rtr.MethodFunc(http.MethodGet, pattern, s.serve, opts...)
+	rtr.MethodFunc(http.MethodHead, pattern, s.serve, opts...)
+}
+
+type staticFS struct {
+	fsys fs.FS
+	opts StaticOptions
+
+	m      sync.Mutex
+	hashes map[string]staticHash
+}
+
+// staticHash is the cached ETag of a file. The size and modification time are used to
+// detect files that have changed since the hash was computed
+type staticHash struct {
+	size    int64
+	modTime time.Time
+	etag    string
+}
+
+func (s *staticFS) serve(c Context) error {
+	return s.serveFile(c, c.URLParams().ByName("filepath"))
+}
+
+// serveFile serves the file at the path name of fsys. ErrNotFound is returned when there
+// is no such file
+func (s *staticFS) serveFile(c Context, name string) error {
+	// cleaning the rooted path resolves any ../ elements so the path cannot escape fsys
+	name = strings.TrimPrefix(path.Clean("/"+name), "/")
+	if strings.Contains(name, "\\") {
+		return ErrNotFound
+	}
+	if name == "" {
+		name = "."
+	}
+
+	f, info, err := s.open(name)
+	if err == nil && info.IsDir() {
+		f.Close()
+		name = path.Join(name, s.opts.Index)
+		f, info, err = s.open(name)
+	}
+	if err != nil {
+		if s.opts.Fallback == "" || path.Ext(name) != "" {
+			return ErrNotFound
+		}
+		name = s.opts.Fallback
+		if f, info, err = s.open(name); err != nil {
+			return ErrNotFound
+		}
+	}
+	defer f.Close()
+	if info.IsDir() {
+		return ErrNotFound
+	}
+
+	h := c.Response().Header()
+	key := name
+	if gz, gzInfo, err := s.open(name + ".gz"); err == nil {
+		addVary(h, "Accept-Encoding")
+		if !gzInfo.IsDir() && acceptsGzip(c.Request()) {
+			f.Close()
+			f, info, key = gz, gzInfo, name+".gz"
+			h.Set("content-encoding", "gzip")
+		} else {
+			gz.Close()
+		}
+	}
+
+	content, err := readSeeker(f)
+	if err != nil {
+		return err
+	}
+	etag, err := s.etag(key, info, content)
+	if err != nil {
+		return err
+	}
+
+	h.Set("etag", etag)
+	if s.opts.MaxAge > 0 {
+		h.Set("cache-control", "public, max-age="+strconv.FormatInt(int64(s.opts.MaxAge/time.Second), 10))
+	} else {
+		h.Set("cache-control", "no-cache")
+	}
+
+	// the name of the uncompressed file is used so that the content-type is detected
+	// from its extension
+	return c.ServeContent(path.Base(name), info.ModTime(), content)
+}
+
+func (s *staticFS) open(name string) (fs.File, fs.FileInfo, error) {
+	if !fs.ValidPath(name) {
+		return nil, nil, fs.ErrNotExist
+	}
+	f, err := s.fsys.Open(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	return f, info, nil
+}
+
+// etag returns a strong ETag of the content of the file named key, computing it only when
+// the file has not been hashed or has changed since it was
+func (s *staticFS) etag(key string, info fs.FileInfo, content io.ReadSeeker) (string, error) {
+	s.m.Lock()
+	cached, ok := s.hashes[key]
+	s.m.Unlock()
+	if ok && cached.size == info.Size() && cached.modTime.Equal(info.ModTime()) {
+		return cached.etag, nil
+	}
+
+	sum := sha256.New()
+	if _, err := io.Copy(sum, content); err != nil {
+		return "", err
+	}
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	etag := `"` + hex.EncodeToString(sum.Sum(nil)[:16]) + `"`
+
+	s.m.Lock()
+	s.hashes[key] = staticHash{size: info.Size(), modTime: info.ModTime(), etag: etag}
+	s.m.Unlock()
+	return etag, nil
+}
+
+// readSeeker returns f as an io.ReadSeeker, reading it into memory if the fs.FS does not
+// provide seekable files
+func readSeeker(f fs.File) (io.ReadSeeker, error) {
+	if rs, ok := f.(io.ReadSeeker); ok {
+		return rs, nil
+	}
+	b, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(b), nil
+}
+
+// acceptsGzip returns true if gzip is an acceptable content-coding of the request
+func acceptsGzip(r *http.Request) bool {
+	for _, v := range r.Header.Values("Accept-Encoding") {
+		for _, coding := range strings.Split(v, ",") {
+			name, q := strings.TrimSpace(coding), 1.0
+			if i := strings.IndexByte(name, ';'); i >= 0 {
+				param := strings.Replace(name[i+1:], " ", "", -1)
+				name = strings.TrimSpace(name[:i])
+				if strings.HasPrefix(param, "q=") {
+					q, _ = strconv.ParseFloat(param[2:], 64)
+				}
+			}
+			if strings.EqualFold(name, "gzip") || name == "*" {
+				return q > 0
+			}
+		}
+	}
+	return false
+}
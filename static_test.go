@@ -0,0 +1,283 @@
+package boar
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func gzipped(t *testing.T, s string) []byte {
+	buf := &bytes.Buffer{}
+	zw := gzip.NewWriter(buf)
+	_, err := zw.Write([]byte(s))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func newStaticFS(t *testing.T) fstest.MapFS {
+	return fstest.MapFS{
+		"index.html":       {Data: []byte("<h1>home</h1>")},
+		"app.js":           {Data: []byte("console.log('app')")},
+		"app.js.gz":        {Data: gzipped(t, "console.log('app')")},
+		"css/site.css":     {Data: []byte("body{}")},
+		"docs/index.html":  {Data: []byte("<h1>docs</h1>")},
+		"empty/readme.txt": {Data: []byte("readme")},
+	}
+}
+
+func serveStatic(r *Router, method, target string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestStaticServesFiles(t *testing.T) {
+	r := NewRouter()
+	r.Static("/assets", newStaticFS(t))
+
+	rec := serveStatic(r, http.MethodGet, "/assets/css/site.css", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "body{}", rec.Body.String())
+	assert.Equal(t, "text/css; charset=utf-8", rec.Header().Get("content-type"))
+	assert.Equal(t, "no-cache", rec.Header().Get("cache-control"))
+	assert.NotEmpty(t, rec.Header().Get("etag"))
+}
+
+func TestStaticServesDirectoryIndex(t *testing.T) {
+	r := NewRouter()
+	r.Static("/", newStaticFS(t))
+
+	rec := serveStatic(r, http.MethodGet, "/", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "<h1>home</h1>", rec.Body.String())
+
+	rec = serveStatic(r, http.MethodGet, "/docs/", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "<h1>docs</h1>", rec.Body.String())
+
+	rec = serveStatic(r, http.MethodGet, "/empty/", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code, "directories should never be listed")
+}
+
+func TestStaticHead(t *testing.T) {
+	r := NewRouter()
+	r.Static("/assets", newStaticFS(t))
+
+	rec := serveStatic(r, http.MethodHead, "/assets/css/site.css", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Body.String())
+	assert.Equal(t, "6", rec.Header().Get("content-length"))
+}
+
+func TestStaticNotFoundUsesErrorHandler(t *testing.T) {
+	r := NewRouter()
+	r.Static("/assets", newStaticFS(t))
+
+	rec := serveStatic(r, http.MethodGet, "/assets/missing.js", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, contentTypeJSON, rec.Header().Get("content-type"))
+}
+
+// recordingFS records the names that are opened
+type recordingFS struct {
+	fs.FS
+	opened []string
+}
+
+func (r *recordingFS) Open(name string) (fs.File, error) {
+	r.opened = append(r.opened, name)
+	return r.FS.Open(name)
+}
+
+func TestStaticGuardsAgainstTraversal(t *testing.T) {
+	fsys := &recordingFS{FS: newStaticFS(t)}
+	r := NewRouter()
+	r.RealRouter().RedirectFixedPath = false
+	r.Static("/assets", fsys)
+
+	for _, target := range []string{
+		"/assets/../../etc/passwd",
+		"/assets/..%2f..%2fetc%2fpasswd",
+		"/assets/css/..%5c..%5cetc%5cpasswd",
+	} {
+		rec := serveStatic(r, http.MethodGet, target, nil)
+		assert.Equal(t, http.StatusNotFound, rec.Code, target)
+	}
+
+	for _, name := range fsys.opened {
+		assert.True(t, fs.ValidPath(name), name)
+		assert.NotContains(t, name, "..")
+		assert.NotContains(t, name, "\\")
+	}
+}
+
+func TestStaticServesPrecompressedSibling(t *testing.T) {
+	r := NewRouter()
+	r.Static("/assets", newStaticFS(t))
+
+	rec := serveStatic(r, http.MethodGet, "/assets/app.js", http.Header{"Accept-Encoding": {"br, gzip"}})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "gzip", rec.Header().Get("content-encoding"))
+	assert.Equal(t, "text/javascript; charset=utf-8", rec.Header().Get("content-type"))
+	assert.Equal(t, "Accept-Encoding", rec.Header().Get("vary"))
+	assert.Equal(t, gzipped(t, "console.log('app')"), rec.Body.Bytes())
+	gzETag := rec.Header().Get("etag")
+
+	rec = serveStatic(r, http.MethodGet, "/assets/app.js", http.Header{"Accept-Encoding": {"gzip;q=0"}})
+	assert.Empty(t, rec.Header().Get("content-encoding"))
+	assert.Equal(t, "console.log('app')", rec.Body.String())
+	assert.Equal(t, "Accept-Encoding", rec.Header().Get("vary"))
+	assert.NotEqual(t, gzETag, rec.Header().Get("etag"), "each representation needs its own etag")
+}
+
+func TestStaticETagRevalidation(t *testing.T) {
+	r := NewRouter()
+	r.Static("/assets", newStaticFS(t))
+
+	rec := serveStatic(r, http.MethodGet, "/assets/css/site.css", nil)
+	etag := rec.Header().Get("etag")
+	require.NotEmpty(t, etag)
+
+	rec = serveStatic(r, http.MethodGet, "/assets/css/site.css", http.Header{"If-None-Match": {etag}})
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Empty(t, rec.Body.String())
+}
+
+func TestStaticETagChangesWithContent(t *testing.T) {
+	fsys := newStaticFS(t)
+	r := NewRouter()
+	r.Static("/assets", fsys)
+
+	first := serveStatic(r, http.MethodGet, "/assets/css/site.css", nil).Header().Get("etag")
+	fsys["css/site.css"] = &fstest.MapFile{Data: []byte("body{color:red}"), ModTime: time.Now()}
+	second := serveStatic(r, http.MethodGet, "/assets/css/site.css", nil).Header().Get("etag")
+	assert.NotEqual(t, first, second)
+}
+
+func TestStaticMaxAge(t *testing.T) {
+	r := NewRouter()
+	r.Static("/assets", newStaticFS(t), WithStaticOptions(StaticOptions{MaxAge: time.Hour}))
+
+	rec := serveStatic(r, http.MethodGet, "/assets/css/site.css", nil)
+	assert.Equal(t, "public, max-age=3600", rec.Header().Get("cache-control"))
+}
+
+func TestStaticFallback(t *testing.T) {
+	r := NewRouter()
+	r.Static("/app", newStaticFS(t), WithStaticOptions(StaticOptions{Fallback: "index.html"}))
+
+	rec := serveStatic(r, http.MethodGet, "/app/users/42", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "<h1>home</h1>", rec.Body.String())
+	assert.Equal(t, "text/html; charset=utf-8", rec.Header().Get("content-type"))
+
+	rec = serveStatic(r, http.MethodGet, "/app/missing.js", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code, "missing assets should not fall back")
+}
+
+func TestStaticAtRootAlongsideRoutes(t *testing.T) {
+	r := NewRouter()
+	r.MethodFunc(http.MethodGet, "/api/x", func(c Context) error {
+		return c.WriteJSON(http.StatusOK, JSON{"api": true})
+	})
+	r.Static("/", newStaticFS(t), WithStaticOptions(StaticOptions{Fallback: "index.html"}))
+	r.MethodFunc(http.MethodPost, "/api/y", func(Context) error {
+		return nil
+	})
+
+	rec := serveStatic(r, http.MethodGet, "/api/x", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, contentTypeJSON, rec.Header().Get("content-type"))
+
+	rec = serveStatic(r, http.MethodGet, "/css/site.css", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "body{}", rec.Body.String())
+
+	rec = serveStatic(r, http.MethodHead, "/app.js", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Body.String())
+
+	rec = serveStatic(r, http.MethodGet, "/", nil)
+	assert.Equal(t, "<h1>home</h1>", rec.Body.String())
+
+	rec = serveStatic(r, http.MethodGet, "/users/42", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "<h1>home</h1>", rec.Body.String())
+
+	rec = serveStatic(r, http.MethodGet, "/api/y", nil)
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+func TestStaticAtRootFallsBackToNotFound(t *testing.T) {
+	r := NewRouter()
+	r.Static("/", newStaticFS(t))
+	r.NotFound(func(c Context) error {
+		return c.WriteJSON(http.StatusNotFound, JSON{"path": c.Request().URL.Path})
+	})
+
+	rec := serveStatic(r, http.MethodGet, "/index.html", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = serveStatic(r, http.MethodGet, "/missing.js", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.JSONEq(t, `{"path":"/missing.js"}`, rec.Body.String())
+
+	rec = serveStatic(r, http.MethodPost, "/index.html", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code, "only GET and HEAD requests are served files")
+}
+
+func TestStaticRunsMiddleware(t *testing.T) {
+	r := NewRouter()
+	r.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c Context) error {
+			c.Response().Header().Set("x-middleware", "yes")
+			return next(c)
+		}
+	})
+	r.Static("/assets", newStaticFS(t))
+
+	rec := serveStatic(r, http.MethodGet, "/assets/css/site.css", nil)
+	assert.Equal(t, "yes", rec.Header().Get("x-middleware"))
+}
+
+func TestStaticRange(t *testing.T) {
+	r := NewRouter()
+	r.Static("/assets", newStaticFS(t))
+
+	rec := serveStatic(r, http.MethodGet, "/assets/css/site.css", http.Header{"Range": {"bytes=0-3"}})
+	assert.Equal(t, http.StatusPartialContent, rec.Code)
+	assert.Equal(t, "body", rec.Body.String())
+}
+
+func TestAcceptsGzip(t *testing.T) {
+	tests := map[string]bool{
+		"":                 false,
+		"gzip":             true,
+		"GZIP":             true,
+		"br, gzip;q=0.5":   true,
+		"gzip; q=0":        false,
+		"*":                true,
+		"deflate, br":      false,
+		"gzip;q=0.000, br": false,
+	}
+	for accept, expected := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if accept != "" {
+			req.Header.Set("Accept-Encoding", accept)
+		}
+		assert.Equal(t, expected, acceptsGzip(req), accept)
+	}
+}
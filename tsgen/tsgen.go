@@ -0,0 +1,557 @@
+// Package tsgen generates TypeScript interfaces and a fetch based client for boar services
+// from the route table of a Router. The generator is run by a program that builds the Router
+// of the service, usually with go generate:
+//
+//	//go:generate go run ./cmd/gents
+//
+//	func main() {
+//		f, err := os.Create("web/src/api.gen.ts")
+//		if err != nil {
+//			log.Fatal(err)
+//		}
+//		defer f.Close()
+//		if err := tsgen.Generate(f, api.NewRouter().Routes(), tsgen.Options{}); err != nil {
+//			log.Fatal(err)
+//		}
+//	}
+//
+// An interface is generated for the Query, URLParams, Headers and Body of the handler given by
+// boar.Handles for each named route and for its response type given by boar.Returns, along
+// with the struct types they refer to. Body and response fields are named by their json tags
+// and are optional when they are omitempty. The generated Client has a method for each named
+// route which rejects with an HTTPError or a ValidationError when the service responds with
+// an error
+package tsgen
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/blockloop/boar"
+	"github.com/blockloop/boar/internal/naming"
+	"github.com/blockloop/boar/schema"
+)
+
+// Options configure the generated code
+type Options struct {
+	// ClientName is the name of the generated client class. Defaults to Client
+	ClientName string
+}
+
+// Generate writes the TypeScript source of the interfaces and the client of the named routes
+// to w. Routes without a name and handlers served with Mount are skipped
+func Generate(w io.Writer, routes []boar.RouteInfo, opts Options) error {
+	if opts.ClientName == "" {
+		opts.ClientName = "Client"
+	}
+	g := &generator{
+		names: make(map[reflect.Type]string),
+		taken: map[string]bool{
+			opts.ClientName: true, "ClientOptions": true, "HTTPError": true, "ValidationError": true,
+		},
+	}
+
+	methods, err := naming.Methods(routes, lowerFirst)
+	if err != nil {
+		return err
+	}
+	client := &bytes.Buffer{}
+	for i, ri := range routes {
+		if methods[i] == "" {
+			continue
+		}
+		if clientMembers[lowerFirst(methods[i])] {
+			return fmt.Errorf("route name %q conflicts with the %s member of the client", ri.Name, lowerFirst(methods[i]))
+		}
+		g.route(client, methods[i], ri)
+	}
+
+	src := &bytes.Buffer{}
+	fmt.Fprintf(src, "// Code generated by github.com/blockloop/boar/tsgen. DO NOT EDIT.\n")
+	for _, decl := range g.decls {
+		src.WriteString("\n" + decl)
+	}
+	src.WriteString(runtime)
+	fmt.Fprintf(src, "\n/** %s sends requests to the service */\n", opts.ClientName)
+	fmt.Fprintf(src, "export class %s {\n", opts.ClientName)
+	src.WriteString(clientRuntime)
+	src.Write(client.Bytes())
+	src.WriteString("}\n")
+
+	_, err = w.Write(src.Bytes())
+	return err
+}
+
+type generator struct {
+	// names holds the interface names of the named struct types that are declared
+	names map[reflect.Type]string
+	taken map[string]bool
+	decls []string
+}
+
+type field struct {
+	name     string
+	typ      string
+	optional bool
+}
+
+// route declares the interfaces of a route and writes its method
+func (g *generator) route(w io.Writer, method string, ri boar.RouteInfo) {
+	var request []field
+	if ri.Query != nil {
+		fields := g.valueFields(ri.Query, "query")
+		name := g.declare(method+"Query", fields)
+		request = append(request, field{name: "query", typ: name, optional: allOptional(fields)})
+	}
+	var params []field
+	urlParams := make(map[string]reflect.StructField)
+	for _, f := range exportedFields(ri.URLParams) {
+		name, _ := schema.FieldName(f, "url")
+		urlParams[name] = f
+	}
+	for _, p := range ri.Params() {
+		typ := "string"
+		if f, ok := urlParams[p.Name]; ok && !p.CatchAll {
+			typ = g.valueType(f.Type)
+		}
+		params = append(params, field{name: p.Name, typ: typ})
+	}
+	if len(params) > 0 {
+		request = append(request, field{name: "params", typ: g.declare(method+"Params", params)})
+	}
+	if ri.Headers != nil {
+		fields := g.valueFields(ri.Headers, "header")
+		name := g.declare(method+"Headers", fields)
+		request = append(request, field{name: "headers", typ: name, optional: allOptional(fields)})
+	}
+	if ri.Body != nil {
+		request = append(request, field{name: "body", typ: g.rootType(method+"Body", ri.Body)})
+	}
+	response := "unknown"
+	if ri.Response != nil {
+		response = g.rootType(method+"Response", ri.Response)
+	}
+
+	args := ""
+	if len(request) > 0 {
+		args = "req: " + g.declare(method+"Request", request)
+		if allOptional(request) {
+			args += " = {}"
+		}
+	}
+
+	// the path is a template literal with the params of the request in place of its segments
+	var b strings.Builder
+	p := ri.Path
+	for _, param := range ri.Params() {
+		i := strings.Index(p, param.String())
+		b.WriteString(strings.NewReplacer("`", "\\`", "$", "\\$").Replace(p[:i]))
+		if param.CatchAll {
+			fmt.Fprintf(&b, "${catchAll(req.params%s)}", propertyAccess(param.Name))
+		} else {
+			fmt.Fprintf(&b, "${param(%q, req.params%s)}", param.Name, propertyAccess(param.Name))
+		}
+		p = p[i+len(param.String()):]
+	}
+	b.WriteString(strings.NewReplacer("`", "\\`", "$", "\\$").Replace(p))
+
+	parts := []string{strconv.Quote(ri.Method), "`" + b.String() + "`"}
+	for _, part := range []string{"query", "headers", "body"} {
+		arg := "undefined"
+		for _, f := range request {
+			if f.name == part {
+				arg = "req." + part
+			}
+		}
+		parts = append(parts, arg)
+	}
+	for len(parts) > 2 && parts[len(parts)-1] == "undefined" {
+		parts = parts[:len(parts)-1]
+	}
+
+	fmt.Fprintf(w, "\n  /** %s sends %s %s */\n", lowerFirst(method), ri.Method, ri.Path)
+	fmt.Fprintf(w, "  %s(%s): Promise<%s> {\n", lowerFirst(method), args, response)
+	fmt.Fprintf(w, "    return this.request<%s>(%s);\n", response, strings.Join(parts, ", "))
+	fmt.Fprintf(w, "  }\n")
+}
+
+// rootType returns the type of the body or response t of a route. Anonymous structs are
+// declared as interfaces named name
+func (g *generator) rootType(name string, t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Struct && t.Name() == "" {
+		return g.declare(name, g.jsonFields(t))
+	}
+	return g.typeExpr(t)
+}
+
+// declare adds the declaration of an interface named by a unique name based on name, which is
+// returned
+func (g *generator) declare(name string, fields []field) string {
+	name = g.uniqueName(name)
+	g.decls = append(g.decls, interfaceDecl(name, fields))
+	return name
+}
+
+func (g *generator) uniqueName(name string) string {
+	unique := name
+	for i := 2; g.taken[unique]; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+	g.taken[unique] = true
+	return unique
+}
+
+// typeExpr returns the TypeScript type of the JSON encoding of t
+func (g *generator) typeExpr(t reflect.Type) string {
+	if t.Kind() == reflect.Ptr {
+		return g.typeExpr(t.Elem()) + " | null"
+	}
+	// the types which encoding/json does not encode by their kind are described as they
+	// are by the JSON schemas of the service
+	if s, ok := schema.Encoded(t); ok {
+		if s.Type == "" {
+			return "unknown"
+		}
+		return s.Type
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array:
+		return arrayOf(g.typeExpr(t.Elem()))
+	case reflect.Map:
+		return "Record<string, " + g.typeExpr(t.Elem()) + ">"
+	case reflect.Struct:
+		if t.Name() == "" {
+			return objectLiteral(g.jsonFields(t))
+		}
+		name, ok := g.names[t]
+		if !ok {
+			name = g.typeName(t)
+			g.names[t] = name
+			// a placeholder keeps the declaration of t before the declarations of the
+			// types of its fields, which may refer back to t
+			i := len(g.decls)
+			g.decls = append(g.decls, "")
+			g.decls[i] = interfaceDecl(name, g.jsonFields(t))
+		}
+		return name
+	}
+	// the JSON of interfaces and other kinds is not known
+	return "unknown"
+}
+
+// typeName returns a unique interface name for the named type t
+func (g *generator) typeName(t reflect.Type) string {
+	name := identifier(t.Name())
+	if !g.taken[name] {
+		g.taken[name] = true
+		return name
+	}
+	return g.uniqueName(naming.Exported(path.Base(t.PkgPath())) + naming.Exported(name))
+}
+
+// jsonFields describes the fields of the struct type t as they are encoded by encoding/json
+func (g *generator) jsonFields(t reflect.Type) []field {
+	var fields []field
+	for _, f := range schema.Fields(t, "json") {
+		typ := g.typeExpr(f.Type)
+		if strings.Contains(f.Opts+",", ",string,") && isScalar(f.Type) {
+			typ = "string"
+		}
+		fields = append(fields, field{
+			name:     f.Key,
+			typ:      typ,
+			optional: strings.Contains(f.Opts+",", ",omitempty,"),
+		})
+	}
+	return fields
+}
+
+// valueFields describes the fields of the struct type t which are bound from the values of a
+// query string or headers named by the tagKey tag. Fields are optional unless they are
+// required by their validate tags
+func (g *generator) valueFields(t reflect.Type, tagKey string) []field {
+	var fields []field
+	for _, f := range exportedFields(t) {
+		name, _ := schema.FieldName(f, tagKey)
+		if name == "-" {
+			continue
+		}
+		fields = append(fields, field{
+			name:     name,
+			typ:      g.valueType(f.Type),
+			optional: !schema.HasRule(f.Tag.Get("validate"), "required"),
+		})
+	}
+	return fields
+}
+
+// valueType returns the type of a value that is bound from a string, which is formatted with
+// String when the request is sent
+func (g *generator) valueType(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8 {
+		return arrayOf(g.valueType(t.Elem()))
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	}
+	return "string"
+}
+
+func exportedFields(t reflect.Type) []reflect.StructField {
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	var fields []reflect.StructField
+	for i := 0; i < t.NumField(); i++ {
+		if f := t.Field(i); f.PkgPath == "" {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+func interfaceDecl(name string, fields []field) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "export interface %s {\n", name)
+	for _, f := range fields {
+		fmt.Fprintf(&b, "  %s;\n", f)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func objectLiteral(fields []field) string {
+	if len(fields) == 0 {
+		return "{}"
+	}
+	parts := make([]string, len(fields))
+	for i, f := range fields {
+		parts[i] = f.String()
+	}
+	return "{ " + strings.Join(parts, "; ") + " }"
+}
+
+func (f field) String() string {
+	name := f.name
+	if !isIdentifier(name) {
+		name = strconv.Quote(name)
+	}
+	if f.optional {
+		name += "?"
+	}
+	return name + ": " + f.typ
+}
+
+func allOptional(fields []field) bool {
+	for _, f := range fields {
+		if !f.optional {
+			return false
+		}
+	}
+	return true
+}
+
+func arrayOf(elem string) string {
+	if strings.Contains(elem, " | ") {
+		return "(" + elem + ")[]"
+	}
+	return elem + "[]"
+}
+
+// isScalar reports whether the ,string option of encoding/json applies to t
+func isScalar(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func propertyAccess(key string) string {
+	if isIdentifier(key) {
+		return "." + key
+	}
+	return "[" + strconv.Quote(key) + "]"
+}
+
+func isIdentifier(s string) bool {
+	for i, r := range s {
+		if r > unicode.MaxASCII || !(r == '_' || r == '$' || unicode.IsLetter(r) || (i > 0 && unicode.IsDigit(r))) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// identifier replaces the characters of a Go type name which are not valid in TypeScript
+// identifiers, such as the brackets of generic types
+func identifier(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || r == '$' || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// lowerFirst converts an exported name into the name of a method, such as UserOrders into
+// userOrders and APIUsers into apiUsers
+func lowerFirst(name string) string {
+	runes := []rune(name)
+	i := 0
+	for i < len(runes) && unicode.IsUpper(runes[i]) {
+		i++
+	}
+	if i > 1 && i < len(runes) {
+		// the last upper case letter of an initialism begins the next word
+		i--
+	}
+	return strings.ToLower(string(runes[:i])) + string(runes[i:])
+}
+
+// clientMembers are the members of the generated client that methods cannot be named
+var clientMembers = map[string]bool{"baseURL": true, "options": true, "request": true, "constructor": true}
+
+// runtime declares the errors that the client rejects with and the helpers of the client
+const runtime = `
+/** HTTPError is thrown for responses with an error status */
+export class HTTPError extends Error {
+  status: number;
+
+  constructor(status: number, message: string) {
+    super(message);
+    this.name = "HTTPError";
+    this.status = status;
+  }
+}
+
+/** ValidationError is thrown when the request failed validation */
+export class ValidationError extends Error {
+  status: number;
+  /** errors are keyed by the part of the request that was invalid, such as body or query */
+  errors: Record<string, string[]>;
+
+  constructor(status: number, errors: Record<string, string[]>) {
+    super(
+      Object.keys(errors)
+        .sort()
+        .reduce<string[]>((all, key) => all.concat(errors[key]), [])
+        .join("; "),
+    );
+    this.name = "ValidationError";
+    this.status = status;
+    this.errors = errors;
+  }
+}
+
+export interface ClientOptions {
+  /** headers are added to every request, such as an Authorization header */
+  headers?: Record<string, string>;
+  /** fetch sends requests. The global fetch is used when it is not set */
+  fetch?: typeof fetch;
+}
+
+function param(name: string, value: unknown): string {
+  const s = String(value);
+  if (s.includes("/")) {
+    throw new Error(` + "`param \"${name}\" cannot contain a slash`" + `);
+  }
+  return encodeURIComponent(s);
+}
+
+function catchAll(value: unknown): string {
+  return String(value).replace(/^\//, "").split("/").map(encodeURIComponent).join("/");
+}
+
+function append(add: (key: string, value: string) => void, values?: object): void {
+  for (const [key, value] of Object.entries(values ?? {})) {
+    for (const v of Array.isArray(value) ? value : [value]) {
+      if (v !== undefined && v !== null) {
+        add(key, String(v));
+      }
+    }
+  }
+}
+
+async function decodeError(res: Response): Promise<Error> {
+  const text = await res.text();
+  try {
+    const body = JSON.parse(text);
+    if (body && typeof body === "object" && body.errors) {
+      return new ValidationError(res.status, body.errors);
+    }
+    if (body && typeof body === "object" && typeof body.error === "string") {
+      return new HTTPError(res.status, body.error);
+    }
+  } catch {
+    // the body is not the JSON of a boar error
+  }
+  return new HTTPError(res.status, text.trim() || res.statusText);
+}
+`
+
+// clientRuntime is the constructor and the request method of the client
+const clientRuntime = `  readonly baseURL: string;
+  readonly options: ClientOptions;
+
+  constructor(baseURL: string, options: ClientOptions = {}) {
+    this.baseURL = baseURL.replace(/\/$/, "");
+    this.options = options;
+  }
+
+  private async request<T>(method: string, path: string, query?: object, headers?: object, body?: unknown): Promise<T> {
+    const search = new URLSearchParams();
+    append((k, v) => search.append(k, v), query);
+    const h = new Headers(this.options.headers);
+    append((k, v) => h.append(k, v), headers);
+    h.set("Accept", "application/json");
+    if (body !== undefined) {
+      h.set("Content-Type", "application/json");
+    }
+
+    const qs = search.toString();
+    const send = this.options.fetch ?? fetch;
+    const res = await send(this.baseURL + path + (qs ? "?" + qs : ""), {
+      method,
+      headers: h,
+      body: body === undefined ? undefined : JSON.stringify(body),
+    });
+    if (res.status >= 400) {
+      throw await decodeError(res);
+    }
+    const text = await res.text();
+    return (text ? JSON.parse(text) : undefined) as T;
+  }
+`
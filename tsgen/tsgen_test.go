@@ -0,0 +1,274 @@
+package tsgen
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/blockloop/boar"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type address struct {
+	Street string `json:"street"`
+}
+
+type audit struct {
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type user struct {
+	audit
+	ID      int64                  `json:"id,string"`
+	Name    string                 `json:"name"`
+	Email   string                 `json:"email,omitempty"`
+	Home    *address               `json:"home"`
+	Friends []*user                `json:"friends"`
+	Avatar  []byte                 `json:"avatar"`
+	Meta    map[string]interface{} `json:"meta"`
+	Ignored string                 `json:"-"`
+	NoTag   bool
+	secret  string
+}
+
+type createUser struct {
+	Query struct {
+		DryRun bool     `query:"dry_run"`
+		Fields []string `query:"fields" validate:"required"`
+	}
+	URLParams struct {
+		Org int `url:"org"`
+	}
+	Headers struct {
+		RequestID string `header:"X-Request-ID"`
+	}
+	Body user
+}
+
+func (h *createUser) Handle(boar.Context) error { return nil }
+
+type search struct {
+	Query struct {
+		Term string `query:"q"`
+	}
+	Body struct {
+		Filters []address `json:"filters"`
+	}
+}
+
+func (h *search) Handle(boar.Context) error { return nil }
+
+func generate(t *testing.T, r *boar.Router) string {
+	buf := &bytes.Buffer{}
+	require.NoError(t, Generate(buf, r.Routes(), Options{}))
+	return buf.String()
+}
+
+func TestGenerateInterfaces(t *testing.T) {
+	r := boar.NewRouter()
+	r.Post("/orgs/:org/users", func(boar.Context) (boar.Handler, error) {
+		return &createUser{}, nil
+	}, boar.Handles(&createUser{}), boar.Name("users.create"), boar.Returns(user{}))
+
+	src := generate(t, r)
+	assert.Contains(t, src, "// Code generated by github.com/blockloop/boar/tsgen. DO NOT EDIT.\n")
+	assert.Contains(t, src, `export interface user {
+  createdAt: string;
+  id: string;
+  name: string;
+  email?: string;
+  home: address | null;
+  friends: (user | null)[];
+  avatar: string;
+  meta: Record<string, unknown>;
+  NoTag: boolean;
+}
+`)
+	assert.Contains(t, src, `export interface address {
+  street: string;
+}
+`)
+	assert.Contains(t, src, `export interface UsersCreateQuery {
+  dry_run?: boolean;
+  fields: string[];
+}
+`)
+	assert.Contains(t, src, `export interface UsersCreateParams {
+  org: number;
+}
+`)
+	assert.Contains(t, src, `export interface UsersCreateHeaders {
+  "X-Request-ID"?: string;
+}
+`)
+	assert.Contains(t, src, `export interface UsersCreateRequest {
+  query: UsersCreateQuery;
+  params: UsersCreateParams;
+  headers?: UsersCreateHeaders;
+  body: user;
+}
+`)
+	assert.Equal(t, 1, bytes.Count([]byte(src), []byte("export interface user ")), "types should be declared once")
+}
+
+type level int
+
+func (l level) MarshalText() ([]byte, error) { return []byte(strconv.Itoa(int(l))), nil }
+
+type point struct {
+	X, Y int
+}
+
+func (p *point) MarshalJSON() ([]byte, error) { return json.Marshal([]int{p.X, p.Y}) }
+
+type encoded struct {
+	Level  level     `json:"level"`
+	Point  point     `json:"point"`
+	Digest [4]byte   `json:"digest"`
+	Data   []byte    `json:"data"`
+	At     time.Time `json:"at"`
+}
+
+func TestGenerateEncodedTypes(t *testing.T) {
+	r := boar.NewRouter()
+	r.MethodFunc(http.MethodGet, "/encoded", func(boar.Context) error { return nil },
+		boar.Name("encoded"), boar.Returns(encoded{}))
+
+	assert.Contains(t, generate(t, r), `export interface encoded {
+  level: string;
+  point: unknown;
+  digest: number[];
+  data: string;
+  at: string;
+}
+`)
+}
+
+func TestGenerateMethods(t *testing.T) {
+	r := boar.NewRouter()
+	r.Post("/orgs/:org/users", func(boar.Context) (boar.Handler, error) {
+		return &createUser{}, nil
+	}, boar.Handles(&createUser{}), boar.Name("users.create"), boar.Returns(user{}))
+	r.Post("/search", func(boar.Context) (boar.Handler, error) {
+		return &search{}, nil
+	}, boar.Handles(&search{}), boar.Name("search"), boar.Returns([]user{}))
+	r.MethodFunc(http.MethodGet, "/files/*filepath", func(boar.Context) error { return nil }, boar.Name("file"))
+	r.MethodFunc(http.MethodDelete, "/files/*filepath", func(boar.Context) error { return nil }, boar.Name("file"))
+	r.MethodFunc(http.MethodGet, "/health", func(boar.Context) error { return nil }, boar.Name("health"),
+		boar.Returns(struct {
+			OK bool `json:"ok"`
+		}{}))
+	r.MethodFunc(http.MethodGet, "/unnamed", func(boar.Context) error { return nil })
+
+	src := generate(t, r)
+	assert.Contains(t, src, `
+  /** usersCreate sends POST /orgs/:org/users */
+  usersCreate(req: UsersCreateRequest): Promise<user> {
+    return this.request<user>("POST", `+"`/orgs/${param(\"org\", req.params.org)}/users`"+`, req.query, req.headers, req.body);
+  }
+`)
+	assert.Contains(t, src, `
+  /** search sends POST /search */
+  search(req: SearchRequest): Promise<user[]> {
+    return this.request<user[]>("POST", `+"`/search`"+`, req.query, undefined, req.body);
+  }
+`)
+	assert.Contains(t, src, `export interface SearchBody {
+  filters: address[];
+}
+`)
+	assert.Contains(t, src, `export interface SearchQuery {
+  q?: string;
+}
+`)
+	assert.Contains(t, src, `  getFile(req: GetFileRequest): Promise<unknown> {
+    return this.request<unknown>("GET", `+"`/files/${catchAll(req.params.filepath)}`"+`);
+  }
+`)
+	assert.Contains(t, src, "  deleteFile(req: DeleteFileRequest): Promise<unknown> {\n")
+	assert.Contains(t, src, `export interface HealthResponse {
+  ok: boolean;
+}
+`)
+	assert.Contains(t, src, "  health(): Promise<HealthResponse> {\n    return this.request<HealthResponse>(\"GET\", `/health`);\n")
+	assert.NotContains(t, src, "unnamed")
+}
+
+type listUsers struct {
+	Query struct {
+		Page int `query:"page"`
+	}
+}
+
+func (h *listUsers) Handle(boar.Context) error { return nil }
+
+func TestGenerateOptionalRequest(t *testing.T) {
+	r := boar.NewRouter()
+	r.Get("/users", func(boar.Context) (boar.Handler, error) {
+		return &listUsers{}, nil
+	}, boar.Handles(&listUsers{}), boar.Name("users"))
+	r.Post("/search", func(boar.Context) (boar.Handler, error) {
+		return &search{}, nil
+	}, boar.Handles(&search{}), boar.Name("search"))
+
+	src := generate(t, r)
+	assert.Contains(t, src, "export interface UsersRequest {\n  query?: UsersQuery;\n}\n")
+	assert.Contains(t, src, "  users(req: UsersRequest = {}): Promise<unknown> {\n")
+	assert.Contains(t, src, "export interface SearchRequest {\n  query?: SearchQuery;\n  body: SearchBody;\n}\n")
+	assert.Contains(t, src, "  search(req: SearchRequest): Promise<unknown> {\n")
+}
+
+func TestGenerateUniqueNames(t *testing.T) {
+	type address struct {
+		Line string `json:"line"`
+	}
+	var body struct {
+		Home  address   `json:"home"`
+		Work  *user     `json:"work"`
+		Other []address `json:"other"`
+	}
+
+	r := boar.NewRouter()
+	r.MethodFunc(http.MethodGet, "/a", func(boar.Context) error { return nil }, boar.Name("a"), boar.Returns(body))
+
+	src := generate(t, r)
+	assert.Contains(t, src, "export interface address {\n  line: string;\n}\n")
+	assert.Contains(t, src, "export interface TsgenAddress {\n  street: string;\n}\n", "a different type with the same name should be qualified")
+	assert.Contains(t, src, "  home: TsgenAddress | null;\n")
+	assert.Contains(t, src, "export interface AResponse {\n  home: address;\n  work: user | null;\n  other: address[];\n}\n")
+}
+
+func TestGenerateRejectsConflictingNames(t *testing.T) {
+	routes := []boar.RouteInfo{
+		{Method: http.MethodGet, Path: "/a", Name: "user.list"},
+		{Method: http.MethodGet, Path: "/b", Name: "user-list"},
+	}
+	err := Generate(&bytes.Buffer{}, routes, Options{})
+	assert.EqualError(t, err, `routes "user.list" and "user-list" both generate the method userList`)
+
+	routes = []boar.RouteInfo{{Method: http.MethodGet, Path: "/a", Name: "request"}}
+	err = Generate(&bytes.Buffer{}, routes, Options{})
+	assert.EqualError(t, err, `route name "request" conflicts with the request member of the client`)
+}
+
+func TestGenerateClientName(t *testing.T) {
+	buf := &bytes.Buffer{}
+	require.NoError(t, Generate(buf, nil, Options{ClientName: "UsersAPI"}))
+	assert.Contains(t, buf.String(), "export class UsersAPI {\n")
+}
+
+func TestLowerFirst(t *testing.T) {
+	tests := map[string]string{
+		"UserOrders": "userOrders",
+		"APIUsers":   "apiUsers",
+		"ID":         "id",
+		"GetUserID":  "getUserID",
+	}
+	for in, expected := range tests {
+		assert.Equal(t, expected, lowerFirst(in), in)
+	}
+}
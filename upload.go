@@ -0,0 +1,137 @@
+package boar
+
+import (
+	"io"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/go-playground/validator.v9"
+)
+
+// sniffLen is the number of bytes http.DetectContentType considers
+const sniffLen = 512
+
+// newValidator creates the validator used for Query, URLParams and Body. In addition to
+// the validator defaults it understands uploaded files with the following tags:
+//
+//	filesize=2MB                   every file is at most 2MB (B, KB, MB and GB are understood)
+//	mimetype=image/png image/*     the sniffed content type of every file is one of the types
+//
+// *multipart.FileHeader fields are validated as if they were a slice of one file
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterCustomTypeFunc(func(field reflect.Value) interface{} {
+		if field.CanAddr() {
+			return []*multipart.FileHeader{field.Addr().Interface().(*multipart.FileHeader)}
+		}
+		fh := field.Interface().(multipart.FileHeader)
+		return []*multipart.FileHeader{&fh}
+	}, multipart.FileHeader{})
+
+	mustRegister(v, "filesize", validateFileSize)
+	mustRegister(v, "mimetype", validateMIMEType)
+	return v
+}
+
+func mustRegister(v *validator.Validate, tag string, fn validator.Func) {
+	if err := v.RegisterValidation(tag, fn); err != nil {
+		panic(err)
+	}
+}
+
+func validateFileSize(fl validator.FieldLevel) bool {
+	files, ok := fl.Field().Interface().([]*multipart.FileHeader)
+	if !ok {
+		return false
+	}
+	max, err := parseByteSize(fl.Param())
+	if err != nil {
+		panic(err)
+	}
+	for _, fh := range files {
+		if fh.Size > max {
+			return false
+		}
+	}
+	return true
+}
+
+func validateMIMEType(fl validator.FieldLevel) bool {
+	files, ok := fl.Field().Interface().([]*multipart.FileHeader)
+	if !ok {
+		return false
+	}
+	allowed := strings.Fields(fl.Param())
+	for _, fh := range files {
+		typ, err := sniffContentType(fh)
+		if err != nil || !matchMediaType(typ, allowed) {
+			return false
+		}
+	}
+	return true
+}
+
+// sniffContentType detects the content type of the uploaded file from its contents.
+// The content-type sent by the client is never trusted
+func sniffContentType(fh *multipart.FileHeader) (string, error) {
+	f, err := fh.Open()
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	buf := make([]byte, sniffLen)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+	typ := http.DetectContentType(buf[:n])
+	if i := strings.IndexByte(typ, ';'); i >= 0 {
+		typ = typ[:i]
+	}
+	return typ, nil
+}
+
+// matchMediaType reports whether typ is one of allowed. Allowed types may use a
+// wildcard subtype such as image/*
+func matchMediaType(typ string, allowed []string) bool {
+	for _, a := range allowed {
+		a = strings.ToLower(a)
+		if a == typ || a == "*/*" {
+			return true
+		}
+		if strings.HasSuffix(a, "/*") && strings.HasPrefix(typ, a[:len(a)-1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseByteSize parses sizes such as 512, 512B, 10KB, 2MB or 1GB
+func parseByteSize(s string) (int64, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	units := []struct {
+		suffix string
+		size   int64
+	}{
+		{"GB", 1 << 30},
+		{"MB", 1 << 20},
+		{"KB", 1 << 10},
+		{"B", 1},
+	}
+	mult := int64(1)
+	for _, u := range units {
+		if strings.HasSuffix(s, u.suffix) {
+			s, mult = strings.TrimSpace(strings.TrimSuffix(s, u.suffix)), u.size
+			break
+		}
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return n * mult, nil
+}
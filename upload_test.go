@@ -0,0 +1,181 @@
+package boar
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var pngHeader = []byte("\x89PNG\x0D\x0A\x1A\x0A")
+
+type uploadHandler struct {
+	handle HandlerFunc
+	Body   struct {
+		Name   string
+		Avatar *multipart.FileHeader   `form:"avatar" validate:"required,filesize=1KB,mimetype=image/*"`
+		Docs   []*multipart.FileHeader `form:"docs" validate:"omitempty,mimetype=text/plain"`
+	}
+}
+
+func (h *uploadHandler) Handle(c Context) error { return h.handle(c) }
+
+func newUploadRequest(t *testing.T, fields map[string]string, files map[string][][]byte) *http.Request {
+	buf := &bytes.Buffer{}
+	mw := multipart.NewWriter(buf)
+	for k, v := range fields {
+		require.NoError(t, mw.WriteField(k, v))
+	}
+	for k, contents := range files {
+		for _, content := range contents {
+			// the client content-type is always application/octet-stream to ensure it is
+			// not trusted
+			fw, err := mw.CreateFormFile(k, k+".bin")
+			require.NoError(t, err)
+			_, err = fw.Write(content)
+			require.NoError(t, err)
+		}
+	}
+	require.NoError(t, mw.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/", buf)
+	req.Header.Set("content-type", mw.FormDataContentType())
+	return req
+}
+
+func serveUpload(t *testing.T, req *http.Request) (*uploadHandler, *httptest.ResponseRecorder) {
+	r := NewRouter()
+	h := &uploadHandler{}
+	h.handle = func(c Context) error {
+		return c.WriteStatus(http.StatusNoContent)
+	}
+	r.Post("/", func(Context) (Handler, error) {
+		return h, nil
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return h, rec
+}
+
+func TestUploadBindsFilesIntoBody(t *testing.T) {
+	req := newUploadRequest(t, map[string]string{"Name": "brett"}, map[string][][]byte{
+		"avatar": {append(pngHeader, 1, 2, 3)},
+		"docs":   {[]byte("hello"), []byte("world")},
+	})
+
+	h, rec := serveUpload(t, req)
+	require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+
+	assert.Equal(t, "brett", h.Body.Name)
+	require.NotNil(t, h.Body.Avatar)
+	assert.EqualValues(t, len(pngHeader)+3, h.Body.Avatar.Size)
+	assert.Len(t, h.Body.Docs, 2)
+}
+
+func TestUploadValidatesRequiredFile(t *testing.T) {
+	req := newUploadRequest(t, map[string]string{"Name": "brett"}, nil)
+
+	_, rec := serveUpload(t, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestUploadValidatesFileSize(t *testing.T) {
+	req := newUploadRequest(t, nil, map[string][][]byte{
+		"avatar": {append(pngHeader, make([]byte, 2048)...)},
+	})
+
+	_, rec := serveUpload(t, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "filesize")
+}
+
+func TestUploadValidatesSniffedMIMEType(t *testing.T) {
+	req := newUploadRequest(t, nil, map[string][][]byte{
+		"avatar": {[]byte("definitely not an image")},
+	})
+
+	_, rec := serveUpload(t, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "mimetype")
+}
+
+func TestUploadValidatesMIMETypeOfEveryFile(t *testing.T) {
+	req := newUploadRequest(t, nil, map[string][][]byte{
+		"avatar": {pngHeader},
+		"docs":   {[]byte("hello"), pngHeader},
+	})
+
+	_, rec := serveUpload(t, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestUploadRemovesTempFilesAfterRequest(t *testing.T) {
+	max := MultiPartFormMaxMemory
+	MultiPartFormMaxMemory = 0
+	defer func() { MultiPartFormMaxMemory = max }()
+
+	req := newUploadRequest(t, nil, map[string][][]byte{
+		"avatar": {pngHeader},
+	})
+
+	var tmpfile string
+	r := NewRouter()
+	r.MethodFunc(http.MethodPost, "/", func(c Context) error {
+		var body struct {
+			Avatar *multipart.FileHeader `form:"avatar"`
+		}
+		if err := c.Bind(&body); err != nil {
+			return err
+		}
+		f, err := body.Avatar.Open()
+		require.NoError(t, err)
+		defer f.Close()
+		osf, ok := f.(*os.File)
+		require.True(t, ok, "expected upload to be spooled to disk")
+		tmpfile = osf.Name()
+		return nil
+	})
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	require.NotEmpty(t, tmpfile)
+	_, err := os.Stat(tmpfile)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestParseByteSize(t *testing.T) {
+	for in, exp := range map[string]int64{
+		"512":   512,
+		"512B":  512,
+		"10kb":  10 << 10,
+		"2MB":   2 << 20,
+		"1 GB":  1 << 30,
+		"100mb": 100 << 20,
+	} {
+		n, err := parseByteSize(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, exp, n, in)
+	}
+
+	_, err := parseByteSize("MB")
+	assert.Error(t, err)
+}
+
+func TestSniffContentTypeIgnoresClientContentType(t *testing.T) {
+	req := newUploadRequest(t, nil, map[string][][]byte{
+		"avatar": {pngHeader},
+	})
+	require.NoError(t, req.ParseMultipartForm(MultiPartFormMaxMemory))
+
+	fh := req.MultipartForm.File["avatar"][0]
+	assert.Equal(t, "application/octet-stream", fh.Header.Get("content-type"))
+
+	typ, err := sniffContentType(fh)
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", typ)
+}
@@ -0,0 +1,48 @@
+package boar
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/blockloop/boar/internal/pattern"
+)
+
+var errNoRouter = errors.New("context is not served by a Router")
+
+// addName registers the name of rt so that its URL can be built. It panics if the name is
+// already used by a route with a different path
+func (rtr *Router) addName(rt *route) {
+	if rtr.names == nil {
+		rtr.names = make(map[string]*route)
+	}
+	if existing, ok := rtr.names[rt.name]; ok && existing.path != rt.path {
+		log.Panicf("route name %q is already used by %q", rt.name, existing.path)
+	}
+	rtr.names[rt.name] = rt
+}
+
+// URL builds the URL of the route registered with the given Name. params are key/value
+// pairs which fill the :param and *catchall segments of the route path. Values are escaped,
+// apart from the slashes of *catchall values which separate segments. Routes are matched
+// against the unescaped path, so a :param value cannot contain a slash. Pairs with keys that
+// are not parameters of the route are added to the query string. An error is returned when
+// there is no route with the name, when params has an odd length or when a parameter of the
+// route is missing or invalid
+//
+//	rtr.Get("/users/:id/orders", listOrders, boar.Name("user.orders"))
+//	rtr.URL("user.orders", "id", "42", "page", "2") // /users/42/orders?page=2
+func (rtr *Router) URL(name string, params ...string) (string, error) {
+	rt, ok := rtr.names[name]
+	if !ok {
+		return "", fmt.Errorf("no route named %q", name)
+	}
+	return pattern.Build(rt.path, fmt.Sprintf("route %q", name), params)
+}
+
+func (r *requestContext) URLFor(name string, params ...string) (string, error) {
+	if r.router == nil {
+		return "", errNoRouter
+	}
+	return r.router.URL(name, params...)
+}
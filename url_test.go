@@ -0,0 +1,126 @@
+package boar
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func noopHandler(Context) error { return nil }
+
+func TestURLFillsParams(t *testing.T) {
+	r := NewRouter()
+	r.MethodFunc(http.MethodGet, "/users/:id/orders/:order", noopHandler, Name("user.order"))
+
+	u, err := r.URL("user.order", "id", "42", "order", "7")
+	require.NoError(t, err)
+	assert.Equal(t, "/users/42/orders/7", u)
+}
+
+func TestURLEscapesParams(t *testing.T) {
+	r := NewRouter()
+	r.MethodFunc(http.MethodGet, "/users/:name", noopHandler, Name("user"))
+
+	u, err := r.URL("user", "name", "a b?c#d%")
+	require.NoError(t, err)
+	assert.Equal(t, "/users/a%20b%3Fc%23d%25", u)
+}
+
+func TestURLFillsCatchAll(t *testing.T) {
+	r := NewRouter()
+	r.MethodFunc(http.MethodGet, "/files/*filepath", noopHandler, Name("file"))
+
+	u, err := r.URL("file", "filepath", "docs/my file.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "/files/docs/my%20file.txt", u)
+
+	u, err = r.URL("file", "filepath", "/docs/a.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "/files/docs/a.txt", u, "the leading slash of a catch-all value should not be repeated")
+}
+
+func TestURLAddsQueryValues(t *testing.T) {
+	r := NewRouter()
+	r.MethodFunc(http.MethodGet, "/users/:id/orders", noopHandler, Name("user.orders"))
+
+	u, err := r.URL("user.orders", "id", "42", "status", "open", "page", "2", "status", "paid")
+	require.NoError(t, err)
+	assert.Equal(t, "/users/42/orders?page=2&status=open&status=paid", u)
+}
+
+func TestURLErrors(t *testing.T) {
+	r := NewRouter()
+	r.MethodFunc(http.MethodGet, "/users/:id", noopHandler, Name("user"))
+
+	_, err := r.URL("missing")
+	assert.EqualError(t, err, `no route named "missing"`)
+
+	_, err = r.URL("user")
+	assert.EqualError(t, err, `missing param "id" for route "user"`)
+
+	_, err = r.URL("user", "id")
+	assert.EqualError(t, err, `odd number of params for route "user"`)
+
+	_, err = r.URL("user", "id", "a/b")
+	assert.EqualError(t, err, `param "id" for route "user" cannot contain a slash`)
+}
+
+func TestURLMatchesRoute(t *testing.T) {
+	r := NewRouter()
+	var got string
+	r.MethodFunc(http.MethodGet, "/users/:name/*rest", func(c Context) error {
+		got = c.URLParams().ByName("name") + " " + c.URLParams().ByName("rest")
+		return nil
+	}, Name("user"))
+
+	u, err := r.URL("user", "name", "a b?%", "rest", "x/y z")
+	require.NoError(t, err)
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, u, nil))
+	assert.Equal(t, "a b?% /x/y z", got)
+}
+
+func TestNameSharedAcrossMethods(t *testing.T) {
+	r := NewRouter()
+	r.MethodFunc(http.MethodGet, "/users/:id", noopHandler, Name("user"))
+	r.MethodFunc(http.MethodDelete, "/users/:id", noopHandler, Name("user"))
+
+	assert.Panics(t, func() {
+		r.MethodFunc(http.MethodGet, "/accounts/:id", noopHandler, Name("user"))
+	})
+}
+
+func TestContextURLFor(t *testing.T) {
+	r := NewRouter()
+	r.MethodFunc(http.MethodGet, "/users/:id", noopHandler, Name("user"))
+	r.MethodFunc(http.MethodPost, "/users", func(c Context) error {
+		u, err := c.URLFor("user", "id", "42")
+		if err != nil {
+			return err
+		}
+		c.Response().Header().Set("location", u)
+		return c.WriteStatus(http.StatusCreated)
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/users", nil))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "/users/42", rec.Header().Get("location"))
+}
+
+func TestContextURLForWithoutRouter(t *testing.T) {
+	c := newContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder(), nil)
+	_, err := c.URLFor("user")
+	assert.Equal(t, errNoRouter, err)
+}
+
+func TestURLForMountedHandler(t *testing.T) {
+	r := NewRouter()
+	r.Mount("/legacy", http.NotFoundHandler(), Name("legacy"))
+
+	u, err := r.URL("legacy", mountParam, "a/b")
+	require.NoError(t, err)
+	assert.Equal(t, "/legacy/a/b", u)
+}
@@ -0,0 +1,700 @@
+package boar
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+)
+
+// websocketGUID is appended to the key of a handshake to compute the accept header (RFC 6455 1.3)
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// DefaultWebSocketReadLimit is the maximum size of a message read from a websocket when
+// WebSocketOptions.ReadLimit is not set
+const DefaultWebSocketReadLimit = 1 << 20 // 1MB
+
+// DefaultWebSocketCloseTimeout is how long Close waits for the close frame of the client when
+// WebSocketOptions.CloseTimeout is not set
+const DefaultWebSocketCloseTimeout = 2 * time.Second
+
+// MessageType is the type of a websocket data message
+type MessageType int
+
+const (
+	// TextMessage is a message of UTF-8 encoded text
+	TextMessage MessageType = 1
+	// BinaryMessage is a message of binary data
+	BinaryMessage MessageType = 2
+)
+
+// websocket frame opcodes (RFC 6455 5.2)
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xA
+)
+
+// Close codes sent in websocket close frames (RFC 6455 7.4.1)
+const (
+	CloseNormalClosure           = 1000
+	CloseGoingAway               = 1001
+	CloseProtocolError           = 1002
+	CloseUnsupportedData         = 1003
+	CloseNoStatusReceived        = 1005
+	CloseAbnormalClosure         = 1006
+	CloseInvalidFramePayloadData = 1007
+	ClosePolicyViolation         = 1008
+	CloseMessageTooBig           = 1009
+	CloseInternalServerErr       = 1011
+)
+
+// maxControlPayload is the maximum payload of ping, pong and close frames
+const maxControlPayload = 125
+
+// ErrWebSocketClosed is returned when writing to a websocket after a close frame was sent
+var ErrWebSocketClosed = errors.New("websocket connection is closed")
+
+// CloseError is returned when reading from a websocket that has been closed, either by a
+// close frame from the client or because the client broke the protocol
+type CloseError struct {
+	// Code is the close code of the connection
+	Code int
+	// Text is the reason the connection was closed
+	Text string
+}
+
+func (e *CloseError) Error() string {
+	if e.Text == "" {
+		return fmt.Sprintf("websocket closed with code %d", e.Code)
+	}
+	return fmt.Sprintf("websocket closed with code %d: %s", e.Code, e.Text)
+}
+
+// WebSocketHandler handles a websocket connection after the request has been upgraded
+type WebSocketHandler interface {
+	HandleWebSocket(Context, *WebSocketConn) error
+}
+
+// WebSocketProviderFunc creates the handler for a websocket request. The Query, URLParams
+// and Headers fields of the handler are populated before the request is upgraded
+type WebSocketProviderFunc func(Context) (WebSocketHandler, error)
+
+// WebSocketOptions configure the websocket connections of a route
+type WebSocketOptions struct {
+	// Subprotocols are the subprotocols supported by the server in order of preference.
+	// The first one also requested by the client is selected
+	Subprotocols []string
+
+	// CheckOrigin returns true if the Origin of the request is allowed to connect. The
+	// default allows requests without an Origin header and requests whose Origin matches
+	// the Host header
+	CheckOrigin func(r *http.Request) bool
+
+	// ReadLimit is the maximum size of a message read from the client. Larger messages
+	// close the connection with CloseMessageTooBig. DefaultWebSocketReadLimit is used
+	// when it is zero
+	ReadLimit int64
+
+	// PingInterval sends a ping to the client at every interval. Reading from a connection
+	// fails when nothing, including a pong, has been received from the client for twice the
+	// interval. Zero disables pings
+	PingInterval time.Duration
+
+	// WriteTimeout is the maximum duration of a single write. Zero means there is no timeout
+	WriteTimeout time.Duration
+
+	// CloseTimeout is how long Close waits for the client to answer the close frame of the
+	// server before the connection is closed. DefaultWebSocketCloseTimeout is used when it
+	// is zero
+	CloseTimeout time.Duration
+}
+
+// WebSocketConn is a message oriented websocket connection. Pings from the client are
+// answered automatically while reading. Messages must be read by a single goroutine, but
+// writes are safe to make concurrently with reads and other writes
+type WebSocketConn struct {
+	// readLimit is accessed atomically, so it is the first field to be 64-bit aligned
+	readLimit int64
+
+	conn        net.Conn
+	br          *bufio.Reader
+	subprotocol string
+	readErr     error
+
+	pingInterval time.Duration
+	writeTimeout time.Duration
+	closeTimeout time.Duration
+
+	// reading is set while a goroutine reads from the connection
+	rm      sync.Mutex
+	reading bool
+	// closeReceived is closed once the close frame of the client has been read
+	closeReceived     chan struct{}
+	closeReceivedOnce sync.Once
+
+	wm        sync.Mutex
+	bw        *bufio.Writer
+	closeSent bool
+
+	pm          sync.Mutex
+	pongHandler func(data []byte)
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+// WebSocket registers a handler for websocket connections at path. The request is handled
+// by the global middlewares like any other request, so authentication and other middlewares
+// are able to reject the request before it is upgraded. The Query, URLParams and Headers of
+// the handler are bound before the handshake, which is performed according to RFC 6455. The
+// connection is closed once HandleWebSocket returns, with CloseInternalServerErr when it
+// returns an error
+func (rtr *Router) WebSocket(path string, provider WebSocketProviderFunc, opts ...RouteOption) {
+	rt := newRoute(http.MethodGet, path, append([]RouteOption{StreamBody()}, opts...))
+	rtr.handle(rt, webSocketMiddleware(rt, provider))
+}
+
+// WithWebSocketOptions configures the connections of a route registered with Router.WebSocket
+func WithWebSocketOptions(opts WebSocketOptions) RouteOption {
+	return func(rt *route) {
+		rt.webSocketOptions = opts
+	}
+}
+
+// webSocketMiddleware binds the websocket handler, upgrades the request and then hands the
+// connection to the handler
+func webSocketMiddleware(rt *route, provider WebSocketProviderFunc) HandlerFunc {
+	return func(c Context) error {
+		handler, err := provider(c)
+		if err != nil {
+			return err
+		}
+		if handler == nil {
+			log.Panicf("nil websocket handler provided for %q", c.Request().URL.Path)
+		}
+
+		if err := bindRequest(rt, c, handler); err != nil {
+			return err
+		}
+
+		ws, err := upgradeWebSocket(c, rt.webSocketOptions)
+		if err != nil {
+			return err
+		}
+
+		err = handler.HandleWebSocket(c, ws)
+		if cerr, ok := err.(*CloseError); ok {
+			switch cerr.Code {
+			case CloseNormalClosure, CloseGoingAway, CloseNoStatusReceived:
+				// the client closed the connection
+				err = nil
+			}
+		}
+		if err != nil {
+			ws.Close(CloseInternalServerErr, "")
+			return err
+		}
+		ws.Close(CloseNormalClosure, "")
+		return nil
+	}
+}
+
+// upgradeWebSocket validates the websocket handshake of the request and takes over the
+// connection. An HTTPError is returned, and nothing is written, when the handshake is invalid
+func upgradeWebSocket(c Context, opts WebSocketOptions) (*WebSocketConn, error) {
+	r := c.Request()
+	if !headerContainsToken(r.Header, "Connection", "upgrade") || !headerContainsToken(r.Header, "Upgrade", "websocket") {
+		return nil, NewHTTPError(http.StatusBadRequest, errors.New("not a websocket handshake"))
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		c.Response().Header().Set("Sec-WebSocket-Version", "13")
+		return nil, NewHTTPError(http.StatusUpgradeRequired, errors.New("unsupported websocket version"))
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if k, err := base64.StdEncoding.DecodeString(key); err != nil || len(k) != 16 {
+		return nil, NewHTTPError(http.StatusBadRequest, errors.New("invalid Sec-WebSocket-Key"))
+	}
+
+	checkOrigin := opts.CheckOrigin
+	if checkOrigin == nil {
+		checkOrigin = sameOrigin
+	}
+	if !checkOrigin(r) {
+		return nil, ErrForbidden
+	}
+
+	subprotocol := selectSubprotocol(r.Header, opts.Subprotocols)
+
+	hj, ok := c.Response().(http.Hijacker)
+	if !ok {
+		return nil, http.ErrNotSupported
+	}
+	conn, brw, err := hj.Hijack()
+	if err != nil {
+		return nil, err
+	}
+
+	h := c.Response().Header().Clone()
+	h.Del("content-type")
+	h.Del("content-length")
+	h.Set("Upgrade", "websocket")
+	h.Set("Connection", "Upgrade")
+	h.Set("Sec-WebSocket-Accept", webSocketAccept(key))
+	if subprotocol != "" {
+		h.Set("Sec-WebSocket-Protocol", subprotocol)
+	}
+
+	// the server may have set deadlines for reading the request
+	conn.SetDeadline(time.Time{})
+	brw.WriteString("HTTP/1.1 101 Switching Protocols\r\n")
+	h.Write(brw)
+	brw.WriteString("\r\n")
+	if err := brw.Flush(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	readLimit := opts.ReadLimit
+	if readLimit <= 0 {
+		readLimit = DefaultWebSocketReadLimit
+	}
+	closeTimeout := opts.CloseTimeout
+	if closeTimeout <= 0 {
+		closeTimeout = DefaultWebSocketCloseTimeout
+	}
+	ws := &WebSocketConn{
+		conn:         conn,
+		br:           brw.Reader,
+		bw:           brw.Writer,
+		subprotocol:  subprotocol,
+		readLimit:    readLimit,
+		pingInterval: opts.PingInterval,
+		writeTimeout: opts.WriteTimeout,
+		closeTimeout: closeTimeout,
+		closed:       make(chan struct{}),
+
+		closeReceived: make(chan struct{}),
+	}
+	if ws.pingInterval > 0 {
+		conn.SetReadDeadline(time.Now().Add(2 * ws.pingInterval))
+		go ws.keepAlive()
+	}
+	return ws, nil
+}
+
+// webSocketAccept computes the Sec-WebSocket-Accept header for the key of a handshake
+func webSocketAccept(key string) string {
+	sum := sha1.Sum([]byte(key + websocketGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// headerContainsToken returns true if the comma separated values of header name contain token
+func headerContainsToken(h http.Header, name, token string) bool {
+	for _, v := range h.Values(name) {
+		for _, t := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// selectSubprotocol returns the first of supported that was requested by the client
+func selectSubprotocol(h http.Header, supported []string) string {
+	for _, s := range supported {
+		if headerContainsToken(h, "Sec-WebSocket-Protocol", s) {
+			return s
+		}
+	}
+	return ""
+}
+
+// sameOrigin allows requests without an Origin header and requests whose Origin host
+// matches the Host header
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, r.Host)
+}
+
+// Subprotocol returns the subprotocol selected during the handshake
+func (ws *WebSocketConn) Subprotocol() string {
+	return ws.subprotocol
+}
+
+// RemoteAddr returns the network address of the client
+func (ws *WebSocketConn) RemoteAddr() net.Addr {
+	return ws.conn.RemoteAddr()
+}
+
+// SetReadLimit sets the maximum size of a message read from the client. It may be called
+// while another goroutine is reading, and applies from the next frame read
+func (ws *WebSocketConn) SetReadLimit(n int64) {
+	atomic.StoreInt64(&ws.readLimit, n)
+}
+
+// SetReadDeadline sets the deadline for reading from the connection. A zero value means
+// reads will not time out. When PingInterval is set the deadline is extended each time
+// anything is received from the client
+func (ws *WebSocketConn) SetReadDeadline(t time.Time) error {
+	return ws.conn.SetReadDeadline(t)
+}
+
+// SetPongHandler sets a func that is called with the payload of each pong received
+// while reading messages
+func (ws *WebSocketConn) SetPongHandler(h func(data []byte)) {
+	ws.pm.Lock()
+	ws.pongHandler = h
+	ws.pm.Unlock()
+}
+
+// ReadMessage reads the next data message from the client. Fragmented messages are
+// reassembled and control frames received in between are handled. A *CloseError is
+// returned once the client closes the connection or breaks the protocol, after which
+// every call returns the same error
+func (ws *WebSocketConn) ReadMessage() (MessageType, []byte, error) {
+	if ws.readErr != nil {
+		return 0, nil, ws.readErr
+	}
+	ws.setReading(true)
+	defer ws.setReading(false)
+
+	typ, msg, err := ws.readMessage()
+	if err != nil {
+		ws.readErr = err
+	}
+	return typ, msg, err
+}
+
+func (ws *WebSocketConn) readMessage() (MessageType, []byte, error) {
+	var (
+		typ MessageType
+		msg []byte
+	)
+	for {
+		fin, opcode, payload, err := ws.readFrame(int64(len(msg)))
+		if err != nil {
+			return 0, nil, err
+		}
+
+		switch opcode {
+		case opPing:
+			if err := ws.writeFrame(opPong, payload); err != nil && err != ErrWebSocketClosed {
+				return 0, nil, err
+			}
+			continue
+		case opPong:
+			ws.pm.Lock()
+			h := ws.pongHandler
+			ws.pm.Unlock()
+			if h != nil {
+				h(payload)
+			}
+			continue
+		case opClose:
+			return 0, nil, ws.handleClose(payload)
+		case opText, opBinary:
+			if typ != 0 {
+				return 0, nil, ws.fail(CloseProtocolError, "expected continuation frame")
+			}
+			typ = MessageType(opcode)
+		case opContinuation:
+			if typ == 0 {
+				return 0, nil, ws.fail(CloseProtocolError, "unexpected continuation frame")
+			}
+		default:
+			return 0, nil, ws.fail(CloseProtocolError, fmt.Sprintf("unknown opcode %d", opcode))
+		}
+
+		msg = append(msg, payload...)
+		if !fin {
+			continue
+		}
+		if typ == TextMessage && !utf8.Valid(msg) {
+			return 0, nil, ws.fail(CloseInvalidFramePayloadData, "invalid UTF-8 in text message")
+		}
+		return typ, msg, nil
+	}
+}
+
+// readFrame reads a single frame from the client and unmasks its payload. read is the
+// size of the message read so far, which is used to enforce the read limit
+func (ws *WebSocketConn) readFrame(read int64) (fin bool, opcode byte, payload []byte, err error) {
+	var header [2]byte
+	if _, err := io.ReadFull(ws.br, header[:]); err != nil {
+		return false, 0, nil, ws.readFailed(err)
+	}
+	// the deadline of Close is not extended
+	if ws.pingInterval > 0 && !ws.isCloseSent() {
+		ws.conn.SetReadDeadline(time.Now().Add(2 * ws.pingInterval))
+	}
+
+	fin = header[0]&0x80 != 0
+	opcode = header[0] & 0x0F
+	if header[0]&0x70 != 0 {
+		return false, 0, nil, ws.fail(CloseProtocolError, "reserved bits are set")
+	}
+	if header[1]&0x80 == 0 {
+		return false, 0, nil, ws.fail(CloseProtocolError, "client frames must be masked")
+	}
+
+	length := uint64(header[1] & 0x7F)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(ws.br, ext[:]); err != nil {
+			return false, 0, nil, ws.readFailed(err)
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(ws.br, ext[:]); err != nil {
+			return false, 0, nil, ws.readFailed(err)
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+
+	control := opcode&0x8 != 0
+	if control && (!fin || length > maxControlPayload) {
+		return false, 0, nil, ws.fail(CloseProtocolError, "invalid control frame")
+	}
+	limit := atomic.LoadInt64(&ws.readLimit)
+	if !control && (length > uint64(limit) || read+int64(length) > limit) {
+		return false, 0, nil, ws.fail(CloseMessageTooBig, "message exceeds the read limit")
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(ws.br, mask[:]); err != nil {
+		return false, 0, nil, ws.readFailed(err)
+	}
+	payload = make([]byte, length)
+	if _, err := io.ReadFull(ws.br, payload); err != nil {
+		return false, 0, nil, ws.readFailed(err)
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return fin, opcode, payload, nil
+}
+
+// readFailed closes the connection after it could not be read from
+func (ws *WebSocketConn) readFailed(err error) error {
+	ws.closeConn()
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return &CloseError{Code: CloseAbnormalClosure, Text: "unexpected EOF"}
+	}
+	return err
+}
+
+// handleClose answers a close frame from the client and closes the connection
+func (ws *WebSocketConn) handleClose(payload []byte) error {
+	ws.closeReceivedOnce.Do(func() { close(ws.closeReceived) })
+	cerr := &CloseError{Code: CloseNoStatusReceived}
+	switch {
+	case len(payload) == 1:
+		return ws.fail(CloseProtocolError, "invalid close frame")
+	case len(payload) >= 2:
+		cerr.Code = int(binary.BigEndian.Uint16(payload))
+		cerr.Text = string(payload[2:])
+		if !validCloseCode(cerr.Code) {
+			return ws.fail(CloseProtocolError, fmt.Sprintf("invalid close code %d", cerr.Code))
+		}
+		if !utf8.Valid(payload[2:]) {
+			return ws.fail(CloseInvalidFramePayloadData, "invalid UTF-8 in close reason")
+		}
+	}
+
+	ws.Close(cerr.Code, "")
+	return cerr
+}
+
+// validCloseCode reports whether code may be sent in a close frame. RFC 6455 7.4 reserves
+// 1004 to 1006 and 1015 for other uses, codes below 3000 which are not registered with IANA
+// are invalid and 3000 to 4999 are left to libraries and applications
+func validCloseCode(code int) bool {
+	switch {
+	case code >= 1000 && code <= 1003, code >= 1007 && code <= 1014:
+		return true
+	}
+	return code >= 3000 && code <= 4999
+}
+
+// fail closes the connection with code after the client broke the protocol
+func (ws *WebSocketConn) fail(code int, reason string) error {
+	ws.Close(code, reason)
+	return &CloseError{Code: code, Text: reason}
+}
+
+// WriteMessage sends a single message to the client
+func (ws *WebSocketConn) WriteMessage(typ MessageType, data []byte) error {
+	if typ != TextMessage && typ != BinaryMessage {
+		return fmt.Errorf("invalid message type %d", typ)
+	}
+	return ws.writeFrame(byte(typ), data)
+}
+
+// Ping sends a ping to the client. data must be no larger than 125 bytes
+func (ws *WebSocketConn) Ping(data []byte) error {
+	if len(data) > maxControlPayload {
+		return errors.New("ping payload must be no larger than 125 bytes")
+	}
+	return ws.writeFrame(opPing, data)
+}
+
+// Close sends a close frame with code and reason to the client and closes the connection.
+// When the client has not closed the connection first, Close waits up to CloseTimeout for
+// the client to answer with its own close frame, as RFC 6455 7.1.1 asks, discarding any
+// messages received in the meantime. Closing a connection that is already closed does
+// nothing. CloseNoStatusReceived sends a close frame without a code
+func (ws *WebSocketConn) Close(code int, reason string) error {
+	var payload []byte
+	if code != CloseNoStatusReceived {
+		if len(reason) > maxControlPayload-2 {
+			reason = reason[:maxControlPayload-2]
+		}
+		payload = make([]byte, 2, 2+len(reason))
+		binary.BigEndian.PutUint16(payload, uint16(code))
+		payload = append(payload, reason...)
+	}
+
+	err := ws.writeFrame(opClose, payload)
+	if err == nil {
+		ws.awaitClose()
+	}
+	ws.closeConn()
+	if err == ErrWebSocketClosed {
+		return nil
+	}
+	return err
+}
+
+// awaitClose waits for the close frame of the client after the server sent its own. The
+// frame is read here unless another goroutine is reading, which receives it instead
+func (ws *WebSocketConn) awaitClose() {
+	select {
+	case <-ws.closeReceived:
+		return
+	case <-ws.closed:
+		return
+	default:
+	}
+
+	deadline := time.Now().Add(ws.closeTimeout)
+	ws.conn.SetReadDeadline(deadline)
+
+	ws.rm.Lock()
+	reading := ws.reading
+	ws.reading = true
+	ws.rm.Unlock()
+	if !reading {
+		defer ws.setReading(false)
+		for ws.readErr == nil {
+			_, opcode, _, err := ws.readFrame(0)
+			if err != nil || opcode == opClose {
+				return
+			}
+		}
+		return
+	}
+
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+	select {
+	case <-ws.closeReceived:
+	case <-ws.closed:
+	case <-timer.C:
+	}
+}
+
+func (ws *WebSocketConn) setReading(reading bool) {
+	ws.rm.Lock()
+	ws.reading = reading
+	ws.rm.Unlock()
+}
+
+func (ws *WebSocketConn) isCloseSent() bool {
+	ws.wm.Lock()
+	defer ws.wm.Unlock()
+	return ws.closeSent
+}
+
+func (ws *WebSocketConn) closeConn() {
+	ws.closeOnce.Do(func() {
+		close(ws.closed)
+		ws.conn.Close()
+	})
+}
+
+// writeFrame writes a single unfragmented frame. Server frames are not masked
+func (ws *WebSocketConn) writeFrame(opcode byte, payload []byte) error {
+	ws.wm.Lock()
+	defer ws.wm.Unlock()
+	if ws.closeSent {
+		return ErrWebSocketClosed
+	}
+	if opcode == opClose {
+		ws.closeSent = true
+	}
+
+	if ws.writeTimeout > 0 {
+		ws.conn.SetWriteDeadline(time.Now().Add(ws.writeTimeout))
+	}
+
+	header := make([]byte, 2, 10)
+	header[0] = 0x80 | opcode
+	switch n := len(payload); {
+	case n < 126:
+		header[1] = byte(n)
+	case n <= 0xFFFF:
+		header[1] = 126
+		header = header[:4]
+		binary.BigEndian.PutUint16(header[2:], uint16(n))
+	default:
+		header[1] = 127
+		header = header[:10]
+		binary.BigEndian.PutUint64(header[2:], uint64(n))
+	}
+
+	ws.bw.Write(header)
+	ws.bw.Write(payload)
+	return ws.bw.Flush()
+}
+
+// keepAlive pings the client at every PingInterval until the connection is closed
+func (ws *WebSocketConn) keepAlive() {
+	ticker := time.NewTicker(ws.pingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := ws.Ping(nil); err != nil {
+				return
+			}
+		case <-ws.closed:
+			return
+		}
+	}
+}
@@ -0,0 +1,487 @@
+package boar
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testWebSocketKey = "dGhlIHNhbXBsZSBub25jZQ=="
+
+// wsClient is a minimal websocket client for testing the server implementation
+type wsClient struct {
+	conn net.Conn
+	br   *bufio.Reader
+	resp *http.Response
+}
+
+func dialWebSocket(t *testing.T, srv *httptest.Server, path string, header http.Header) *wsClient {
+	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL+path, nil)
+	require.NoError(t, err)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", testWebSocketKey)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	require.NoError(t, req.Write(conn))
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	require.NoError(t, err)
+	return &wsClient{conn: conn, br: br, resp: resp}
+}
+
+func (c *wsClient) writeFrame(t *testing.T, fin bool, opcode byte, payload []byte) {
+	b0 := opcode
+	if fin {
+		b0 |= 0x80
+	}
+	frame := []byte{b0}
+	switch n := len(payload); {
+	case n < 126:
+		frame = append(frame, 0x80|byte(n))
+	default:
+		frame = append(frame, 0x80|126, byte(n>>8), byte(n))
+	}
+	mask := []byte{1, 2, 3, 4}
+	frame = append(frame, mask...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	_, err := c.conn.Write(frame)
+	require.NoError(t, err)
+}
+
+func (c *wsClient) readFrame(t *testing.T) (byte, []byte) {
+	var header [2]byte
+	_, err := io.ReadFull(c.br, header[:])
+	require.NoError(t, err)
+	require.Zero(t, header[1]&0x80, "server frames must not be masked")
+
+	n := int(header[1] & 0x7F)
+	if n == 126 {
+		var ext [2]byte
+		_, err := io.ReadFull(c.br, ext[:])
+		require.NoError(t, err)
+		n = int(binary.BigEndian.Uint16(ext[:]))
+	}
+	payload := make([]byte, n)
+	_, err = io.ReadFull(c.br, payload)
+	require.NoError(t, err)
+	return header[0] & 0x0F, payload
+}
+
+// readClose reads a close frame and answers it as a client should. The server may already
+// have closed the connection, so the answer is allowed to fail
+func (c *wsClient) readClose(t *testing.T) int {
+	op, payload := c.readFrame(t)
+	require.Equal(t, byte(opClose), op)
+	require.True(t, len(payload) >= 2)
+	c.conn.Write([]byte{0x80 | opClose, 0x80, 0, 0, 0, 0})
+	return int(binary.BigEndian.Uint16(payload))
+}
+
+func closePayload(code int, reason string) []byte {
+	b := make([]byte, 2)
+	binary.BigEndian.PutUint16(b, uint16(code))
+	return append(b, reason...)
+}
+
+type echoWebSocket struct {
+	Query struct {
+		Prefix string `query:"prefix"`
+	}
+	URLParams struct {
+		Room string `url:"room"`
+	}
+	Headers struct {
+		Token string `header:"x-token"`
+	}
+}
+
+func (h *echoWebSocket) HandleWebSocket(c Context, ws *WebSocketConn) error {
+	for {
+		typ, msg, err := ws.ReadMessage()
+		if err != nil {
+			return err
+		}
+		reply := h.URLParams.Room + ":" + h.Headers.Token + ":" + h.Query.Prefix + string(msg)
+		if err := ws.WriteMessage(typ, []byte(reply)); err != nil {
+			return err
+		}
+	}
+}
+
+func newWebSocketServer(t *testing.T, provider WebSocketProviderFunc, opts ...RouteOption) *httptest.Server {
+	r := NewRouter()
+	r.WebSocket("/ws/:room", provider, opts...)
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func echoProvider(Context) (WebSocketHandler, error) {
+	return &echoWebSocket{}, nil
+}
+
+func TestWebSocketAccept(t *testing.T) {
+	// example from RFC 6455 section 1.3
+	assert.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", webSocketAccept(testWebSocketKey))
+}
+
+func TestWebSocketHandshakeAndEcho(t *testing.T) {
+	srv := newWebSocketServer(t, echoProvider)
+	c := dialWebSocket(t, srv, "/ws/lobby?prefix=>", http.Header{"X-Token": {"abc"}})
+
+	require.Equal(t, http.StatusSwitchingProtocols, c.resp.StatusCode)
+	assert.Equal(t, "websocket", c.resp.Header.Get("Upgrade"))
+	assert.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", c.resp.Header.Get("Sec-WebSocket-Accept"))
+
+	c.writeFrame(t, true, opText, []byte("hello"))
+	op, payload := c.readFrame(t)
+	assert.Equal(t, byte(opText), op)
+	assert.Equal(t, "lobby:abc:>hello", string(payload))
+
+	c.writeFrame(t, true, opBinary, []byte{1, 2})
+	op, payload = c.readFrame(t)
+	assert.Equal(t, byte(opBinary), op)
+	assert.Equal(t, append([]byte("lobby:abc:>"), 1, 2), payload)
+
+	c.writeFrame(t, true, opClose, closePayload(CloseNormalClosure, "bye"))
+	assert.Equal(t, CloseNormalClosure, c.readClose(t))
+}
+
+func TestWebSocketReassemblesFragments(t *testing.T) {
+	srv := newWebSocketServer(t, echoProvider)
+	c := dialWebSocket(t, srv, "/ws/a", nil)
+
+	c.writeFrame(t, false, opText, []byte("hel"))
+	c.writeFrame(t, true, opPing, []byte("ping"))
+	c.writeFrame(t, true, opContinuation, []byte("lo"))
+
+	op, payload := c.readFrame(t)
+	assert.Equal(t, byte(opPong), op, "pings should be answered between fragments")
+	assert.Equal(t, "ping", string(payload))
+
+	op, payload = c.readFrame(t)
+	assert.Equal(t, byte(opText), op)
+	assert.Equal(t, "a::hello", string(payload))
+}
+
+func TestWebSocketRunsMiddlewareBeforeUpgrade(t *testing.T) {
+	r := NewRouter()
+	r.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c Context) error {
+			if c.Request().Header.Get("X-Token") != "secret" {
+				return ErrUnauthorized
+			}
+			return next(c)
+		}
+	})
+	r.WebSocket("/ws/:room", echoProvider)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	c := dialWebSocket(t, srv, "/ws/a", nil)
+	assert.Equal(t, http.StatusUnauthorized, c.resp.StatusCode)
+
+	c = dialWebSocket(t, srv, "/ws/a", http.Header{"X-Token": {"secret"}})
+	assert.Equal(t, http.StatusSwitchingProtocols, c.resp.StatusCode)
+}
+
+func TestWebSocketRejectsInvalidHandshakes(t *testing.T) {
+	srv := newWebSocketServer(t, echoProvider)
+
+	resp, err := http.Get(srv.URL + "/ws/a")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	c := dialWebSocket(t, srv, "/ws/a", http.Header{"Sec-Websocket-Version": {"8"}})
+	assert.Equal(t, http.StatusUpgradeRequired, c.resp.StatusCode)
+	assert.Equal(t, "13", c.resp.Header.Get("Sec-WebSocket-Version"))
+
+	c = dialWebSocket(t, srv, "/ws/a", http.Header{"Sec-Websocket-Key": {"short"}})
+	assert.Equal(t, http.StatusBadRequest, c.resp.StatusCode)
+
+	c = dialWebSocket(t, srv, "/ws/a", http.Header{"Origin": {"http://evil.example.com"}})
+	assert.Equal(t, http.StatusForbidden, c.resp.StatusCode)
+}
+
+func TestWebSocketSelectsSubprotocol(t *testing.T) {
+	srv := newWebSocketServer(t, echoProvider, WithWebSocketOptions(WebSocketOptions{
+		Subprotocols: []string{"v2", "v1"},
+	}))
+
+	c := dialWebSocket(t, srv, "/ws/a", http.Header{"Sec-Websocket-Protocol": {"v1, v2"}})
+	require.Equal(t, http.StatusSwitchingProtocols, c.resp.StatusCode)
+	assert.Equal(t, "v2", c.resp.Header.Get("Sec-WebSocket-Protocol"))
+}
+
+func TestWebSocketReadLimit(t *testing.T) {
+	srv := newWebSocketServer(t, echoProvider, WithWebSocketOptions(WebSocketOptions{
+		ReadLimit: 4,
+	}))
+	c := dialWebSocket(t, srv, "/ws/a", nil)
+
+	c.writeFrame(t, false, opText, []byte("abc"))
+	c.writeFrame(t, true, opContinuation, []byte("de"))
+	assert.Equal(t, CloseMessageTooBig, c.readClose(t))
+}
+
+func TestWebSocketClosesUnmaskedFrames(t *testing.T) {
+	srv := newWebSocketServer(t, echoProvider)
+	c := dialWebSocket(t, srv, "/ws/a", nil)
+
+	_, err := c.conn.Write([]byte{0x81, 0x01, 'a'})
+	require.NoError(t, err)
+	assert.Equal(t, CloseProtocolError, c.readClose(t))
+}
+
+func TestWebSocketClosesInvalidUTF8(t *testing.T) {
+	srv := newWebSocketServer(t, echoProvider)
+	c := dialWebSocket(t, srv, "/ws/a", nil)
+
+	c.writeFrame(t, true, opText, []byte{0xff, 0xfe})
+	assert.Equal(t, CloseInvalidFramePayloadData, c.readClose(t))
+}
+
+func TestWebSocketClosesInvalidCloseCodes(t *testing.T) {
+	srv := newWebSocketServer(t, echoProvider)
+	for _, code := range []int{999, 1004, CloseNoStatusReceived, CloseAbnormalClosure, 1015, 2999, 5000} {
+		c := dialWebSocket(t, srv, "/ws/a", nil)
+		c.writeFrame(t, true, opClose, closePayload(code, ""))
+		assert.Equal(t, CloseProtocolError, c.readClose(t), code)
+	}
+}
+
+func TestWebSocketEchoesValidCloseCodes(t *testing.T) {
+	srv := newWebSocketServer(t, echoProvider)
+	for _, code := range []int{CloseGoingAway, 1014, 3000, 4999} {
+		c := dialWebSocket(t, srv, "/ws/a", nil)
+		c.writeFrame(t, true, opClose, closePayload(code, ""))
+		assert.Equal(t, code, c.readClose(t), code)
+	}
+}
+
+func TestWebSocketSetReadLimitWhileReading(t *testing.T) {
+	limited := make(chan struct{})
+	srv := newWebSocketServer(t, func(Context) (WebSocketHandler, error) {
+		return webSocketHandlerFunc(func(c Context, ws *WebSocketConn) error {
+			read := make(chan error, 1)
+			go func() {
+				_, _, err := ws.ReadMessage()
+				read <- err
+			}()
+			ws.SetReadLimit(2)
+			close(limited)
+			return <-read
+		}), nil
+	})
+	c := dialWebSocket(t, srv, "/ws/a", nil)
+
+	<-limited
+	c.writeFrame(t, true, opText, []byte("abc"))
+	assert.Equal(t, CloseMessageTooBig, c.readClose(t))
+}
+
+type failingWebSocket struct{}
+
+func (failingWebSocket) HandleWebSocket(c Context, ws *WebSocketConn) error {
+	return errors.New("boom")
+}
+
+func TestWebSocketHandlerErrorClosesWithInternalError(t *testing.T) {
+	errs := make(chan error, 1)
+	r := NewRouter()
+	r.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c Context) error {
+			err := next(c)
+			errs <- err
+			return err
+		}
+	})
+	r.WebSocket("/ws", func(Context) (WebSocketHandler, error) {
+		return failingWebSocket{}, nil
+	})
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	c := dialWebSocket(t, srv, "/ws", nil)
+	assert.Equal(t, CloseInternalServerErr, c.readClose(t))
+	assert.EqualError(t, <-errs, "boom")
+}
+
+type pingWebSocket struct {
+	pongs chan []byte
+}
+
+func (h *pingWebSocket) HandleWebSocket(c Context, ws *WebSocketConn) error {
+	ws.SetPongHandler(func(data []byte) {
+		h.pongs <- data
+	})
+	if err := ws.Ping([]byte("are you there")); err != nil {
+		return err
+	}
+	_, _, err := ws.ReadMessage()
+	return err
+}
+
+func TestWebSocketPingPong(t *testing.T) {
+	h := &pingWebSocket{pongs: make(chan []byte, 1)}
+	srv := newWebSocketServer(t, func(Context) (WebSocketHandler, error) {
+		return h, nil
+	})
+	c := dialWebSocket(t, srv, "/ws/a", nil)
+
+	op, payload := c.readFrame(t)
+	require.Equal(t, byte(opPing), op)
+	c.writeFrame(t, true, opPong, payload)
+
+	select {
+	case data := <-h.pongs:
+		assert.Equal(t, "are you there", string(data))
+	case <-time.After(time.Second):
+		t.Fatal("pong was not handled")
+	}
+	c.writeFrame(t, true, opClose, closePayload(CloseGoingAway, ""))
+	assert.Equal(t, CloseGoingAway, c.readClose(t))
+}
+
+func TestWebSocketPingInterval(t *testing.T) {
+	srv := newWebSocketServer(t, echoProvider, WithWebSocketOptions(WebSocketOptions{
+		PingInterval: 10 * time.Millisecond,
+	}))
+	c := dialWebSocket(t, srv, "/ws/a", nil)
+
+	op, _ := c.readFrame(t)
+	assert.Equal(t, byte(opPing), op)
+}
+
+func TestWebSocketConnWriteAfterClose(t *testing.T) {
+	done := make(chan error, 1)
+	srv := newWebSocketServer(t, func(Context) (WebSocketHandler, error) {
+		return webSocketHandlerFunc(func(c Context, ws *WebSocketConn) error {
+			require.NoError(t, ws.Close(CloseNormalClosure, "done"))
+			done <- ws.WriteMessage(TextMessage, []byte("late"))
+			return nil
+		}), nil
+	})
+	c := dialWebSocket(t, srv, "/ws/a", nil)
+
+	assert.Equal(t, CloseNormalClosure, c.readClose(t))
+	assert.Equal(t, ErrWebSocketClosed, <-done)
+}
+
+func TestWebSocketCloseWaitsForClient(t *testing.T) {
+	closed := make(chan error, 1)
+	srv := newWebSocketServer(t, func(Context) (WebSocketHandler, error) {
+		return webSocketHandlerFunc(func(c Context, ws *WebSocketConn) error {
+			closed <- ws.Close(CloseGoingAway, "")
+			return nil
+		}), nil
+	})
+	c := dialWebSocket(t, srv, "/ws/a", nil)
+
+	op, _ := c.readFrame(t)
+	require.Equal(t, byte(opClose), op)
+	select {
+	case <-closed:
+		t.Fatal("Close returned before the client answered")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	c.writeFrame(t, true, opText, []byte("discarded"))
+	c.writeFrame(t, true, opClose, closePayload(CloseGoingAway, ""))
+	select {
+	case err := <-closed:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("Close did not return after the client answered")
+	}
+	_, err := c.br.ReadByte()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestWebSocketCloseTimesOut(t *testing.T) {
+	closed := make(chan time.Duration, 1)
+	srv := newWebSocketServer(t, func(Context) (WebSocketHandler, error) {
+		return webSocketHandlerFunc(func(c Context, ws *WebSocketConn) error {
+			start := time.Now()
+			ws.Close(CloseNormalClosure, "")
+			closed <- time.Since(start)
+			return nil
+		}), nil
+	}, WithWebSocketOptions(WebSocketOptions{CloseTimeout: 50 * time.Millisecond}))
+	c := dialWebSocket(t, srv, "/ws/a", nil)
+
+	op, _ := c.readFrame(t)
+	require.Equal(t, byte(opClose), op)
+	_, err := c.br.ReadByte()
+	assert.Equal(t, io.EOF, err, "the connection should be closed without an answer")
+	assert.True(t, <-closed >= 50*time.Millisecond)
+}
+
+func TestWebSocketCloseWhileReading(t *testing.T) {
+	read := make(chan error, 1)
+	srv := newWebSocketServer(t, func(Context) (WebSocketHandler, error) {
+		return webSocketHandlerFunc(func(c Context, ws *WebSocketConn) error {
+			go func() {
+				_, _, err := ws.ReadMessage()
+				read <- err
+			}()
+			time.Sleep(20 * time.Millisecond)
+			return ws.Close(CloseNormalClosure, "")
+		}), nil
+	})
+	c := dialWebSocket(t, srv, "/ws/a", nil)
+
+	assert.Equal(t, CloseNormalClosure, c.readClose(t))
+	select {
+	case err := <-read:
+		var cerr *CloseError
+		require.True(t, errors.As(err, &cerr), "%v", err)
+		assert.Equal(t, CloseNoStatusReceived, cerr.Code)
+	case <-time.After(time.Second):
+		t.Fatal("the reader did not receive the close frame of the client")
+	}
+}
+
+type webSocketHandlerFunc func(Context, *WebSocketConn) error
+
+func (f webSocketHandlerFunc) HandleWebSocket(c Context, ws *WebSocketConn) error {
+	return f(c, ws)
+}
+
+func TestSameOrigin(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "http://example.com/ws", nil)
+	assert.True(t, sameOrigin(r))
+
+	r.Header.Set("Origin", "https://EXAMPLE.com")
+	assert.True(t, sameOrigin(r))
+
+	r.Header.Set("Origin", "https://other.com")
+	assert.False(t, sameOrigin(r))
+}
+
+func TestHeaderContainsToken(t *testing.T) {
+	h := http.Header{"Connection": {"keep-alive, Upgrade"}}
+	assert.True(t, headerContainsToken(h, "Connection", "upgrade"))
+	assert.False(t, headerContainsToken(h, "Connection", "close"))
+}